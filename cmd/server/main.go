@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"time"
 
 	prometheuslib "github.com/prometheus/client_golang/prometheus"
@@ -13,10 +14,17 @@ import (
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
 	user "github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/client/user"
 	server "github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server"
+	httpserver "github.com/sagarmaheshwary/microservices-authentication-service/internal/http/server"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/apikey"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/audit"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/device"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/federation"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/jaeger"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/jwt"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/prometheus"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/rbac"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis"
 	"google.golang.org/grpc"
 )
@@ -25,17 +33,35 @@ func main() {
 	logger.Init()
 	cfg := config.NewConfig()
 
+	if err := logger.Configure(&logger.Options{
+		Level:          cfg.Log.Level,
+		Format:         cfg.Log.Format,
+		Output:         cfg.Log.Output,
+		File:           cfg.Log.File,
+		FileMaxSizeMB:  cfg.Log.FileMaxSizeMB,
+		FileMaxBackups: cfg.Log.FileMaxBackups,
+		FileMaxAgeDays: cfg.Log.FileMaxAgeDays,
+		SampleInfo:     uint32(max(cfg.Log.SampleInfo, 0)),
+	}); err != nil {
+		logger.Error("Invalid log config: %v", err)
+		os.Exit(constant.ExitFailure)
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
-	shutdownJaeger := jaeger.Init(ctx, cfg.Jaeger.URL)
+	shutdownJaeger, err := jaeger.Init(ctx, cfg.Jaeger)
+	if err != nil {
+		logger.Error("Invalid tracing config: %v", err)
+		os.Exit(constant.ExitFailure)
+	}
 
-	promServer := prometheus.NewServer(cfg.Prometheus.URL, prometheuslib.NewRegistry())
-	go func() {
-		if err := prometheus.Serve(promServer, promServer.ListenAndServe); err != nil && err != http.ErrServerClosed {
-			stop()
-		}
-	}()
+	registry := prometheuslib.NewRegistry()
+	shutdownMetrics, err := metrics.Init(ctx, cfg.Metrics, registry)
+	if err != nil {
+		logger.Error("Failed to set up metrics: %v", err)
+		os.Exit(constant.ExitFailure)
+	}
 
 	redisClient, err := redis.NewClient(cfg.Redis)
 	if err != nil {
@@ -43,39 +69,140 @@ func main() {
 	}
 	defer redisClient.Close()
 
-	userClient, userConn, err := user.NewClient(ctx, &user.InitClientOptions{Config: cfg.GRPCUserClient})
+	userClient, userConn, err := user.NewClient(ctx, &user.InitClientOptions{
+		Config:          cfg.GRPCUserClient,
+		SkipHealthCheck: cfg.DegradedMode.Enabled,
+	})
 	if err != nil {
 		logger.Error("Failed to connect to user client: %v", err)
 		os.Exit(constant.ExitFailure)
 	}
 	defer userConn.Close()
 
-	jwtManager := jwt.NewJWTManager(cfg.JWT, redisClient)
+	policy, err := rbac.NewPolicy(cfg.RBAC)
+	if err != nil {
+		logger.Error("Failed to load RBAC policy: %v", err)
+		os.Exit(constant.ExitFailure)
+	}
+
+	jwtManager := jwt.NewJWTManager(cfg.JWT, redisClient, policy)
+	deviceManager := device.NewDeviceManager(cfg.Device, redisClient)
+	federationManager := federation.NewFederationManager(cfg.OIDC, redisClient)
+	apiKeyManager := apikey.NewAPIKeyManager(cfg.APIKey, redisClient)
+
+	auditSinks, err := audit.NewSinks(cfg.Audit, redisClient)
+	if err != nil {
+		logger.Error("Invalid audit config: %v", err)
+		os.Exit(constant.ExitFailure)
+	}
+	var auditor audit.Auditor
+	if len(auditSinks) > 0 {
+		if auditor, err = audit.NewAuditor(cfg.Audit, auditSinks...); err != nil {
+			logger.Error("Invalid audit config: %v", err)
+			os.Exit(constant.ExitFailure)
+		}
+	}
+
+	// In degraded mode the service keeps verifying tokens without the user
+	// service, so losing it only degrades readiness.
+	var profileFallback *server.ProfileFallback
+	nonCritical := cfg.Health.NonCriticalDependencies
+	if cfg.DegradedMode.Enabled {
+		profileFallback = server.NewProfileFallback(cfg.DegradedMode)
+		if !slices.Contains(nonCritical, server.HealthServiceUser) {
+			nonCritical = append(slices.Clone(nonCritical), server.HealthServiceUser)
+		}
+	}
+
+	authServer := &server.AuthenticationServer{
+		UserClient:        userClient,
+		JWTManager:        jwtManager,
+		DeviceManager:     deviceManager,
+		FederationManager: federationManager,
+		APIKeyManager:     apiKeyManager,
+		Auditor:           auditor,
+		ProfileFallback:   profileFallback,
+	}
+	healthServer := &server.HealthServer{
+		UserClient:  userClient,
+		RedisClient: redisClient,
+		Timeout:     cfg.Health.CheckTimeout,
+		NonCritical: nonCritical,
+	}
+	go healthServer.Run(ctx, cfg.Health.CheckInterval)
+
+	promServer := prometheus.NewServer(cfg.Prometheus.URL, registry, healthServer.Probes())
+	go func() {
+		if err := prometheus.Serve(promServer, promServer.ListenAndServe); err != nil && err != http.ErrServerClosed {
+			stop()
+		}
+	}()
 
-	grpcServer := server.NewServer(userClient, redisClient, jwtManager)
+	tlsOptions, err := server.TLSOptions(cfg.GRPCServer)
+	if err != nil {
+		logger.Error("Failed to load gRPC server TLS certificates: %v", err)
+		os.Exit(constant.ExitFailure)
+	}
+
+	grpcServer := server.NewServer(authServer, healthServer, tlsOptions...)
 	go func() {
 		if err := server.Serve(cfg.GRPCServer.URL, grpcServer); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
 			stop()
 		}
 	}()
 
+	httpServer := httpserver.NewServer(cfg.HTTPServer.URL, authServer, cfg.ForwardAuth)
+	go func() {
+		if err := httpserver.Serve(httpServer, httpServer.ListenAndServe); err != nil && err != http.ErrServerClosed {
+			stop()
+		}
+	}()
+
 	<-ctx.Done()
 
 	logger.Info("Shutdown signal received")
 
+	// Fail readiness first and keep serving for a while, so load balancers
+	// stop sending requests before the servers close their connections.
+	healthServer.Shutdown()
+	if cfg.Health.ShutdownDelay > 0 {
+		logger.Info("Waiting %s for traffic to drain", cfg.Health.ShutdownDelay)
+		time.Sleep(cfg.Health.ShutdownDelay)
+	}
+
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := shutdownJaeger(shutdownCtx); err != nil {
 		logger.Warn("failed to shutdown jaeger tracer: %v", err)
 	}
 
+	shutdownCtx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownMetrics(shutdownCtx); err != nil {
+		logger.Warn("Failed to flush metrics: %v", err)
+	}
+
 	shutdownCtx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := promServer.Shutdown(shutdownCtx); err != nil {
 		logger.Warn("Prometheus server shutdown error: %v", err)
 	}
 
+	shutdownCtx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("HTTP server shutdown error: %v", err)
+	}
+
 	grpcServer.GracefulStop()
 
+	if auditor != nil {
+		shutdownCtx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := auditor.Close(shutdownCtx); err != nil {
+			logger.Warn("Audit log flush error: %v", err)
+		}
+	}
+
 	logger.Info("Shutdown complete")
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/gofor-little/env"
@@ -16,21 +17,46 @@ type Config struct {
 	GRPCUserClient *GRPCUserClient
 	Redis          *Redis
 	Prometheus     *Prometheus
+	Metrics        *Metrics
 	Jaeger         *Jaeger
+	HTTPServer     *HTTPServer
+	Device         *Device
+	APIKey         *APIKey
+	OIDC           *OIDC
+	RBAC           *RBAC
+	ForwardAuth    *ForwardAuth
+	Log            *Log
+	Audit          *Audit
+	Health         *Health
+	DegradedMode   *DegradedMode
 }
 
 type GRPCServer struct {
-	URL string
+	URL                   string
+	TLSCertFile           string
+	TLSKeyFile            string
+	TLSClientCAFile       string
+	TLSClientCertOptional bool
+	TLSReloadInterval     time.Duration
 }
 
 type GRPCUserClient struct {
-	URL     string
-	Timeout time.Duration
+	URL               string
+	Timeout           time.Duration
+	TLSEnabled        bool
+	TLSCAFile         string
+	TLSCertFile       string
+	TLSKeyFile        string
+	TLSServerName     string
+	TLSMinVersion     string
+	TLSReloadInterval time.Duration
 }
 
 type JWT struct {
-	Secret string
-	Expiry time.Duration
+	Secret            string
+	Expiry            time.Duration
+	ExchangeExpiry    time.Duration
+	ExchangeAudiences []string
 }
 
 type Redis struct {
@@ -44,10 +70,118 @@ type Prometheus struct {
 	URL string
 }
 
+// Metrics are always served to Prometheus, and also pushed over OTLP HTTP
+// when OTLPURL is set.
+type Metrics struct {
+	OTLPURL      string
+	OTLPInterval time.Duration
+}
+
+// Jaeger configures tracing. Spans go to URL over OTLP HTTP unless Exporter
+// is "none", which turns tracing off for local runs.
 type Jaeger struct {
+	URL      string
+	Exporter string
+	Insecure bool
+	CAFile   string
+	Headers  map[string]string
+	// SampleRatio is the share of new traces recorded. With ParentBased,
+	// requests that arrive as part of a trace follow the caller's decision.
+	SampleRatio        float64
+	ParentBased        bool
+	Propagators        []string
+	ServiceVersion     string
+	Environment        string
+	PodName            string
+	ResourceAttributes map[string]string
+}
+
+type HTTPServer struct {
 	URL string
 }
 
+type Device struct {
+	VerificationURI string
+	Expiry          time.Duration
+	Interval        time.Duration
+}
+
+// APIKey caps how long an API key may live. Keys without an expiry are not
+// issued.
+type APIKey struct {
+	MaxExpiry time.Duration
+}
+
+type OIDC struct {
+	StateExpiry time.Duration
+	Providers   map[string]*OIDCProvider
+}
+
+type OIDCProvider struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// TrustEmail lets a verified email from this provider sign in to an
+	// existing account with that address. Only enable it for providers that
+	// own their users' email domains.
+	TrustEmail bool
+}
+
+type RBAC struct {
+	PolicyFile string
+}
+
+type ForwardAuth struct {
+	Cookie    string
+	CacheTTL  time.Duration
+	CacheSize int
+}
+
+type Log struct {
+	Level          string
+	Format         string
+	Output         string
+	File           string
+	FileMaxSizeMB  int
+	FileMaxBackups int
+	FileMaxAgeDays int
+	SampleInfo     int
+}
+
+type Audit struct {
+	Sinks             []string
+	File              string
+	FileMaxSizeMB     int
+	FileMaxBackups    int
+	RedisStream       string
+	RedisStreamMaxLen int64
+	BufferSize        int
+	// TrustedProxies are the CIDRs whose x-forwarded-for is believed.
+	TrustedProxies []string
+}
+
+// Health sets how often dependencies are checked in the background. Probes
+// are answered from the last result.
+type Health struct {
+	CheckInterval time.Duration
+	CheckTimeout  time.Duration
+	// NonCriticalDependencies only degrade readiness when they are down.
+	NonCriticalDependencies []string
+	// ShutdownDelay keeps serving after readiness turns off on shutdown,
+	// so load balancers stop routing here before connections are closed.
+	ShutdownDelay time.Duration
+}
+
+// DegradedMode lets VerifyToken keep answering while the user service is
+// down, from profiles cached for CacheTTL or the profile signed into tokens.
+type DegradedMode struct {
+	Enabled   bool
+	CacheTTL  time.Duration
+	CacheSize int
+}
+
 type LoaderOptions struct {
 	EnvPath     string
 	EnvLoader   func(string) error
@@ -78,15 +212,29 @@ func NewConfigWithOptions(opts LoaderOptions) *Config {
 
 	return &Config{
 		GRPCServer: &GRPCServer{
-			URL: helper.GetEnv("GRPC_SERVER_URL", "0.0.0.0:5001"),
+			URL:                   helper.GetEnv("GRPC_SERVER_URL", "0.0.0.0:5001"),
+			TLSCertFile:           helper.GetEnv("GRPC_SERVER_TLS_CERT_FILE", ""),
+			TLSKeyFile:            helper.GetEnv("GRPC_SERVER_TLS_KEY_FILE", ""),
+			TLSClientCAFile:       helper.GetEnv("GRPC_SERVER_TLS_CLIENT_CA_FILE", ""),
+			TLSClientCertOptional: helper.GetEnvBool("GRPC_SERVER_TLS_CLIENT_CERT_OPTIONAL", false),
+			TLSReloadInterval:     helper.GetEnvDurationSeconds("GRPC_SERVER_TLS_RELOAD_INTERVAL_SECONDS", 30),
 		},
 		JWT: &JWT{
-			Secret: helper.GetEnv("JWT_SECRET", "secret-key"),
-			Expiry: helper.GetEnvDurationSeconds("JWT_EXPIRY_SECONDS", 3600),
+			Secret:            helper.GetEnv("JWT_SECRET", "secret-key"),
+			Expiry:            helper.GetEnvDurationSeconds("JWT_EXPIRY_SECONDS", 3600),
+			ExchangeExpiry:    helper.GetEnvDurationSeconds("JWT_EXCHANGE_EXPIRY_SECONDS", 300),
+			ExchangeAudiences: helper.GetEnvSlice("JWT_EXCHANGE_AUDIENCES", nil),
 		},
 		GRPCUserClient: &GRPCUserClient{
-			URL:     helper.GetEnv("GRPC_USER_SERVICE_URL", "user-service:5000"),
-			Timeout: helper.GetEnvDurationSeconds("GRPC_USER_SERVICE_TIMEOUT_SECONDS", 5),
+			URL:               helper.GetEnv("GRPC_USER_SERVICE_URL", "user-service:5000"),
+			Timeout:           helper.GetEnvDurationSeconds("GRPC_USER_SERVICE_TIMEOUT_SECONDS", 5),
+			TLSEnabled:        helper.GetEnvBool("GRPC_USER_SERVICE_TLS_ENABLED", false),
+			TLSCAFile:         helper.GetEnv("GRPC_USER_SERVICE_TLS_CA_FILE", ""),
+			TLSCertFile:       helper.GetEnv("GRPC_USER_SERVICE_TLS_CERT_FILE", ""),
+			TLSKeyFile:        helper.GetEnv("GRPC_USER_SERVICE_TLS_KEY_FILE", ""),
+			TLSServerName:     helper.GetEnv("GRPC_USER_SERVICE_TLS_SERVER_NAME", ""),
+			TLSMinVersion:     helper.GetEnv("GRPC_USER_SERVICE_TLS_MIN_VERSION", "1.2"),
+			TLSReloadInterval: helper.GetEnvDurationSeconds("GRPC_USER_SERVICE_TLS_RELOAD_INTERVAL_SECONDS", 30),
 		},
 		Redis: &Redis{
 			Host:     helper.GetEnv("REDIS_HOST", "redis"),
@@ -97,10 +245,100 @@ func NewConfigWithOptions(opts LoaderOptions) *Config {
 		Prometheus: &Prometheus{
 			URL: helper.GetEnv("PROMETHEUS_URL", "0.0.0.0:5011"),
 		},
+		Metrics: &Metrics{
+			OTLPURL:      helper.GetEnv("METRICS_OTLP_URL", ""),
+			OTLPInterval: helper.GetEnvDurationSeconds("METRICS_OTLP_INTERVAL_SECONDS", 15),
+		},
 		Jaeger: &Jaeger{
-			URL: helper.GetEnv("JAEGER_URL", "jaeger:4318"),
+			URL:                helper.GetEnv("JAEGER_URL", "jaeger:4318"),
+			Exporter:           helper.GetEnv("TRACING_EXPORTER", "otlp"),
+			Insecure:           helper.GetEnvBool("TRACING_INSECURE", true),
+			CAFile:             helper.GetEnv("TRACING_CA_FILE", ""),
+			Headers:            helper.GetEnvMap("TRACING_HEADERS"),
+			SampleRatio:        helper.GetEnvFloat("TRACING_SAMPLE_RATIO", 1),
+			ParentBased:        helper.GetEnvBool("TRACING_PARENT_BASED", true),
+			Propagators:        helper.GetEnvSlice("TRACING_PROPAGATORS", []string{"tracecontext", "baggage"}),
+			ServiceVersion:     helper.GetEnv("SERVICE_VERSION", ""),
+			Environment:        helper.GetEnv("DEPLOYMENT_ENVIRONMENT", ""),
+			PodName:            helper.GetEnv("POD_NAME", ""),
+			ResourceAttributes: helper.GetEnvMap("TRACING_RESOURCE_ATTRIBUTES"),
+		},
+		HTTPServer: &HTTPServer{
+			URL: helper.GetEnv("HTTP_SERVER_URL", "0.0.0.0:5002"),
+		},
+		Device: &Device{
+			VerificationURI: helper.GetEnv("DEVICE_VERIFICATION_URI", "http://localhost:3000/device"),
+			Expiry:          helper.GetEnvDurationSeconds("DEVICE_CODE_EXPIRY_SECONDS", 600),
+			Interval:        helper.GetEnvDurationSeconds("DEVICE_POLL_INTERVAL_SECONDS", 5),
+		},
+		APIKey: &APIKey{
+			MaxExpiry: helper.GetEnvDurationSeconds("APIKEY_MAX_EXPIRY_SECONDS", 31536000),
+		},
+		OIDC: &OIDC{
+			StateExpiry: helper.GetEnvDurationSeconds("OIDC_STATE_EXPIRY_SECONDS", 600),
+			Providers:   loadOIDCProviders(),
+		},
+		RBAC: &RBAC{
+			PolicyFile: helper.GetEnv("RBAC_POLICY_FILE", ""),
+		},
+		ForwardAuth: &ForwardAuth{
+			Cookie:    helper.GetEnv("FORWARD_AUTH_COOKIE", ""),
+			CacheTTL:  helper.GetEnvDurationSeconds("FORWARD_AUTH_CACHE_TTL_SECONDS", 5),
+			CacheSize: helper.GetEnvInt("FORWARD_AUTH_CACHE_SIZE", 10000),
 		},
+		Log: &Log{
+			Level:          helper.GetEnv("LOG_LEVEL", "info"),
+			Format:         helper.GetEnv("LOG_FORMAT", "console"),
+			Output:         helper.GetEnv("LOG_OUTPUT", "stderr"),
+			File:           helper.GetEnv("LOG_FILE", ""),
+			FileMaxSizeMB:  helper.GetEnvInt("LOG_FILE_MAX_SIZE_MB", 100),
+			FileMaxBackups: helper.GetEnvInt("LOG_FILE_MAX_BACKUPS", 5),
+			FileMaxAgeDays: helper.GetEnvInt("LOG_FILE_MAX_AGE_DAYS", 0),
+			SampleInfo:     helper.GetEnvInt("LOG_SAMPLE_INFO", 0),
+		},
+		Audit: &Audit{
+			Sinks:             helper.GetEnvSlice("AUDIT_SINKS", nil),
+			File:              helper.GetEnv("AUDIT_FILE", "audit.log"),
+			FileMaxSizeMB:     helper.GetEnvInt("AUDIT_FILE_MAX_SIZE_MB", 100),
+			FileMaxBackups:    helper.GetEnvInt("AUDIT_FILE_MAX_BACKUPS", 0),
+			RedisStream:       helper.GetEnv("AUDIT_REDIS_STREAM", "audit-events"),
+			RedisStreamMaxLen: int64(helper.GetEnvInt("AUDIT_REDIS_STREAM_MAX_LEN", 100000)),
+			BufferSize:        helper.GetEnvInt("AUDIT_BUFFER_SIZE", 1024),
+			TrustedProxies:    helper.GetEnvSlice("AUDIT_TRUSTED_PROXIES", nil),
+		},
+		Health: &Health{
+			CheckInterval:           helper.GetEnvDurationSeconds("HEALTH_CHECK_INTERVAL_SECONDS", 10),
+			CheckTimeout:            helper.GetEnvDurationSeconds("HEALTH_CHECK_TIMEOUT_SECONDS", 3),
+			NonCriticalDependencies: helper.GetEnvSlice("HEALTH_NONCRITICAL_DEPENDENCIES", nil),
+			ShutdownDelay:           helper.GetEnvDurationSeconds("HEALTH_SHUTDOWN_DELAY_SECONDS", 0),
+		},
+		DegradedMode: &DegradedMode{
+			Enabled:   helper.GetEnvBool("DEGRADED_MODE_ENABLED", false),
+			CacheTTL:  helper.GetEnvDurationSeconds("DEGRADED_MODE_CACHE_TTL_SECONDS", 3600),
+			CacheSize: helper.GetEnvInt("DEGRADED_MODE_CACHE_SIZE", 10000),
+		},
+	}
+}
+
+// loadOIDCProviders reads one OIDC_<NAME>_* group per entry in OIDC_PROVIDERS,
+// e.g. OIDC_PROVIDERS=google reads OIDC_GOOGLE_ISSUER_URL and friends.
+func loadOIDCProviders() map[string]*OIDCProvider {
+	providers := map[string]*OIDCProvider{}
+	redirectURL := helper.GetEnv("OIDC_REDIRECT_URL", "http://localhost:3000/auth/callback")
+
+	for _, name := range helper.GetEnvSlice("OIDC_PROVIDERS", nil) {
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers[name] = &OIDCProvider{
+			IssuerURL:    helper.GetEnv(prefix+"ISSUER_URL", ""),
+			ClientID:     helper.GetEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: helper.GetEnv(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  helper.GetEnv(prefix+"REDIRECT_URL", redirectURL),
+			Scopes:       helper.GetEnvSlice(prefix+"SCOPES", []string{"openid", "email", "profile"}),
+			TrustEmail:   helper.GetEnvBool(prefix+"TRUST_EMAIL", false),
+		}
 	}
+
+	return providers
 }
 
 func NewConfig() *Config {
@@ -62,3 +62,28 @@ func TestNewConfigWithOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestNewConfigWithOptions_OIDCProviders(t *testing.T) {
+	os.Clearenv()
+	t.Setenv("OIDC_PROVIDERS", "google, okta")
+	t.Setenv("OIDC_REDIRECT_URL", "https://app.example.com/callback")
+	t.Setenv("OIDC_GOOGLE_ISSUER_URL", "https://accounts.google.com")
+	t.Setenv("OIDC_GOOGLE_CLIENT_ID", "google-client")
+	t.Setenv("OIDC_GOOGLE_CLIENT_SECRET", "google-secret")
+	t.Setenv("OIDC_OKTA_ISSUER_URL", "https://example.okta.com")
+	t.Setenv("OIDC_OKTA_SCOPES", "openid,email")
+	t.Setenv("OIDC_OKTA_REDIRECT_URL", "https://app.example.com/okta/callback")
+
+	cfg := config.NewConfigWithOptions(config.LoaderOptions{})
+
+	require.Len(t, cfg.OIDC.Providers, 2)
+	assert.Equal(t, &config.OIDCProvider{
+		IssuerURL:    "https://accounts.google.com",
+		ClientID:     "google-client",
+		ClientSecret: "google-secret",
+		RedirectURL:  "https://app.example.com/callback",
+		Scopes:       []string{"openid", "email", "profile"},
+	}, cfg.OIDC.Providers["google"])
+	assert.Equal(t, []string{"openid", "email"}, cfg.OIDC.Providers["okta"].Scopes)
+	assert.Equal(t, "https://app.example.com/okta/callback", cfg.OIDC.Providers["okta"].RedirectURL)
+}
@@ -8,19 +8,102 @@ const (
 	MessageUnauthorized        = "Unauthorized"
 	MessageForbidden           = "Forbidden"
 	MessageNotFound            = "Resource Not Found"
+	MessageRequestTooLarge     = "Request Entity Too Large"
 	MessageInternalServerError = "Internal Server Error"
+	MessageServiceUnavailable  = "Service Unavailable"
 )
 
 // gRPC metadata headers
 const (
 	HeaderAuthorization = "authorization"
+	HeaderRequestID     = "x-request-id"
 )
 
 const HeaderBearerPrefix = "Bearer "
 
+// Identity headers passed to upstream services by edge authorization
+const (
+	HeaderUserID    = "x-user-id"
+	HeaderUserEmail = "x-user-email"
+)
+
 // Redis key prefix
 const (
 	RedisTokenBlacklist = "token-blacklist"
+	RedisDeviceCode     = "device-code"
+	RedisDeviceUserCode = "device-user-code"
+	RedisOIDCState      = "oidc-state"
+	RedisAPIKey         = "api-key"
+	RedisUserAPIKeys    = "api-keys:user"
+)
+
+// APIKeyPrefix marks bearer tokens that are API keys rather than JWTs.
+const APIKeyPrefix = "ak_"
+
+// OAuth 2.0 grant types
+const (
+	GrantTypeDeviceCode    = "urn:ietf:params:oauth:grant-type:device_code"
+	GrantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+)
+
+// OAuth 2.0 token type identifiers (RFC 8693)
+const (
+	TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+	TokenTypeJWT         = "urn:ietf:params:oauth:token-type:jwt"
+)
+
+// OAuth 2.0 error codes (RFC 6749, RFC 8628)
+const (
+	OAuthErrorInvalidRequest       = "invalid_request"
+	OAuthErrorInvalidGrant         = "invalid_grant"
+	OAuthErrorInvalidClient        = "invalid_client"
+	OAuthErrorUnsupportedGrantType = "unsupported_grant_type"
+	OAuthErrorInvalidScope         = "invalid_scope"
+	OAuthErrorInvalidTarget        = "invalid_target"
+	OAuthErrorAuthorizationPending = "authorization_pending"
+	OAuthErrorSlowDown             = "slow_down"
+	OAuthErrorAccessDenied         = "access_denied"
+	OAuthErrorExpiredToken         = "expired_token"
+	OAuthErrorServerError          = "server_error"
+)
+
+// Authorization decision reasons
+const (
+	AuthzReasonGranted           = "permission granted"
+	AuthzReasonInvalidCredential = "invalid or revoked credential"
+	AuthzReasonNoScopes          = "credential carries no scopes"
+	AuthzReasonMissingPermission = "missing permission"
+)
+
+// Metric label values
+const (
+	MetricTokenAccess   = "access"
+	MetricTokenExchange = "exchange"
+	MetricTokenAPIKey   = "api_key"
+
+	MetricBlacklistHit   = "hit"
+	MetricBlacklistMiss  = "miss"
+	MetricBlacklistError = "error"
+
+	MetricLoginNone               = "none"
+	MetricLoginInvalidCredentials = "invalid_credentials"
+	MetricLoginInvalidRequest     = "invalid_request"
+	MetricLoginUserService        = "user_service_error"
+	MetricLoginTokenError         = "token_error"
+
+	MetricVerifyExpired       = "expired"
+	MetricVerifyBadSignature  = "bad_signature"
+	MetricVerifyRevoked       = "revoked"
+	MetricVerifyMalformed     = "malformed"
+	MetricVerifyWrongAudience = "wrong_audience"
+	MetricVerifyInvalid       = "invalid"
+
+	MetricRedisOK    = "ok"
+	MetricRedisNil   = "nil"
+	MetricRedisError = "error"
+
+	MetricFallbackCache = "cache"
+	MetricFallbackToken = "token"
 )
 
 const ServiceName = "Authentication Service"
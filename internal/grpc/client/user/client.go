@@ -2,17 +2,22 @@ package user
 
 import (
 	"context"
+	"errors"
 
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/certs"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
 	userpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/user"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/otel"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
+var ErrTLSKeyPair = errors.New("user client TLS needs both a certificate and a key file")
+
 type DialFunc func(target string, opts ...grpc.DialOption) (*grpc.ClientConn, error)
 
 type ClientFactory func(c userpb.UserServiceClient, h healthpb.HealthClient, cfg *config.GRPCUserClient) UserService
@@ -44,12 +49,22 @@ func NewClient(ctx context.Context, opt *InitClientOptions) (UserService, *grpc.
 		opt.Factory = defaultFactory
 	}
 	if len(opt.DialOptions) == 0 {
+		creds, err := TransportCredentials(opt.Config)
+		if err != nil {
+			logger.Error("User gRPC client TLS setup failed: %v", err)
+			return nil, nil, err
+		}
+
 		opt.DialOptions = []grpc.DialOption{
-			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithTransportCredentials(creds),
 			grpc.WithStatsHandler(otelgrpc.NewClientHandler(
 				otelgrpc.WithTracerProvider(otel.GetTracerProvider()),
 				otelgrpc.WithPropagators(otel.GetTextMapPropagator()),
 			)),
+			grpc.WithChainUnaryInterceptor(MetricsUnaryInterceptor),
+		}
+		if opt.Config.TLSServerName != "" {
+			opt.DialOptions = append(opt.DialOptions, grpc.WithAuthority(opt.Config.TLSServerName))
 		}
 	}
 
@@ -76,3 +91,28 @@ func NewClient(ctx context.Context, opt *InitClientOptions) (UserService, *grpc.
 	logger.Info("User gRPC client ready on %q", opt.Config.URL)
 	return UserClient, conn, nil
 }
+
+// TransportCredentials returns the credentials for the user-service
+// connection. It is plaintext unless TLS is enabled or a CA bundle or client
+// certificate is configured. Without a CA bundle the server is verified
+// against the system roots.
+func TransportCredentials(cfg *config.GRPCUserClient) (credentials.TransportCredentials, error) {
+	if !cfg.TLSEnabled && cfg.TLSCAFile == "" && cfg.TLSCertFile == "" && cfg.TLSKeyFile == "" {
+		return insecure.NewCredentials(), nil
+	}
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, ErrTLSKeyPair
+	}
+
+	minVersion, err := certs.ParseTLSVersion(cfg.TLSMinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	reloader, err := certs.NewReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSCAFile, cfg.TLSReloadInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(reloader.ClientConfig(cfg.TLSServerName, minVersion)), nil
+}
@@ -3,14 +3,21 @@ package user_test
 import (
 	"context"
 	"errors"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
 	user "github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/client/user"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/certs"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/certs/certstest"
 	userpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/user"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
@@ -99,3 +106,133 @@ func TestInitClient(t *testing.T) {
 		})
 	}
 }
+
+func TestTransportCredentials(t *testing.T) {
+	ca := certstest.NewCA(t, "test-ca")
+	pair := ca.Issue(t, "auth-service", "")
+
+	tests := []struct {
+		name         string
+		config       *config.GRPCUserClient
+		expectSecure bool
+		expectErr    error
+	}{
+		{
+			name:   "plaintext by default",
+			config: &config.GRPCUserClient{},
+		},
+		{
+			name:         "TLS with system roots",
+			config:       &config.GRPCUserClient{TLSEnabled: true},
+			expectSecure: true,
+		},
+		{
+			name:         "CA bundle turns TLS on",
+			config:       &config.GRPCUserClient{TLSCAFile: ca.File},
+			expectSecure: true,
+		},
+		{
+			name:         "client certificate",
+			config:       &config.GRPCUserClient{TLSCAFile: ca.File, TLSCertFile: pair.CertFile, TLSKeyFile: pair.KeyFile, TLSMinVersion: "1.3"},
+			expectSecure: true,
+		},
+		{
+			name:      "certificate without key",
+			config:    &config.GRPCUserClient{TLSCertFile: pair.CertFile},
+			expectErr: user.ErrTLSKeyPair,
+		},
+		{
+			name:      "unsupported minimum version",
+			config:    &config.GRPCUserClient{TLSEnabled: true, TLSMinVersion: "1.0"},
+			expectErr: certs.ErrTLSVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, err := user.TransportCredentials(tt.config)
+
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectSecure, creds.Info().SecurityProtocol == "tls")
+		})
+	}
+}
+
+func TestNewClient_TLS(t *testing.T) {
+	ca := certstest.NewCA(t, "test-ca")
+	serverPair := ca.Issue(t, "user-service", "", "user-service.internal")
+	clientPair := ca.Issue(t, "auth-service", "")
+	otherCA := certstest.NewCA(t, "other-ca")
+
+	reloader, err := certs.NewReloader(serverPair.CertFile, serverPair.KeyFile, ca.File, 0)
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer(grpc.Creds(credentials.NewTLS(reloader.ServerConfig(false))))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	tests := []struct {
+		name      string
+		config    *config.GRPCUserClient
+		expectErr bool
+	}{
+		{
+			name: "mTLS",
+			config: &config.GRPCUserClient{
+				TLSCAFile: ca.File, TLSCertFile: clientPair.CertFile, TLSKeyFile: clientPair.KeyFile,
+			},
+		},
+		{
+			name: "mTLS with server name override",
+			config: &config.GRPCUserClient{
+				TLSCAFile: ca.File, TLSCertFile: clientPair.CertFile, TLSKeyFile: clientPair.KeyFile,
+				TLSServerName: "user-service.internal",
+			},
+		},
+		{
+			name: "server name not in certificate",
+			config: &config.GRPCUserClient{
+				TLSCAFile: ca.File, TLSCertFile: clientPair.CertFile, TLSKeyFile: clientPair.KeyFile,
+				TLSServerName: "billing-service.internal",
+			},
+			expectErr: true,
+		},
+		{
+			name: "server signed by unknown CA",
+			config: &config.GRPCUserClient{
+				TLSCAFile: otherCA.File, TLSCertFile: clientPair.CertFile, TLSKeyFile: clientPair.KeyFile,
+			},
+			expectErr: true,
+		},
+		{
+			name:      "missing client certificate",
+			config:    &config.GRPCUserClient{TLSCAFile: ca.File},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.URL = lis.Addr().String()
+			tt.config.Timeout = 2 * time.Second
+
+			svc, conn, err := user.NewClient(context.Background(), &user.InitClientOptions{Config: tt.config})
+
+			if tt.expectErr {
+				assert.Error(t, err)
+				assert.Nil(t, svc)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, svc)
+			conn.Close()
+		})
+	}
+}
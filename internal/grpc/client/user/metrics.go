@@ -0,0 +1,32 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsUnaryInterceptor records the latency of every user-service call,
+// health checks included, by method and gRPC status.
+func MetricsUnaryInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	start := time.Now()
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+
+	metrics.UserServiceDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+		metrics.MethodKey.String(method),
+		metrics.StatusKey.String(status.Code(err).String()),
+	))
+	return err
+}
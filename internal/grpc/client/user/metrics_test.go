@@ -0,0 +1,42 @@
+package user_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/client/user"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics/metricstest"
+)
+
+func TestMetricsUnaryInterceptor(t *testing.T) {
+	tests := []struct {
+		name       string
+		invokerErr error
+		expected   string
+	}{
+		{"success", nil, "OK"},
+		{"not found", status.Error(codes.NotFound, "user not found"), "NotFound"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := metricstest.New(t)
+
+			invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				return tt.invokerErr
+			}
+
+			err := user.MetricsUnaryInterceptor(context.Background(), "/user.UserService/FindById", nil, nil, nil, invoker)
+
+			assert.Equal(t, tt.invokerErr, err)
+			assert.Equal(t, 1.0, reader.Value(t, "user_service_request_duration_seconds",
+				metrics.MethodKey.String("/user.UserService/FindById"), metrics.StatusKey.String(tt.expected)))
+		})
+	}
+}
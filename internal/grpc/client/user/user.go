@@ -14,6 +14,9 @@ type UserService interface {
 	FindById(ctx context.Context, in *userpb.FindByIdRequest) (*userpb.FindByIdResponse, error)
 	FindByCredential(ctx context.Context, in *userpb.FindByCredentialRequest) (*userpb.FindByCredentialResponse, error)
 	Store(ctx context.Context, in *userpb.StoreRequest) (*userpb.StoreResponse, error)
+	FindByEmail(ctx context.Context, in *userpb.FindByEmailRequest) (*userpb.FindByEmailResponse, error)
+	FindByFederatedIdentity(ctx context.Context, in *userpb.FindByFederatedIdentityRequest) (*userpb.FindByFederatedIdentityResponse, error)
+	LinkFederatedIdentity(ctx context.Context, in *userpb.LinkFederatedIdentityRequest) (*userpb.LinkFederatedIdentityResponse, error)
 	Health(ctx context.Context) error
 }
 
@@ -33,11 +36,11 @@ func (u *UserClient) FindById(ctx context.Context, in *userpb.FindByIdRequest) (
 
 	response, err := u.client.FindById(ctx, in)
 	if err != nil {
-		logger.Error("gRPC userClient.FindById request failed: %v", err)
+		logger.ErrorCtx(ctx, "gRPC userClient.FindById request failed: %v", err)
 		return nil, err
 	}
 
-	logger.Info("gRPC userClient.FindById response: %v", response)
+	logger.InfoCtx(ctx, "gRPC userClient.FindById response: %v", response)
 	return response, nil
 }
 
@@ -47,11 +50,11 @@ func (u *UserClient) FindByCredential(ctx context.Context, in *userpb.FindByCred
 
 	response, err := u.client.FindByCredential(ctx, in)
 	if err != nil {
-		logger.Error("gRPC userClient.FindByCredential request failed: %v", err)
+		logger.ErrorCtx(ctx, "gRPC userClient.FindByCredential request failed: %v", err)
 		return nil, err
 	}
 
-	logger.Info("gRPC userClient.FindByCredential response: %v", response)
+	logger.InfoCtx(ctx, "gRPC userClient.FindByCredential response: %v", response)
 	return response, nil
 }
 
@@ -61,11 +64,53 @@ func (u *UserClient) Store(ctx context.Context, in *userpb.StoreRequest) (*userp
 
 	response, err := u.client.Store(ctx, in)
 	if err != nil {
-		logger.Error("gRPC userClient.Store request failed: %v", err)
+		logger.ErrorCtx(ctx, "gRPC userClient.Store request failed: %v", err)
 		return nil, err
 	}
 
-	logger.Info("gRPC userClient.Store response: %v", response)
+	logger.InfoCtx(ctx, "gRPC userClient.Store response: %v", response)
+	return response, nil
+}
+
+func (u *UserClient) FindByEmail(ctx context.Context, in *userpb.FindByEmailRequest) (*userpb.FindByEmailResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.config.Timeout)
+	defer cancel()
+
+	response, err := u.client.FindByEmail(ctx, in)
+	if err != nil {
+		logger.ErrorCtx(ctx, "gRPC userClient.FindByEmail request failed: %v", err)
+		return nil, err
+	}
+
+	logger.InfoCtx(ctx, "gRPC userClient.FindByEmail response: %v", response)
+	return response, nil
+}
+
+func (u *UserClient) FindByFederatedIdentity(ctx context.Context, in *userpb.FindByFederatedIdentityRequest) (*userpb.FindByFederatedIdentityResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.config.Timeout)
+	defer cancel()
+
+	response, err := u.client.FindByFederatedIdentity(ctx, in)
+	if err != nil {
+		logger.ErrorCtx(ctx, "gRPC userClient.FindByFederatedIdentity request failed: %v", err)
+		return nil, err
+	}
+
+	logger.InfoCtx(ctx, "gRPC userClient.FindByFederatedIdentity response: %v", response)
+	return response, nil
+}
+
+func (u *UserClient) LinkFederatedIdentity(ctx context.Context, in *userpb.LinkFederatedIdentityRequest) (*userpb.LinkFederatedIdentityResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.config.Timeout)
+	defer cancel()
+
+	response, err := u.client.LinkFederatedIdentity(ctx, in)
+	if err != nil {
+		logger.ErrorCtx(ctx, "gRPC userClient.LinkFederatedIdentity request failed: %v", err)
+		return nil, err
+	}
+
+	logger.InfoCtx(ctx, "gRPC userClient.LinkFederatedIdentity response: %v", response)
 	return response, nil
 }
 
@@ -75,12 +120,12 @@ func (u *UserClient) Health(ctx context.Context) error {
 
 	res, err := u.health.Check(ctx, &healthpb.HealthCheckRequest{})
 	if err != nil {
-		logger.Error("User gRPC health check failed! %v", err)
+		logger.ErrorCtx(ctx, "User gRPC health check failed! %v", err)
 		return err
 	}
 
 	if res.Status == healthpb.HealthCheckResponse_NOT_SERVING {
-		logger.Error("User gRPC health check failed")
+		logger.ErrorCtx(ctx, "User gRPC health check failed")
 		return errors.New("user grpc health check failed")
 	}
 
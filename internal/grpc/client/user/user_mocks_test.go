@@ -49,6 +49,36 @@ func (m *MockUserServiceClient) Store(ctx context.Context, in *userpb.StoreReque
 	return args.Get(0).(*userpb.StoreResponse), nil
 }
 
+func (m *MockUserServiceClient) FindByEmail(ctx context.Context, in *userpb.FindByEmailRequest, opts ...grpc.CallOption) (*userpb.FindByEmailResponse, error) {
+	args := m.Called(ctx, in)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(*userpb.FindByEmailResponse), nil
+}
+
+func (m *MockUserServiceClient) FindByFederatedIdentity(ctx context.Context, in *userpb.FindByFederatedIdentityRequest, opts ...grpc.CallOption) (*userpb.FindByFederatedIdentityResponse, error) {
+	args := m.Called(ctx, in)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(*userpb.FindByFederatedIdentityResponse), nil
+}
+
+func (m *MockUserServiceClient) LinkFederatedIdentity(ctx context.Context, in *userpb.LinkFederatedIdentityRequest, opts ...grpc.CallOption) (*userpb.LinkFederatedIdentityResponse, error) {
+	args := m.Called(ctx, in)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(*userpb.LinkFederatedIdentityResponse), nil
+}
+
 func (m *MockUserServiceClient) Health(ctx context.Context, opts ...grpc.CallOption) error {
 	args := m.Called(ctx)
 
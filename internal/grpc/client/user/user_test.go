@@ -253,6 +253,147 @@ func TestUserClient_Store(t *testing.T) {
 	}
 }
 
+func TestUserClient_FindByEmail(t *testing.T) {
+	req := &userpb.FindByEmailRequest{Email: dummyUser.Email}
+	res := &userpb.FindByEmailResponse{
+		Message: constant.MessageOK,
+		Data:    &userpb.FindByEmailResponseData{User: dummyUser},
+	}
+
+	cfg := &config.GRPCUserClient{Timeout: 2 * time.Second}
+
+	tests := []struct {
+		name       string
+		mockReturn *userpb.FindByEmailResponse
+		mockErr    error
+		expectErr  bool
+		expectGRPC codes.Code
+	}{
+		{
+			name:       "success",
+			mockReturn: res,
+			mockErr:    nil,
+			expectErr:  false,
+		},
+		{
+			name:       "not found",
+			mockReturn: nil,
+			mockErr:    status.Error(codes.NotFound, "user not found"),
+			expectErr:  true,
+			expectGRPC: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockUserServiceClient)
+			mockHealth := new(MockHealthClient)
+
+			mockClient.On("FindByEmail", mock.Anything, req).
+				Return(tt.mockReturn, tt.mockErr).
+				Once()
+
+			c := user.NewUserClient(mockClient, mockHealth, cfg)
+
+			got, err := c.FindByEmail(context.Background(), req)
+
+			if tt.expectErr {
+				require.Error(t, err)
+				assert.Nil(t, got)
+				assert.Equal(t, tt.expectGRPC, status.Code(err))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.mockReturn, got)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserClient_FindByFederatedIdentity(t *testing.T) {
+	req := &userpb.FindByFederatedIdentityRequest{Provider: "google", Subject: "upstream-42"}
+	res := &userpb.FindByFederatedIdentityResponse{
+		Message: constant.MessageOK,
+		Data:    &userpb.FindByFederatedIdentityResponseData{User: dummyUser},
+	}
+
+	cfg := &config.GRPCUserClient{Timeout: 2 * time.Second}
+
+	tests := []struct {
+		name       string
+		mockReturn *userpb.FindByFederatedIdentityResponse
+		mockErr    error
+		expectGRPC codes.Code
+	}{
+		{
+			name:       "success",
+			mockReturn: res,
+		},
+		{
+			name:       "not linked",
+			mockErr:    status.Error(codes.NotFound, "identity not linked"),
+			expectGRPC: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockUserServiceClient)
+			mockClient.On("FindByFederatedIdentity", mock.Anything, req).
+				Return(tt.mockReturn, tt.mockErr).
+				Once()
+
+			c := user.NewUserClient(mockClient, new(MockHealthClient), cfg)
+
+			got, err := c.FindByFederatedIdentity(context.Background(), req)
+
+			assert.Equal(t, tt.expectGRPC, status.Code(err))
+			assert.Equal(t, tt.mockReturn, got)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserClient_LinkFederatedIdentity(t *testing.T) {
+	req := &userpb.LinkFederatedIdentityRequest{UserId: dummyUser.Id, Provider: "google", Subject: "upstream-42"}
+	cfg := &config.GRPCUserClient{Timeout: 2 * time.Second}
+
+	tests := []struct {
+		name       string
+		mockReturn *userpb.LinkFederatedIdentityResponse
+		mockErr    error
+		expectGRPC codes.Code
+	}{
+		{
+			name:       "success",
+			mockReturn: &userpb.LinkFederatedIdentityResponse{Message: constant.MessageOK},
+		},
+		{
+			name:       "user service unavailable",
+			mockErr:    status.Error(codes.Unavailable, "down"),
+			expectGRPC: codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := new(MockUserServiceClient)
+			mockClient.On("LinkFederatedIdentity", mock.Anything, req).
+				Return(tt.mockReturn, tt.mockErr).
+				Once()
+
+			c := user.NewUserClient(mockClient, new(MockHealthClient), cfg)
+
+			got, err := c.LinkFederatedIdentity(context.Background(), req)
+
+			assert.Equal(t, tt.expectGRPC, status.Code(err))
+			assert.Equal(t, tt.mockReturn, got)
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
 func TestUploadClient_Health(t *testing.T) {
 	cfg := &config.GRPCUserClient{Timeout: 2 * time.Second}
 
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/apikey"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/audit"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (a *AuthenticationServer) CreateAPIKey(ctx context.Context, data *authpb.CreateAPIKeyRequest) (response *authpb.CreateAPIKeyResponse, err error) {
+	event := audit.NewEvent(ctx, audit.EventCreateAPIKey)
+	defer func() { a.recordAudit(ctx, event, err) }()
+
+	claims, err := parseAndValidateJwtTokenFromMetadata(ctx, a.JWTManager)
+	if err != nil {
+		return nil, err
+	}
+	event.UserID = claimsUserID(claims)
+
+	// A long-lived key must come from the user's own session. Tokens issued by
+	// token exchange act for someone else and are bound to another audience.
+	if _, delegated := claims["act"]; delegated {
+		return nil, status.Error(codes.PermissionDenied, constant.MessageForbidden)
+	}
+	if _, bound := claims["aud"]; bound {
+		return nil, status.Error(codes.PermissionDenied, constant.MessageForbidden)
+	}
+
+	if data.Name == "" || data.ExpiresInSeconds <= 0 {
+		return nil, status.Error(codes.InvalidArgument, constant.MessageBadRequest)
+	}
+
+	// A key can't be granted more than the token used to create it, and
+	// inherits the token's scopes when none are requested.
+	scope, ok := downscope(claims, strings.Join(data.Scopes, " "))
+	if !ok {
+		return nil, status.Error(codes.PermissionDenied, constant.MessageForbidden)
+	}
+
+	key, apiKey, err := a.APIKeyManager.Create(
+		ctx,
+		uint(claims["id"].(float64)),
+		claims["username"].(string),
+		data.Name,
+		strings.Fields(scope),
+		time.Duration(data.ExpiresInSeconds)*time.Second,
+	)
+	if errors.Is(err, apikey.ErrInvalidExpiry) {
+		return nil, status.Error(codes.InvalidArgument, constant.MessageBadRequest)
+	}
+	if err != nil {
+		logger.ErrorCtx(ctx, "API key creation failed: %v", err)
+		return nil, status.Error(codes.Internal, constant.MessageInternalServerError)
+	}
+
+	response = &authpb.CreateAPIKeyResponse{
+		Message: constant.MessageCreated,
+		Data: &authpb.CreateAPIKeyResponseData{
+			Key:    key,
+			ApiKey: toAPIKeyPb(apiKey),
+		},
+	}
+	return response, nil
+}
+
+func (a *AuthenticationServer) ListAPIKeys(ctx context.Context, data *authpb.ListAPIKeysRequest) (response *authpb.ListAPIKeysResponse, err error) {
+	event := audit.NewEvent(ctx, audit.EventListAPIKeys)
+	defer func() { a.recordAudit(ctx, event, err) }()
+
+	claims, err := parseAndValidateJwtTokenFromMetadata(ctx, a.JWTManager)
+	if err != nil {
+		return nil, err
+	}
+	event.UserID = claimsUserID(claims)
+
+	keys, err := a.APIKeyManager.List(ctx, uint(claims["id"].(float64)))
+	if err != nil {
+		logger.ErrorCtx(ctx, "API key listing failed: %v", err)
+		return nil, status.Error(codes.Internal, constant.MessageInternalServerError)
+	}
+
+	apiKeys := make([]*authpb.APIKey, 0, len(keys))
+	for _, k := range keys {
+		apiKeys = append(apiKeys, toAPIKeyPb(k))
+	}
+
+	response = &authpb.ListAPIKeysResponse{
+		Message: constant.MessageOK,
+		Data:    &authpb.ListAPIKeysResponseData{ApiKeys: apiKeys},
+	}
+	return response, nil
+}
+
+func (a *AuthenticationServer) RevokeAPIKey(ctx context.Context, data *authpb.RevokeAPIKeyRequest) (response *authpb.RevokeAPIKeyResponse, err error) {
+	event := audit.NewEvent(ctx, audit.EventRevokeAPIKey)
+	defer func() { a.recordAudit(ctx, event, err) }()
+
+	claims, err := parseAndValidateJwtTokenFromMetadata(ctx, a.JWTManager)
+	if err != nil {
+		return nil, err
+	}
+	event.UserID = claimsUserID(claims)
+
+	err = a.APIKeyManager.Revoke(ctx, uint(claims["id"].(float64)), data.Id)
+	if errors.Is(err, apikey.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, constant.MessageNotFound)
+	}
+	if err != nil {
+		logger.ErrorCtx(ctx, "API key revocation failed: %v", err)
+		return nil, status.Error(codes.Internal, constant.MessageInternalServerError)
+	}
+
+	response = &authpb.RevokeAPIKeyResponse{
+		Message: constant.MessageOK,
+		Data:    &authpb.RevokeAPIKeyResponseData{},
+	}
+	return response, nil
+}
+
+func toAPIKeyPb(k *apikey.APIKey) *authpb.APIKey {
+	apiKey := &authpb.APIKey{
+		Id:        k.ID,
+		Name:      k.Name,
+		Scopes:    k.Scopes,
+		CreatedAt: k.CreatedAt,
+	}
+	if k.ExpiresAt != 0 {
+		apiKey.ExpiresAt = &k.ExpiresAt
+	}
+	if k.LastUsedAt != 0 {
+		apiKey.LastUsedAt = &k.LastUsedAt
+	}
+	return apiKey
+}
@@ -0,0 +1,259 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	libjwt "github.com/golang-jwt/jwt/v5"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/apikey"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	userpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var dummyAPIKey = &apikey.APIKey{
+	ID:        "abc123",
+	UserID:    uint(dummyUser.Id),
+	Username:  dummyUser.Name,
+	Name:      "ci",
+	Scopes:    []string{"videos:read"},
+	CreatedAt: 100,
+}
+
+func withBearer(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func validCaller(j *MockJWTManager) {
+	j.On("ParseToken", "validtoken").Return(libjwt.MapClaims{"id": float64(dummyUser.Id), "username": dummyUser.Name, "jti": "1"}, nil)
+	j.On("IsBlacklisted", mock.Anything, "1").Return(false)
+}
+
+func TestAuthenticationServer_CreateAPIKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		setupMocks func(j *MockJWTManager, k *MockAPIKeyManager)
+		inputCtx   context.Context
+		input      *authpb.CreateAPIKeyRequest
+		expectCode codes.Code
+	}{
+		{
+			name: "success",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				j.On("ParseToken", "validtoken").Return(libjwt.MapClaims{"id": float64(dummyUser.Id), "username": dummyUser.Name, "jti": "1", "scope": "videos:*"}, nil)
+				j.On("IsBlacklisted", mock.Anything, "1").Return(false)
+				k.On("Create", mock.Anything, uint(dummyUser.Id), dummyUser.Name, "ci", []string{"videos:read"}, 24*time.Hour).
+					Return("ak_abc123_secret", dummyAPIKey, nil)
+			},
+			inputCtx:   withBearer("validtoken"),
+			input:      &authpb.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"videos:read"}, ExpiresInSeconds: 86400},
+			expectCode: codes.OK,
+		},
+		{
+			name: "scope beyond the caller's token",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				j.On("ParseToken", "validtoken").Return(libjwt.MapClaims{"id": float64(dummyUser.Id), "username": dummyUser.Name, "jti": "1", "scope": "videos:read"}, nil)
+				j.On("IsBlacklisted", mock.Anything, "1").Return(false)
+			},
+			inputCtx:   withBearer("validtoken"),
+			input:      &authpb.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"videos:delete"}, ExpiresInSeconds: 86400},
+			expectCode: codes.PermissionDenied,
+		},
+		{
+			name: "scopes requested with a token without any",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				validCaller(j)
+			},
+			inputCtx:   withBearer("validtoken"),
+			input:      &authpb.CreateAPIKeyRequest{Name: "ci", Scopes: []string{"videos:read"}, ExpiresInSeconds: 86400},
+			expectCode: codes.PermissionDenied,
+		},
+		{
+			name: "delegated token",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				j.On("ParseToken", "validtoken").Return(libjwt.MapClaims{"id": float64(dummyUser.Id), "username": dummyUser.Name, "jti": "1", "act": map[string]any{"sub": "2"}}, nil)
+				j.On("IsBlacklisted", mock.Anything, "1").Return(false)
+			},
+			inputCtx:   withBearer("validtoken"),
+			input:      &authpb.CreateAPIKeyRequest{Name: "ci", ExpiresInSeconds: 86400},
+			expectCode: codes.PermissionDenied,
+		},
+		{
+			name: "exchanged token",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				j.On("ParseToken", "validtoken").Return(libjwt.MapClaims{"id": float64(dummyUser.Id), "username": dummyUser.Name, "jti": "1", "aud": "video-service"}, nil)
+			},
+			inputCtx:   withBearer("validtoken"),
+			input:      &authpb.CreateAPIKeyRequest{Name: "ci", ExpiresInSeconds: 86400},
+			expectCode: codes.Unauthenticated,
+		},
+		{
+			name: "missing expiry",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				validCaller(j)
+			},
+			inputCtx:   withBearer("validtoken"),
+			input:      &authpb.CreateAPIKeyRequest{Name: "ci"},
+			expectCode: codes.InvalidArgument,
+		},
+		{
+			name: "expiry beyond the maximum",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				validCaller(j)
+				k.On("Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return("", nil, apikey.ErrInvalidExpiry)
+			},
+			inputCtx:   withBearer("validtoken"),
+			input:      &authpb.CreateAPIKeyRequest{Name: "ci", ExpiresInSeconds: 10 * 365 * 86400},
+			expectCode: codes.InvalidArgument,
+		},
+		{
+			name: "missing name",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				validCaller(j)
+			},
+			inputCtx:   withBearer("validtoken"),
+			input:      &authpb.CreateAPIKeyRequest{},
+			expectCode: codes.InvalidArgument,
+		},
+		{
+			name:       "unauthenticated",
+			inputCtx:   context.Background(),
+			input:      &authpb.CreateAPIKeyRequest{Name: "ci"},
+			expectCode: codes.Unauthenticated,
+		},
+		{
+			name: "storage failure",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				validCaller(j)
+				k.On("Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+					Return("", nil, errors.New("redis down"))
+			},
+			inputCtx:   withBearer("validtoken"),
+			input:      &authpb.CreateAPIKeyRequest{Name: "ci", ExpiresInSeconds: 86400},
+			expectCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := new(MockJWTManager)
+			k := new(MockAPIKeyManager)
+			if tt.setupMocks != nil {
+				tt.setupMocks(j, k)
+			}
+
+			s := &server.AuthenticationServer{JWTManager: j, APIKeyManager: k}
+			resp, err := s.CreateAPIKey(tt.inputCtx, tt.input)
+
+			assert.Equal(t, tt.expectCode, status.Code(err))
+			if tt.expectCode == codes.OK {
+				assert.Equal(t, constant.MessageCreated, resp.Message)
+				assert.Equal(t, "ak_abc123_secret", resp.Data.Key)
+				assert.Equal(t, "abc123", resp.Data.ApiKey.Id)
+				assert.Nil(t, resp.Data.ApiKey.LastUsedAt)
+			}
+
+			j.AssertExpectations(t)
+			k.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthenticationServer_ListAPIKeys(t *testing.T) {
+	j := new(MockJWTManager)
+	k := new(MockAPIKeyManager)
+	validCaller(j)
+	k.On("List", mock.Anything, uint(dummyUser.Id)).Return([]*apikey.APIKey{dummyAPIKey}, nil)
+
+	s := &server.AuthenticationServer{JWTManager: j, APIKeyManager: k}
+	resp, err := s.ListAPIKeys(withBearer("validtoken"), &authpb.ListAPIKeysRequest{})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.Data.ApiKeys, 1)
+	assert.Equal(t, "ci", resp.Data.ApiKeys[0].Name)
+	j.AssertExpectations(t)
+	k.AssertExpectations(t)
+}
+
+func TestAuthenticationServer_RevokeAPIKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		revokeErr  error
+		expectCode codes.Code
+	}{
+		{name: "success", expectCode: codes.OK},
+		{name: "not found", revokeErr: apikey.ErrNotFound, expectCode: codes.NotFound},
+		{name: "storage failure", revokeErr: errors.New("redis down"), expectCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := new(MockJWTManager)
+			k := new(MockAPIKeyManager)
+			validCaller(j)
+			k.On("Revoke", mock.Anything, uint(dummyUser.Id), "abc123").Return(tt.revokeErr)
+
+			s := &server.AuthenticationServer{JWTManager: j, APIKeyManager: k}
+			_, err := s.RevokeAPIKey(withBearer("validtoken"), &authpb.RevokeAPIKeyRequest{Id: "abc123"})
+
+			assert.Equal(t, tt.expectCode, status.Code(err))
+			j.AssertExpectations(t)
+			k.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthenticationServer_VerifyToken_APIKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		setupMocks func(u *MockUserClient, k *MockAPIKeyManager)
+		expectCode codes.Code
+	}{
+		{
+			name: "resolves owning user",
+			setupMocks: func(u *MockUserClient, k *MockAPIKeyManager) {
+				k.On("Verify", mock.Anything, "ak_abc123_secret").Return(dummyAPIKey, nil)
+				u.On("FindById", mock.Anything, &userpb.FindByIdRequest{Id: dummyUser.Id}).
+					Return(&userpb.FindByIdResponse{Data: &userpb.FindByIdResponseData{User: dummyUser}}, nil)
+			},
+			expectCode: codes.OK,
+		},
+		{
+			name: "revoked key",
+			setupMocks: func(u *MockUserClient, k *MockAPIKeyManager) {
+				k.On("Verify", mock.Anything, "ak_abc123_secret").Return(nil, apikey.ErrInvalidKey)
+			},
+			expectCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := new(MockUserClient)
+			j := new(MockJWTManager)
+			k := new(MockAPIKeyManager)
+			tt.setupMocks(u, k)
+
+			s := &server.AuthenticationServer{UserClient: u, JWTManager: j, APIKeyManager: k}
+			resp, err := s.VerifyToken(withBearer("ak_abc123_secret"), &authpb.VerifyTokenRequest{})
+
+			assert.Equal(t, tt.expectCode, status.Code(err))
+			if tt.expectCode == codes.OK {
+				assert.Equal(t, dummyUser.Email, resp.Data.User.Email)
+			}
+
+			u.AssertExpectations(t)
+			j.AssertExpectations(t)
+			k.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	libjwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/audit"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	userpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/user"
+)
+
+// recordedEvent captures the single event passed to the auditor.
+func recordedEvent(a *MockAuditor) func() *audit.Event {
+	var event *audit.Event
+	a.On("Record", mock.Anything).Run(func(args mock.Arguments) {
+		event = args.Get(0).(*audit.Event)
+	}).Once()
+	return func() *audit.Event { return event }
+}
+
+func TestAuthenticationServer_AuditsLogin(t *testing.T) {
+	ctx := metadata.NewIncomingContext(
+		peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 50123}}),
+		metadata.Pairs("user-agent", "grpc-go/1.70"),
+	)
+	loginResp := &userpb.FindByCredentialResponse{Data: &userpb.FindByCredentialResponseData{User: dummyUser}}
+
+	tests := []struct {
+		name            string
+		setupMocks      func(u *MockUserClient, j *MockJWTManager)
+		expectedOutcome string
+		expectedReason  string
+		expectedUserID  int
+	}{
+		{
+			name: "success",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
+				u.On("FindByCredential", mock.Anything, mock.Anything).Return(loginResp, nil)
+				j.On("NewToken", uint(dummyUser.Id), dummyUser.Name).Return("token123", nil)
+			},
+			expectedOutcome: audit.OutcomeSuccess,
+			expectedUserID:  int(dummyUser.Id),
+		},
+		{
+			name: "wrong password",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
+				u.On("FindByCredential", mock.Anything, mock.Anything).Return(nil, status.Error(codes.Unauthenticated, "invalid credentials"))
+			},
+			expectedOutcome: audit.OutcomeFailure,
+			expectedReason:  codes.Unauthenticated.String(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := new(MockUserClient)
+			j := new(MockJWTManager)
+			a := new(MockAuditor)
+			tt.setupMocks(u, j)
+			event := recordedEvent(a)
+
+			s := &server.AuthenticationServer{UserClient: u, JWTManager: j, Auditor: a}
+			_, _ = s.Login(ctx, &authpb.LoginRequest{Email: "Name@Gmail.com", Password: "pass"})
+
+			a.AssertExpectations(t)
+			require.NotNil(t, event())
+			assert.Equal(t, audit.EventLogin, event().Type)
+			assert.Equal(t, tt.expectedOutcome, event().Outcome)
+			assert.Equal(t, tt.expectedReason, event().Reason)
+			assert.Equal(t, tt.expectedUserID, event().UserID)
+			assert.Equal(t, audit.HashEmail(dummyUser.Email), event().EmailHash)
+			assert.Equal(t, "10.0.0.7", event().PeerIP)
+			assert.Equal(t, "grpc-go/1.70", event().UserAgent)
+			assert.False(t, event().Timestamp.IsZero())
+		})
+	}
+}
+
+func TestAuthenticationServer_AuditsLogout(t *testing.T) {
+	j := new(MockJWTManager)
+	a := new(MockAuditor)
+	j.On("ParseToken", "validtoken").Return(libjwt.MapClaims{"id": float64(dummyUser.Id), "jti": "1", "exp": float64(9999999999)}, nil)
+	j.On("IsBlacklisted", mock.Anything, "1").Return(false)
+	j.On("AddToBlacklist", mock.Anything, "1", int64(9999999999)).Return(errors.New("redis down"))
+	event := recordedEvent(a)
+
+	s := &server.AuthenticationServer{JWTManager: j, Auditor: a}
+	_, err := s.Logout(withBearer("validtoken"), &authpb.LogoutRequest{})
+
+	assert.Error(t, err)
+	assert.Equal(t, audit.EventLogout, event().Type)
+	assert.Equal(t, audit.OutcomeFailure, event().Outcome)
+	assert.Equal(t, int(dummyUser.Id), event().UserID)
+}
+
+func TestAuthenticationServer_AuditsAuthorizeDenial(t *testing.T) {
+	j := new(MockJWTManager)
+	a := new(MockAuditor)
+	j.On("ParseToken", "validtoken").Return(libjwt.MapClaims{"id": float64(dummyUser.Id), "jti": "1", "scope": "videos:read"}, nil)
+	j.On("IsBlacklisted", mock.Anything, "1").Return(false)
+	event := recordedEvent(a)
+
+	s := &server.AuthenticationServer{JWTManager: j, Auditor: a}
+	resp, err := s.Authorize(context.Background(), &authpb.AuthorizeRequest{Token: "validtoken", Permission: "videos:delete"})
+
+	require.NoError(t, err)
+	assert.False(t, resp.Data.Allowed)
+	assert.Equal(t, audit.EventAuthorize, event().Type)
+	assert.Equal(t, audit.OutcomeDenied, event().Outcome)
+	assert.Equal(t, constant.AuthzReasonMissingPermission, event().Reason)
+	assert.Equal(t, int(dummyUser.Id), event().UserID)
+}
@@ -2,17 +2,24 @@ package server
 
 import (
 	"context"
-	"strings"
+	"slices"
 
 	libjwt "github.com/golang-jwt/jwt/v5"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/client/user"
-	"github.com/sagarmaheshwary/microservices-authentication-service/internal/helper"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/apikey"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/audit"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/device"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/federation"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/jwt"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
 	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
 	userpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/user"
+	"github.com/sagarmaheshwary/microservices-authentication-service/pkg/authguard"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -22,12 +29,41 @@ const (
 
 type AuthenticationServer struct {
 	authpb.AuthenticationServiceServer
-	UserClient user.UserService
-	JWTManager jwt.JWTManager
+	UserClient        user.UserService
+	JWTManager        jwt.JWTManager
+	DeviceManager     device.DeviceManager
+	FederationManager federation.FederationManager
+	APIKeyManager     apikey.APIKeyManager
+	// Auditor may be nil, which turns auditing off.
+	Auditor audit.Auditor
+	// ProfileFallback may be nil, which turns degraded mode off.
+	ProfileFallback *ProfileFallback
 }
 
-func (a *AuthenticationServer) Register(ctx context.Context, data *authpb.RegisterRequest) (*authpb.RegisterResponse, error) {
-	clientResponse, err := a.UserClient.Store(ctx, &userpb.StoreRequest{
+// recordAudit completes event with the outcome of the RPC, queues it and tags
+// the RPC span with the same outcome.
+func (a *AuthenticationServer) recordAudit(ctx context.Context, event *audit.Event, err error) {
+	event.Finish(err)
+	if a.Auditor != nil {
+		a.Auditor.Record(event)
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(OutcomeKey.String(event.Outcome))
+	if event.Reason != "" {
+		span.SetAttributes(FailureReasonKey.String(event.Reason))
+	}
+	if event.UserID != 0 {
+		span.SetAttributes(UserIDKey.Int(event.UserID))
+	}
+}
+
+func (a *AuthenticationServer) Register(ctx context.Context, data *authpb.RegisterRequest) (response *authpb.RegisterResponse, err error) {
+	event := audit.NewEvent(ctx, audit.EventRegister)
+	event.EmailHash = audit.HashEmail(data.Email)
+	defer func() { a.recordAudit(ctx, event, err) }()
+
+	clientResponse, err := a.users().Store(ctx, &userpb.StoreRequest{
 		Name:     data.Name,
 		Email:    data.Email,
 		Password: data.Password,
@@ -37,12 +73,14 @@ func (a *AuthenticationServer) Register(ctx context.Context, data *authpb.Regist
 	}
 
 	user := clientResponse.Data.User
-	token, err := a.JWTManager.NewToken(uint(user.Id), user.Email)
+	event.UserID = int(user.Id)
+	logger.AddField(ctx, logger.FieldUserID, user.Id)
+	token, err := a.signToken(ctx, uint(user.Id), user.Email, "", a.ProfileFallback.tokenProfile(user))
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, REGISTER_RPC_TOKEN_ERROR)
 	}
 
-	response := &authpb.RegisterResponse{
+	response = &authpb.RegisterResponse{
 		Message: constant.MessageOK,
 		Data: &authpb.RegisterResponseData{
 			Token: token,
@@ -59,22 +97,37 @@ func (a *AuthenticationServer) Register(ctx context.Context, data *authpb.Regist
 	return response, nil
 }
 
-func (a *AuthenticationServer) Login(ctx context.Context, data *authpb.LoginRequest) (*authpb.LoginResponse, error) {
-	clientResponse, err := a.UserClient.FindByCredential(ctx, &userpb.FindByCredentialRequest{
+func (a *AuthenticationServer) Login(ctx context.Context, data *authpb.LoginRequest) (response *authpb.LoginResponse, err error) {
+	event := audit.NewEvent(ctx, audit.EventLogin)
+	event.EmailHash = audit.HashEmail(data.Email)
+	reason := constant.MetricLoginNone
+	defer func() {
+		a.recordAudit(ctx, event, err)
+		metrics.LoginAttempts.Add(ctx, 1, metric.WithAttributes(
+			metrics.OutcomeKey.String(event.Outcome),
+			metrics.ReasonKey.String(reason),
+		))
+	}()
+
+	clientResponse, err := a.users().FindByCredential(ctx, &userpb.FindByCredentialRequest{
 		Email:    data.Email,
 		Password: data.Password,
 	})
 	if err != nil {
+		reason = loginFailureReason(err)
 		return nil, err
 	}
 
 	user := clientResponse.Data.User
-	token, err := a.JWTManager.NewToken(uint(user.Id), user.Name)
+	event.UserID = int(user.Id)
+	logger.AddField(ctx, logger.FieldUserID, user.Id)
+	token, err := a.signToken(ctx, uint(user.Id), user.Name, "", a.ProfileFallback.tokenProfile(user))
 	if err != nil {
+		reason = constant.MetricLoginTokenError
 		return nil, status.Error(codes.Unauthenticated, constant.MessageUnauthorized)
 	}
 
-	response := &authpb.LoginResponse{
+	response = &authpb.LoginResponse{
 		Message: constant.MessageOK,
 		Data: &authpb.LoginResponseData{
 			Token: token,
@@ -91,23 +144,60 @@ func (a *AuthenticationServer) Login(ctx context.Context, data *authpb.LoginRequ
 	return response, nil
 }
 
-func (a *AuthenticationServer) VerifyToken(ctx context.Context, data *authpb.VerifyTokenRequest) (*authpb.VerifyTokenResponse, error) {
-	claims, err := parseAndValidateJwtTokenFromMetadata(ctx, a.JWTManager)
+// loginFailureReason tells wrong credentials, which is what credential
+// stuffing looks like, apart from bad input and user-service failures.
+func loginFailureReason(err error) string {
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.NotFound:
+		return constant.MetricLoginInvalidCredentials
+	case codes.InvalidArgument:
+		return constant.MetricLoginInvalidRequest
+	default:
+		return constant.MetricLoginUserService
+	}
+}
+
+func (a *AuthenticationServer) VerifyToken(ctx context.Context, data *authpb.VerifyTokenRequest) (response *authpb.VerifyTokenResponse, err error) {
+	event := audit.NewEvent(ctx, audit.EventVerifyToken)
+	defer func() { a.recordAudit(ctx, event, err) }()
+
+	token, err := bearerTokenFromMetadata(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	userId := claims["id"].(float64)
+	var userId float64
+	var profile *jwt.Profile
+	if apikey.IsAPIKey(token) {
+		key, err := a.APIKeyManager.Verify(ctx, token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, constant.MessageUnauthorized)
+		}
+		userId = float64(key.UserID)
+		logger.AddField(ctx, logger.FieldUserID, key.UserID)
+	} else {
+		claims, err := parseAndValidateJwtToken(ctx, a.JWTManager, token, data.Audience)
+		if err != nil {
+			return nil, err
+		}
+		userId = claims["id"].(float64)
+		profile = jwt.ProfileFromClaims(claims)
+	}
+	event.UserID = int(userId)
 
-	clientResponse, err := a.UserClient.FindById(ctx, &userpb.FindByIdRequest{
+	var user *userpb.User
+	stale := false
+	clientResponse, err := a.users().FindById(ctx, &userpb.FindByIdRequest{
 		Id: int32(userId),
 	})
-	if err != nil {
+	if err == nil {
+		user = clientResponse.Data.User
+		a.ProfileFallback.remember(user)
+	} else if user, stale = a.ProfileFallback.lookup(ctx, int32(userId), profile, err); !stale {
 		return nil, status.Error(codes.Unauthenticated, constant.MessageUnauthorized)
 	}
 
-	user := clientResponse.Data.User
-	response := &authpb.VerifyTokenResponse{
+	response = &authpb.VerifyTokenResponse{
 		Message: constant.MessageOK,
 		Data: &authpb.VerifyTokenResponseData{
 			User: &authpb.User{
@@ -118,47 +208,132 @@ func (a *AuthenticationServer) VerifyToken(ctx context.Context, data *authpb.Ver
 				CreatedAt: user.CreatedAt,
 				UpdatedAt: user.UpdatedAt,
 			},
+			Stale: stale,
 		},
 	}
 	return response, nil
 }
 
-func (a *AuthenticationServer) Logout(ctx context.Context, data *authpb.LogoutRequest) (*authpb.LogoutResponse, error) {
+func (a *AuthenticationServer) Logout(ctx context.Context, data *authpb.LogoutRequest) (response *authpb.LogoutResponse, err error) {
+	event := audit.NewEvent(ctx, audit.EventLogout)
+	defer func() { a.recordAudit(ctx, event, err) }()
+
 	claims, err := parseAndValidateJwtTokenFromMetadata(ctx, a.JWTManager)
 	if err != nil {
 		return nil, err
 	}
+	event.UserID = claimsUserID(claims)
 
 	err = a.JWTManager.AddToBlacklist(ctx, claims["jti"].(string), int64(claims["exp"].(float64)))
 	if err != nil {
 		return nil, status.Error(codes.Unauthenticated, constant.MessageUnauthorized)
 	}
 
-	response := &authpb.LogoutResponse{
+	response = &authpb.LogoutResponse{
 		Message: constant.MessageOK,
 		Data:    &authpb.LogoutResponseData{},
 	}
 	return response, nil
 }
 
+func claimsUserID(claims libjwt.MapClaims) int {
+	id, _ := claims["id"].(float64)
+	return int(id)
+}
+
 func parseAndValidateJwtTokenFromMetadata(ctx context.Context, jwtManager jwt.JWTManager) (libjwt.MapClaims, error) {
-	authErr := status.Error(codes.Unauthenticated, constant.MessageUnauthorized)
+	token, err := bearerTokenFromMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	md, _ := metadata.FromIncomingContext(ctx)
-	header, _ := helper.GetGRPCMetadataValue(md, constant.HeaderAuthorization)
-	token, f := strings.CutPrefix(header, constant.HeaderBearerPrefix)
-	if !f {
-		return nil, authErr
+	return parseAndValidateJwtToken(ctx, jwtManager, token, "")
+}
+
+func bearerTokenFromMetadata(ctx context.Context) (string, error) {
+	token, ok := authguard.BearerToken(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, constant.MessageUnauthorized)
 	}
 
+	return token, nil
+}
+
+// parseAndValidateJwtToken takes user tokens and the tokens token exchange
+// issued to audience. An exchanged token is bound to the service it was
+// issued to, so it is rejected everywhere else, including here when no
+// audience is given.
+func parseAndValidateJwtToken(ctx context.Context, jwtManager jwt.JWTManager, token string, audience string) (libjwt.MapClaims, error) {
+	return validateJwtToken(ctx, jwtManager, token, func(claims libjwt.MapClaims) bool {
+		return audienceAllowed(claims, audience)
+	})
+}
+
+// parseAndValidateExchangeToken takes tokens for any audience: trading the
+// token a service was given for one to the next service is what token
+// exchange is for.
+func parseAndValidateExchangeToken(ctx context.Context, jwtManager jwt.JWTManager, token string) (libjwt.MapClaims, error) {
+	return validateJwtToken(ctx, jwtManager, token, nil)
+}
+
+func validateJwtToken(ctx context.Context, jwtManager jwt.JWTManager, token string, audienceOK func(claims libjwt.MapClaims) bool) (libjwt.MapClaims, error) {
+	authErr := status.Error(codes.Unauthenticated, constant.MessageUnauthorized)
+
+	_, span := startSpan(ctx, SpanParseToken)
 	claims, err := jwtManager.ParseToken(token)
+	if err == nil && audienceOK != nil && !audienceOK(claims) {
+		err = jwt.ErrWrongAudience
+	}
 	if err != nil {
+		reason := jwt.VerificationFailureReason(err)
+		span.AddEvent(EventTokenRejected, trace.WithAttributes(FailureReasonKey.String(reason)))
+		span.SetAttributes(OutcomeKey.String(audit.OutcomeFailure), FailureReasonKey.String(reason))
+		endSpan(span, err)
+		metrics.TokenVerificationFailures.Add(ctx, 1, metric.WithAttributes(metrics.ReasonKey.String(reason)))
 		return nil, authErr
 	}
+	jti, _ := claims["jti"].(string)
+	span.SetAttributes(OutcomeKey.String(audit.OutcomeSuccess), UserIDKey.Int(claimsUserID(claims)), TokenIDHashKey.String(hashTokenID(jti)))
+	endSpan(span, nil)
 
-	if blacklisted := jwtManager.IsBlacklisted(ctx, claims["jti"].(string)); blacklisted {
+	if blacklisted := isBlacklisted(ctx, jwtManager, jti); blacklisted {
+		metrics.TokenVerificationFailures.Add(ctx, 1, metric.WithAttributes(metrics.ReasonKey.String(constant.MetricVerifyRevoked)))
 		return nil, authErr
 	}
+	logger.AddField(ctx, logger.FieldUserID, claims["id"])
 
 	return claims, nil
 }
+
+// audienceAllowed takes tokens without an aud claim anywhere and the others
+// only for one of their audiences.
+func audienceAllowed(claims libjwt.MapClaims, audience string) bool {
+	audiences, err := claims.GetAudience()
+	if err != nil {
+		return false
+	}
+	if len(audiences) == 0 {
+		return true
+	}
+	return audience != "" && slices.Contains(audiences, audience)
+}
+
+// isBlacklisted checks jti against the logout blacklist in its own span. A
+// hit is a revoked token being replayed, so it is also recorded as an event on
+// the RPC span where it stands out in the trace view.
+func isBlacklisted(ctx context.Context, jwtManager jwt.JWTManager, jti string) bool {
+	jtiHash := TokenIDHashKey.String(hashTokenID(jti))
+
+	spanCtx, span := startSpan(ctx, SpanBlacklistCheck, jtiHash)
+	defer span.End()
+
+	if !jwtManager.IsBlacklisted(spanCtx, jti) {
+		span.SetAttributes(BlacklistKey.String(constant.MetricBlacklistMiss))
+		return false
+	}
+
+	span.SetAttributes(BlacklistKey.String(constant.MetricBlacklistHit))
+	span.AddEvent(EventTokenRevoked, trace.WithAttributes(jtiHash))
+	trace.SpanFromContext(ctx).AddEvent(EventTokenRevoked, trace.WithAttributes(jtiHash))
+	return true
+}
@@ -165,6 +165,7 @@ func TestAuthenticationServer_VerifyToken(t *testing.T) {
 		name        string
 		setupMocks  func(u *MockUserClient, j *MockJWTManager)
 		inputCtx    context.Context
+		audience    string
 		expectErr   bool
 		expectedMsg string
 	}{
@@ -188,6 +189,35 @@ func TestAuthenticationServer_VerifyToken(t *testing.T) {
 			inputCtx:  ctx,
 			expectErr: true,
 		},
+		{
+			name: "exchanged token presented by its audience",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
+				j.On("ParseToken", "validtoken").Return(libjwt.MapClaims{"id": float64(dummyUser.Id), "jti": "1", "aud": "video-service"}, nil)
+				j.On("IsBlacklisted", mock.Anything, "1").Return(false)
+				u.On("FindById", mock.Anything, mock.Anything).Return(&userpb.FindByIdResponse{Data: &userpb.FindByIdResponseData{User: dummyUser}}, nil)
+			},
+			inputCtx:    ctx,
+			audience:    "video-service",
+			expectErr:   false,
+			expectedMsg: constant.MessageOK,
+		},
+		{
+			name: "exchanged token presented by another service",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
+				j.On("ParseToken", "validtoken").Return(libjwt.MapClaims{"id": float64(dummyUser.Id), "jti": "1", "aud": "video-service"}, nil)
+			},
+			inputCtx:  ctx,
+			audience:  "billing-service",
+			expectErr: true,
+		},
+		{
+			name: "exchanged token without an audience in the request",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
+				j.On("ParseToken", "validtoken").Return(libjwt.MapClaims{"id": float64(dummyUser.Id), "jti": "1", "aud": "video-service"}, nil)
+			},
+			inputCtx:  ctx,
+			expectErr: true,
+		},
 		{
 			name: "invalid token",
 			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
@@ -207,7 +237,7 @@ func TestAuthenticationServer_VerifyToken(t *testing.T) {
 			}
 
 			s := &server.AuthenticationServer{UserClient: u, JWTManager: j}
-			resp, err := s.VerifyToken(tt.inputCtx, &authpb.VerifyTokenRequest{})
+			resp, err := s.VerifyToken(tt.inputCtx, &authpb.VerifyTokenRequest{Audience: tt.audience})
 
 			if tt.expectErr {
 				assert.Error(t, err)
@@ -0,0 +1,177 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	libjwt "github.com/golang-jwt/jwt/v5"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/apikey"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthenticationServer_Authorize(t *testing.T) {
+	scopedClaims := func(scope any) libjwt.MapClaims {
+		claims := libjwt.MapClaims{"id": float64(dummyUser.Id), "jti": "1"}
+		if scope != nil {
+			claims["scope"] = scope
+		}
+		return claims
+	}
+	validToken := func(j *MockJWTManager, claims libjwt.MapClaims) {
+		j.On("ParseToken", "validtoken").Return(claims, nil)
+		j.On("IsBlacklisted", mock.Anything, "1").Return(false)
+	}
+
+	tests := []struct {
+		name           string
+		setupMocks     func(j *MockJWTManager, k *MockAPIKeyManager)
+		inputCtx       context.Context
+		input          *authpb.AuthorizeRequest
+		expectCode     codes.Code
+		expectedAllow  bool
+		expectedReason string
+	}{
+		{
+			name: "granted by token scope",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				validToken(j, scopedClaims("videos:read videos:upload"))
+			},
+			input:          &authpb.AuthorizeRequest{Token: "validtoken", Permission: "videos:upload"},
+			expectedAllow:  true,
+			expectedReason: constant.AuthzReasonGranted,
+		},
+		{
+			name: "token from metadata",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				validToken(j, scopedClaims("videos:*"))
+			},
+			inputCtx:       withBearer("validtoken"),
+			input:          &authpb.AuthorizeRequest{Permission: "videos:delete", Resource: "42"},
+			expectedAllow:  true,
+			expectedReason: constant.AuthzReasonGranted,
+		},
+		{
+			name: "missing permission",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				validToken(j, scopedClaims("videos:read"))
+			},
+			input:          &authpb.AuthorizeRequest{Token: "validtoken", Permission: "videos:delete"},
+			expectedReason: constant.AuthzReasonMissingPermission,
+		},
+		{
+			name: "exchanged token for its audience",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				claims := scopedClaims("videos:read")
+				claims["aud"] = "video-service"
+				validToken(j, claims)
+			},
+			input:          &authpb.AuthorizeRequest{Token: "validtoken", Permission: "videos:read", Audience: "video-service"},
+			expectedAllow:  true,
+			expectedReason: constant.AuthzReasonGranted,
+		},
+		{
+			name: "exchanged token for another audience",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				claims := scopedClaims("videos:read")
+				claims["aud"] = "video-service"
+				j.On("ParseToken", "validtoken").Return(claims, nil)
+			},
+			input:          &authpb.AuthorizeRequest{Token: "validtoken", Permission: "videos:read"},
+			expectedReason: constant.AuthzReasonInvalidCredential,
+		},
+		{
+			name: "token without scopes",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				validToken(j, scopedClaims(nil))
+			},
+			input:          &authpb.AuthorizeRequest{Token: "validtoken", Permission: "videos:read"},
+			expectedReason: constant.AuthzReasonNoScopes,
+		},
+		{
+			name: "token with empty scope",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				validToken(j, scopedClaims(""))
+			},
+			input:          &authpb.AuthorizeRequest{Token: "validtoken", Permission: "videos:read"},
+			expectedReason: constant.AuthzReasonNoScopes,
+		},
+		{
+			name: "invalid token",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				j.On("ParseToken", "validtoken").Return(nil, errors.New("expired"))
+			},
+			input:          &authpb.AuthorizeRequest{Token: "validtoken", Permission: "videos:read"},
+			expectedReason: constant.AuthzReasonInvalidCredential,
+		},
+		{
+			name:           "no credential",
+			input:          &authpb.AuthorizeRequest{Permission: "videos:read"},
+			expectedReason: constant.AuthzReasonInvalidCredential,
+		},
+		{
+			name: "granted by api key scope",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				k.On("Verify", mock.Anything, "ak_abc123_secret").Return(dummyAPIKey, nil)
+			},
+			input:          &authpb.AuthorizeRequest{Token: "ak_abc123_secret", Permission: "videos:read"},
+			expectedAllow:  true,
+			expectedReason: constant.AuthzReasonGranted,
+		},
+		{
+			name: "api key without scopes",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				unscoped := *dummyAPIKey
+				unscoped.Scopes = nil
+				k.On("Verify", mock.Anything, "ak_abc123_secret").Return(&unscoped, nil)
+			},
+			input:          &authpb.AuthorizeRequest{Token: "ak_abc123_secret", Permission: "videos:read"},
+			expectedReason: constant.AuthzReasonNoScopes,
+		},
+		{
+			name: "revoked api key",
+			setupMocks: func(j *MockJWTManager, k *MockAPIKeyManager) {
+				k.On("Verify", mock.Anything, "ak_abc123_secret").Return(nil, apikey.ErrInvalidKey)
+			},
+			input:          &authpb.AuthorizeRequest{Token: "ak_abc123_secret", Permission: "videos:read"},
+			expectedReason: constant.AuthzReasonInvalidCredential,
+		},
+		{
+			name:       "missing permission argument",
+			input:      &authpb.AuthorizeRequest{Token: "validtoken"},
+			expectCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := new(MockJWTManager)
+			k := new(MockAPIKeyManager)
+			if tt.setupMocks != nil {
+				tt.setupMocks(j, k)
+			}
+			ctx := tt.inputCtx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			s := &server.AuthenticationServer{JWTManager: j, APIKeyManager: k}
+			resp, err := s.Authorize(ctx, tt.input)
+
+			assert.Equal(t, tt.expectCode, status.Code(err))
+			if tt.expectCode == codes.OK {
+				assert.Equal(t, tt.expectedAllow, resp.Data.Allowed)
+				assert.Equal(t, tt.expectedReason, resp.Data.Reason)
+			}
+
+			j.AssertExpectations(t)
+			k.AssertExpectations(t)
+		})
+	}
+}
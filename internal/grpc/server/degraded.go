@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/jwt"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	userpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/user"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// EventProfileFallback marks a VerifyToken answered with a stale profile.
+const EventProfileFallback = "auth.profile_fallback"
+
+// FallbackSourceKey is where a stale profile came from: cache or token.
+const FallbackSourceKey = attribute.Key("auth.fallback.source")
+
+// ProfileFallback is degraded mode for VerifyToken: while the user service
+// can't be reached, users come from the profiles cached on earlier lookups
+// or, failing that, from the profile signed into the token when it was
+// issued. The token itself is still fully verified.
+type ProfileFallback struct {
+	cache *profileCache
+}
+
+func NewProfileFallback(cfg *config.DegradedMode) *ProfileFallback {
+	return &ProfileFallback{cache: newProfileCache(cfg.CacheTTL, cfg.CacheSize)}
+}
+
+// remember keeps user for later fallbacks. It does nothing on a nil
+// fallback, as do the other methods, so callers needn't check.
+func (f *ProfileFallback) remember(user *userpb.User) {
+	if f == nil {
+		return
+	}
+	f.cache.set(user.Id, user)
+}
+
+// tokenProfile is the profile to sign into user's tokens, nil unless degraded
+// mode is on.
+func (f *ProfileFallback) tokenProfile(user *userpb.User) *jwt.Profile {
+	if f == nil {
+		return nil
+	}
+	return &jwt.Profile{
+		Name:      user.Name,
+		Email:     user.Email,
+		Image:     user.Image,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// lookup returns a stale profile when err means the user service could not be
+// reached, from the cache or else from the token's profile. Credentials
+// without one, like API keys, only fall back to the cache. Any other error is
+// an answer, e.g. NotFound for a deleted user, and gets no fallback.
+func (f *ProfileFallback) lookup(ctx context.Context, userId int32, profile *jwt.Profile, err error) (*userpb.User, bool) {
+	if f == nil || !userServiceUnavailable(err) {
+		return nil, false
+	}
+
+	source := constant.MetricFallbackCache
+	user, ok := f.cache.get(userId)
+	if !ok {
+		if profile == nil {
+			logger.WarnCtx(ctx, "User service unavailable and no profile to fall back to for user %d: %v", userId, err)
+			return nil, false
+		}
+		source = constant.MetricFallbackToken
+		user = &userpb.User{
+			Id:        userId,
+			Name:      profile.Name,
+			Email:     profile.Email,
+			Image:     profile.Image,
+			CreatedAt: profile.CreatedAt,
+			UpdatedAt: profile.UpdatedAt,
+		}
+	}
+
+	metrics.ProfileFallbacks.Add(ctx, 1, metric.WithAttributes(metrics.SourceKey.String(source)))
+	trace.SpanFromContext(ctx).AddEvent(EventProfileFallback, trace.WithAttributes(FallbackSourceKey.String(source)))
+	logger.WarnCtx(ctx, "User service unavailable, verifying user %d with a profile from the %s: %v", userId, source, err)
+	return user, true
+}
+
+func userServiceUnavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+type profileCacheEntry struct {
+	user      *userpb.User
+	expiresAt time.Time
+}
+
+// profileCache is a small TTL cache of user profiles keyed by user id. Once
+// full, expired entries are swept and, failing that, new users are simply not
+// cached until space frees up.
+type profileCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	entries map[int32]profileCacheEntry
+}
+
+func newProfileCache(ttl time.Duration, size int) *profileCache {
+	return &profileCache{
+		ttl:     ttl,
+		size:    size,
+		entries: map[int32]profileCacheEntry{},
+	}
+}
+
+func (c *profileCache) get(id int32) (*userpb.User, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, id)
+		return nil, false
+	}
+	return entry.user, true
+}
+
+func (c *profileCache) set(id int32, user *userpb.User) {
+	if c.ttl <= 0 || c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if _, ok := c.entries[id]; !ok && len(c.entries) >= c.size {
+		for k, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= c.size {
+			return
+		}
+	}
+	c.entries[id] = profileCacheEntry{user: user, expiresAt: now.Add(c.ttl)}
+}
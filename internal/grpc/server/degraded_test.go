@@ -0,0 +1,227 @@
+package server_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	libjwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/jwt"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics/metricstest"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	userpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/user"
+)
+
+var degradedMode = &config.DegradedMode{Enabled: true, CacheTTL: time.Minute, CacheSize: 10}
+
+// profileCaller accepts "profiletoken", a token carrying dummyUser's profile
+// the way ParseToken returns it.
+func profileCaller(j *MockJWTManager) {
+	j.On("ParseToken", "profiletoken").Return(libjwt.MapClaims{
+		"id":       float64(dummyUser.Id),
+		"username": dummyUser.Name,
+		"jti":      "2",
+		"profile":  map[string]any{"name": dummyUser.Name, "email": dummyUser.Email, "created_at": *dummyUser.CreatedAt},
+	}, nil)
+	j.On("IsBlacklisted", mock.Anything, "2").Return(false)
+}
+
+func TestAuthenticationServer_VerifyToken_DegradedMode(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	found := &userpb.FindByIdResponse{Data: &userpb.FindByIdResponseData{User: dummyUser}}
+
+	tests := []struct {
+		name           string
+		fallback       *config.DegradedMode
+		warmCache      bool
+		token          string
+		userErr        error
+		expectedCode   codes.Code
+		expectedUser   *authpb.User
+		expectedSource string
+	}{
+		{
+			name:         "user service up",
+			fallback:     degradedMode,
+			token:        "validtoken",
+			expectedCode: codes.OK,
+			expectedUser: &authpb.User{Id: dummyUser.Id, Name: dummyUser.Name, Email: dummyUser.Email, CreatedAt: dummyUser.CreatedAt},
+		},
+		{
+			name:           "cached profile",
+			fallback:       degradedMode,
+			warmCache:      true,
+			token:          "validtoken",
+			userErr:        unavailable,
+			expectedCode:   codes.OK,
+			expectedUser:   &authpb.User{Id: dummyUser.Id, Name: dummyUser.Name, Email: dummyUser.Email, CreatedAt: dummyUser.CreatedAt},
+			expectedSource: constant.MetricFallbackCache,
+		},
+		{
+			name:           "profile from the token",
+			fallback:       degradedMode,
+			token:          "profiletoken",
+			userErr:        status.Error(codes.DeadlineExceeded, "timed out"),
+			expectedCode:   codes.OK,
+			expectedUser:   &authpb.User{Id: dummyUser.Id, Name: dummyUser.Name, Email: dummyUser.Email, CreatedAt: dummyUser.CreatedAt},
+			expectedSource: constant.MetricFallbackToken,
+		},
+		{
+			name:         "token without a profile",
+			fallback:     degradedMode,
+			token:        "validtoken",
+			userErr:      unavailable,
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "api key without a cached profile",
+			fallback:     degradedMode,
+			token:        "ak_abc123_secret",
+			userErr:      unavailable,
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:           "cache turned off",
+			fallback:       &config.DegradedMode{Enabled: true},
+			warmCache:      true,
+			token:          "profiletoken",
+			userErr:        unavailable,
+			expectedCode:   codes.OK,
+			expectedUser:   &authpb.User{Id: dummyUser.Id, Name: dummyUser.Name, Email: dummyUser.Email, CreatedAt: dummyUser.CreatedAt},
+			expectedSource: constant.MetricFallbackToken,
+		},
+		{
+			name:         "deleted user gets no fallback",
+			fallback:     degradedMode,
+			warmCache:    true,
+			token:        "validtoken",
+			userErr:      status.Error(codes.NotFound, "user not found"),
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "degraded mode off",
+			token:        "validtoken",
+			userErr:      unavailable,
+			expectedCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, j, k := new(MockUserClient), new(MockJWTManager), new(MockAPIKeyManager)
+			validCaller(j)
+			profileCaller(j)
+			k.On("Verify", mock.Anything, "ak_abc123_secret").Return(dummyAPIKey, nil)
+			s := &server.AuthenticationServer{UserClient: u, JWTManager: j, APIKeyManager: k}
+			if tt.fallback != nil {
+				s.ProfileFallback = server.NewProfileFallback(tt.fallback)
+			}
+			reader := metricstest.New(t)
+
+			if tt.warmCache {
+				u.On("FindById", mock.Anything, mock.Anything).Return(found, nil).Once()
+				_, err := s.VerifyToken(withBearer(tt.token), &authpb.VerifyTokenRequest{})
+				require.NoError(t, err)
+			}
+			if tt.userErr != nil {
+				u.On("FindById", mock.Anything, mock.Anything).Return(nil, tt.userErr)
+			} else {
+				u.On("FindById", mock.Anything, mock.Anything).Return(found, nil)
+			}
+
+			res, err := s.VerifyToken(withBearer(tt.token), &authpb.VerifyTokenRequest{})
+
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedCode != codes.OK {
+				assert.Nil(t, res)
+				return
+			}
+			assert.Equal(t, tt.expectedUser, res.Data.User)
+			assert.Equal(t, tt.expectedSource != "", res.Data.Stale)
+			if tt.expectedSource != "" {
+				assert.Equal(t, 1.0, reader.Value(t, "auth_profile_fallbacks_total", metrics.SourceKey.String(tt.expectedSource)))
+			}
+		})
+	}
+}
+
+func TestAuthenticationServer_VerifyToken_DegradedMode_CacheExpires(t *testing.T) {
+	metricstest.New(t)
+	u, j := new(MockUserClient), new(MockJWTManager)
+	validCaller(j)
+	s := &server.AuthenticationServer{
+		UserClient:      u,
+		JWTManager:      j,
+		ProfileFallback: server.NewProfileFallback(&config.DegradedMode{Enabled: true, CacheTTL: 10 * time.Millisecond, CacheSize: 10}),
+	}
+
+	u.On("FindById", mock.Anything, mock.Anything).Return(&userpb.FindByIdResponse{
+		Data: &userpb.FindByIdResponseData{User: dummyUser},
+	}, nil).Once()
+	_, err := s.VerifyToken(withBearer("validtoken"), &authpb.VerifyTokenRequest{})
+	require.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+	u.On("FindById", mock.Anything, mock.Anything).Return(nil, status.Error(codes.Unavailable, "down"))
+	res, err := s.VerifyToken(context.Background(), &authpb.VerifyTokenRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "no token, no fallback")
+	assert.Nil(t, res)
+
+	res, err = s.VerifyToken(withBearer("validtoken"), &authpb.VerifyTokenRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "expired profile isn't served")
+	assert.Nil(t, res)
+}
+
+func TestAuthenticationServer_Login_DegradedModeSignsProfile(t *testing.T) {
+	loginResp := &userpb.FindByCredentialResponse{Data: &userpb.FindByCredentialResponseData{User: dummyUser}}
+	profile := &jwt.Profile{Name: dummyUser.Name, Email: dummyUser.Email, CreatedAt: dummyUser.CreatedAt}
+
+	tests := []struct {
+		name       string
+		fallback   *config.DegradedMode
+		setupMocks func(j *MockJWTManager)
+	}{
+		{
+			name:     "degraded mode on",
+			fallback: degradedMode,
+			setupMocks: func(j *MockJWTManager) {
+				j.On("NewProfileToken", uint(dummyUser.Id), dummyUser.Name, profile).Return("token123", nil)
+			},
+		},
+		{
+			name: "degraded mode off",
+			setupMocks: func(j *MockJWTManager) {
+				j.On("NewToken", uint(dummyUser.Id), dummyUser.Name).Return("token123", nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metricstest.New(t)
+			u, j := new(MockUserClient), new(MockJWTManager)
+			u.On("FindByCredential", mock.Anything, mock.Anything).Return(loginResp, nil)
+			tt.setupMocks(j)
+			s := &server.AuthenticationServer{UserClient: u, JWTManager: j}
+			if tt.fallback != nil {
+				s.ProfileFallback = server.NewProfileFallback(tt.fallback)
+			}
+
+			res, err := s.Login(context.Background(), &authpb.LoginRequest{Email: dummyUser.Email, Password: "secret"})
+
+			require.NoError(t, err)
+			assert.Equal(t, "token123", res.Data.Token)
+			j.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	libjwt "github.com/golang-jwt/jwt/v5"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/audit"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/device"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (a *AuthenticationServer) DeviceAuthorization(ctx context.Context, data *authpb.DeviceAuthorizationRequest) (response *authpb.DeviceAuthorizationResponse, err error) {
+	event := audit.NewEvent(ctx, audit.EventDeviceAuthorization)
+	defer func() { a.recordAudit(ctx, event, err) }()
+
+	if data.ClientId == "" {
+		return nil, status.Error(codes.InvalidArgument, constant.OAuthErrorInvalidRequest)
+	}
+
+	auth, err := a.DeviceManager.Authorize(ctx, data.ClientId, data.Scope)
+	if err != nil {
+		logger.ErrorCtx(ctx, "Device authorization failed: %v", err)
+		return nil, status.Error(codes.Internal, constant.MessageInternalServerError)
+	}
+
+	response = &authpb.DeviceAuthorizationResponse{
+		Message: constant.MessageOK,
+		Data: &authpb.DeviceAuthorizationResponseData{
+			DeviceCode:              auth.DeviceCode,
+			UserCode:                device.FormatUserCode(auth.UserCode),
+			VerificationUri:         auth.VerificationURI,
+			VerificationUriComplete: auth.VerificationURIComplete,
+			ExpiresIn:               auth.ExpiresAt - time.Now().Unix(),
+			Interval:                auth.Interval,
+		},
+	}
+	return response, nil
+}
+
+func (a *AuthenticationServer) VerifyDevice(ctx context.Context, data *authpb.VerifyDeviceRequest) (response *authpb.VerifyDeviceResponse, err error) {
+	event := audit.NewEvent(ctx, audit.EventVerifyDevice)
+	defer func() { a.recordAudit(ctx, event, err) }()
+
+	claims, err := parseAndValidateJwtTokenFromMetadata(ctx, a.JWTManager)
+	if err != nil {
+		return nil, err
+	}
+	event.UserID = claimsUserID(claims)
+
+	if data.Deny {
+		event.Reason = audit.ReasonUserDenied
+		err = a.DeviceManager.Deny(ctx, data.UserCode)
+	} else {
+		err = a.approveDevice(ctx, claims, data.UserCode)
+	}
+	if status.Code(err) == codes.InvalidArgument {
+		return nil, err
+	}
+	if errors.Is(err, device.ErrInvalidUserCode) {
+		return nil, status.Error(codes.NotFound, constant.MessageNotFound)
+	}
+	if err != nil {
+		logger.ErrorCtx(ctx, "Device verification failed: %v", err)
+		return nil, status.Error(codes.Internal, constant.MessageInternalServerError)
+	}
+
+	response = &authpb.VerifyDeviceResponse{
+		Message: constant.MessageOK,
+		Data:    &authpb.VerifyDeviceResponseData{},
+	}
+	return response, nil
+}
+
+// approveDevice grants the device what it asked for, which must be within what
+// the approving user's own token allows, as with token exchange. The device
+// token is later issued with that scope.
+func (a *AuthenticationServer) approveDevice(ctx context.Context, claims libjwt.MapClaims, userCode string) error {
+	auth, err := a.DeviceManager.Lookup(ctx, userCode)
+	if err != nil {
+		return err
+	}
+	if auth.Scope != "" {
+		if _, ok := downscope(claims, auth.Scope); !ok {
+			return status.Error(codes.InvalidArgument, constant.OAuthErrorInvalidScope)
+		}
+	}
+
+	return a.DeviceManager.Approve(ctx, userCode, uint(claims["id"].(float64)), claims["username"].(string))
+}
+
+func (a *AuthenticationServer) DeviceToken(ctx context.Context, data *authpb.DeviceTokenRequest) (response *authpb.DeviceTokenResponse, err error) {
+	event := audit.NewEvent(ctx, audit.EventDeviceToken)
+	defer func() { a.recordAudit(ctx, event, err) }()
+
+	if data.DeviceCode == "" || data.ClientId == "" {
+		return nil, status.Error(codes.InvalidArgument, constant.OAuthErrorInvalidRequest)
+	}
+
+	auth, err := a.DeviceManager.Poll(ctx, data.DeviceCode, data.ClientId)
+	if err != nil {
+		return nil, deviceTokenError(err)
+	}
+	event.UserID = int(auth.UserID)
+
+	token, err := a.signToken(ctx, auth.UserID, auth.Username, auth.Scope, nil)
+	if err != nil {
+		return nil, status.Error(codes.Internal, constant.OAuthErrorServerError)
+	}
+
+	response = &authpb.DeviceTokenResponse{
+		Message: constant.MessageOK,
+		Data: &authpb.DeviceTokenResponseData{
+			Token: token,
+		},
+	}
+	return response, nil
+}
+
+// deviceTokenError keeps the RFC 8628 error code as the status message so
+// HTTP callers can relay it verbatim in the "error" field.
+func deviceTokenError(err error) error {
+	switch {
+	case errors.Is(err, device.ErrAuthorizationPending):
+		return status.Error(codes.FailedPrecondition, constant.OAuthErrorAuthorizationPending)
+	case errors.Is(err, device.ErrSlowDown):
+		return status.Error(codes.ResourceExhausted, constant.OAuthErrorSlowDown)
+	case errors.Is(err, device.ErrAccessDenied):
+		return status.Error(codes.PermissionDenied, constant.OAuthErrorAccessDenied)
+	case errors.Is(err, device.ErrExpiredToken):
+		return status.Error(codes.NotFound, constant.OAuthErrorExpiredToken)
+	case errors.Is(err, device.ErrClientMismatch):
+		return status.Error(codes.InvalidArgument, constant.OAuthErrorInvalidGrant)
+	default:
+		logger.Error("Device token poll failed: %v", err)
+		return status.Error(codes.Internal, constant.OAuthErrorServerError)
+	}
+}
@@ -0,0 +1,282 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	libjwt "github.com/golang-jwt/jwt/v5"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/device"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthenticationServer_DeviceAuthorization(t *testing.T) {
+	auth := &device.Authorization{
+		DeviceCode:              "device123",
+		UserCode:                "BCDFGHJK",
+		Interval:                5,
+		ExpiresAt:               time.Now().Add(10 * time.Minute).Unix(),
+		VerificationURI:         "https://example.com/device",
+		VerificationURIComplete: "https://example.com/device?user_code=BCDF-GHJK",
+	}
+
+	tests := []struct {
+		name       string
+		setupMocks func(d *MockDeviceManager)
+		input      *authpb.DeviceAuthorizationRequest
+		expectCode codes.Code
+	}{
+		{
+			name: "success",
+			setupMocks: func(d *MockDeviceManager) {
+				d.On("Authorize", mock.Anything, "tv-app", "profile").Return(auth, nil)
+			},
+			input:      &authpb.DeviceAuthorizationRequest{ClientId: "tv-app", Scope: "profile"},
+			expectCode: codes.OK,
+		},
+		{
+			name:       "missing client id",
+			input:      &authpb.DeviceAuthorizationRequest{},
+			expectCode: codes.InvalidArgument,
+		},
+		{
+			name: "manager fails",
+			setupMocks: func(d *MockDeviceManager) {
+				d.On("Authorize", mock.Anything, "tv-app", "").Return(nil, errors.New("redis down"))
+			},
+			input:      &authpb.DeviceAuthorizationRequest{ClientId: "tv-app"},
+			expectCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := new(MockDeviceManager)
+			if tt.setupMocks != nil {
+				tt.setupMocks(d)
+			}
+
+			s := &server.AuthenticationServer{DeviceManager: d}
+			resp, err := s.DeviceAuthorization(context.Background(), tt.input)
+
+			assert.Equal(t, tt.expectCode, status.Code(err))
+			if tt.expectCode == codes.OK {
+				assert.Equal(t, "device123", resp.Data.DeviceCode)
+				assert.Equal(t, "BCDF-GHJK", resp.Data.UserCode)
+				assert.Equal(t, auth.VerificationURIComplete, resp.Data.VerificationUriComplete)
+				assert.Equal(t, int64(5), resp.Data.Interval)
+				assert.InDelta(t, 600, resp.Data.ExpiresIn, 2)
+			}
+
+			d.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthenticationServer_VerifyDevice(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer validtoken"))
+	claims := libjwt.MapClaims{"id": float64(dummyUser.Id), "username": dummyUser.Name, "jti": "1"}
+
+	tests := []struct {
+		name       string
+		setupMocks func(j *MockJWTManager, d *MockDeviceManager)
+		inputCtx   context.Context
+		input      *authpb.VerifyDeviceRequest
+		expectCode codes.Code
+	}{
+		{
+			name: "approve",
+			setupMocks: func(j *MockJWTManager, d *MockDeviceManager) {
+				j.On("ParseToken", "validtoken").Return(claims, nil)
+				j.On("IsBlacklisted", mock.Anything, "1").Return(false)
+				d.On("Lookup", mock.Anything, "BCDF-GHJK").Return(&device.Authorization{}, nil)
+				d.On("Approve", mock.Anything, "BCDF-GHJK", uint(dummyUser.Id), dummyUser.Name).Return(nil)
+			},
+			inputCtx:   ctx,
+			input:      &authpb.VerifyDeviceRequest{UserCode: "BCDF-GHJK"},
+			expectCode: codes.OK,
+		},
+		{
+			name: "approve scope within the user's",
+			setupMocks: func(j *MockJWTManager, d *MockDeviceManager) {
+				scoped := libjwt.MapClaims{"id": float64(dummyUser.Id), "username": dummyUser.Name, "jti": "1", "scope": "videos:read videos:upload"}
+				j.On("ParseToken", "validtoken").Return(scoped, nil)
+				j.On("IsBlacklisted", mock.Anything, "1").Return(false)
+				d.On("Lookup", mock.Anything, "BCDF-GHJK").Return(&device.Authorization{Scope: "videos:read"}, nil)
+				d.On("Approve", mock.Anything, "BCDF-GHJK", uint(dummyUser.Id), dummyUser.Name).Return(nil)
+			},
+			inputCtx:   ctx,
+			input:      &authpb.VerifyDeviceRequest{UserCode: "BCDF-GHJK"},
+			expectCode: codes.OK,
+		},
+		{
+			name: "device asks for more than the user holds",
+			setupMocks: func(j *MockJWTManager, d *MockDeviceManager) {
+				scoped := libjwt.MapClaims{"id": float64(dummyUser.Id), "username": dummyUser.Name, "jti": "1", "scope": "videos:read"}
+				j.On("ParseToken", "validtoken").Return(scoped, nil)
+				j.On("IsBlacklisted", mock.Anything, "1").Return(false)
+				d.On("Lookup", mock.Anything, "BCDF-GHJK").Return(&device.Authorization{Scope: "videos:upload"}, nil)
+			},
+			inputCtx:   ctx,
+			input:      &authpb.VerifyDeviceRequest{UserCode: "BCDF-GHJK"},
+			expectCode: codes.InvalidArgument,
+		},
+		{
+			name: "deny",
+			setupMocks: func(j *MockJWTManager, d *MockDeviceManager) {
+				j.On("ParseToken", "validtoken").Return(claims, nil)
+				j.On("IsBlacklisted", mock.Anything, "1").Return(false)
+				d.On("Deny", mock.Anything, "BCDF-GHJK").Return(nil)
+			},
+			inputCtx:   ctx,
+			input:      &authpb.VerifyDeviceRequest{UserCode: "BCDF-GHJK", Deny: true},
+			expectCode: codes.OK,
+		},
+		{
+			name: "unknown user code",
+			setupMocks: func(j *MockJWTManager, d *MockDeviceManager) {
+				j.On("ParseToken", "validtoken").Return(claims, nil)
+				j.On("IsBlacklisted", mock.Anything, "1").Return(false)
+				d.On("Lookup", mock.Anything, "XXXX-XXXX").Return(nil, device.ErrInvalidUserCode)
+			},
+			inputCtx:   ctx,
+			input:      &authpb.VerifyDeviceRequest{UserCode: "XXXX-XXXX"},
+			expectCode: codes.NotFound,
+		},
+		{
+			name:       "not logged in",
+			inputCtx:   context.Background(),
+			input:      &authpb.VerifyDeviceRequest{UserCode: "BCDF-GHJK"},
+			expectCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := new(MockJWTManager)
+			d := new(MockDeviceManager)
+			if tt.setupMocks != nil {
+				tt.setupMocks(j, d)
+			}
+
+			s := &server.AuthenticationServer{JWTManager: j, DeviceManager: d}
+			_, err := s.VerifyDevice(tt.inputCtx, tt.input)
+			assert.Equal(t, tt.expectCode, status.Code(err))
+
+			j.AssertExpectations(t)
+			d.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthenticationServer_DeviceToken(t *testing.T) {
+	approved := &device.Authorization{UserID: uint(dummyUser.Id), Username: dummyUser.Name}
+	input := &authpb.DeviceTokenRequest{DeviceCode: "device123", ClientId: "tv-app"}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(j *MockJWTManager, d *MockDeviceManager)
+		input         *authpb.DeviceTokenRequest
+		expectCode    codes.Code
+		expectedMsg   string
+		expectedToken string
+	}{
+		{
+			name: "approved",
+			setupMocks: func(j *MockJWTManager, d *MockDeviceManager) {
+				d.On("Poll", mock.Anything, "device123", "tv-app").Return(approved, nil)
+				j.On("NewToken", uint(dummyUser.Id), dummyUser.Name).Return("token123", nil)
+			},
+			input:         input,
+			expectCode:    codes.OK,
+			expectedMsg:   constant.MessageOK,
+			expectedToken: "token123",
+		},
+		{
+			name: "approved with scope",
+			setupMocks: func(j *MockJWTManager, d *MockDeviceManager) {
+				scoped := &device.Authorization{UserID: uint(dummyUser.Id), Username: dummyUser.Name, Scope: "videos:read"}
+				d.On("Poll", mock.Anything, "device123", "tv-app").Return(scoped, nil)
+				j.On("NewScopedToken", uint(dummyUser.Id), dummyUser.Name, "videos:read").Return("token123", nil)
+			},
+			input:         input,
+			expectCode:    codes.OK,
+			expectedMsg:   constant.MessageOK,
+			expectedToken: "token123",
+		},
+		{
+			name: "authorization pending",
+			setupMocks: func(j *MockJWTManager, d *MockDeviceManager) {
+				d.On("Poll", mock.Anything, "device123", "tv-app").Return(nil, device.ErrAuthorizationPending)
+			},
+			input:       input,
+			expectCode:  codes.FailedPrecondition,
+			expectedMsg: constant.OAuthErrorAuthorizationPending,
+		},
+		{
+			name: "slow down",
+			setupMocks: func(j *MockJWTManager, d *MockDeviceManager) {
+				d.On("Poll", mock.Anything, "device123", "tv-app").Return(nil, device.ErrSlowDown)
+			},
+			input:       input,
+			expectCode:  codes.ResourceExhausted,
+			expectedMsg: constant.OAuthErrorSlowDown,
+		},
+		{
+			name: "access denied",
+			setupMocks: func(j *MockJWTManager, d *MockDeviceManager) {
+				d.On("Poll", mock.Anything, "device123", "tv-app").Return(nil, device.ErrAccessDenied)
+			},
+			input:       input,
+			expectCode:  codes.PermissionDenied,
+			expectedMsg: constant.OAuthErrorAccessDenied,
+		},
+		{
+			name: "expired",
+			setupMocks: func(j *MockJWTManager, d *MockDeviceManager) {
+				d.On("Poll", mock.Anything, "device123", "tv-app").Return(nil, device.ErrExpiredToken)
+			},
+			input:       input,
+			expectCode:  codes.NotFound,
+			expectedMsg: constant.OAuthErrorExpiredToken,
+		},
+		{
+			name:        "missing device code",
+			input:       &authpb.DeviceTokenRequest{ClientId: "tv-app"},
+			expectCode:  codes.InvalidArgument,
+			expectedMsg: constant.OAuthErrorInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := new(MockJWTManager)
+			d := new(MockDeviceManager)
+			if tt.setupMocks != nil {
+				tt.setupMocks(j, d)
+			}
+
+			s := &server.AuthenticationServer{JWTManager: j, DeviceManager: d}
+			resp, err := s.DeviceToken(context.Background(), tt.input)
+
+			assert.Equal(t, tt.expectCode, status.Code(err))
+			if tt.expectCode == codes.OK {
+				assert.Equal(t, tt.expectedMsg, resp.Message)
+				assert.Equal(t, tt.expectedToken, resp.Data.Token)
+			} else {
+				assert.Equal(t, tt.expectedMsg, status.Convert(err).Message())
+			}
+
+			j.AssertExpectations(t)
+			d.AssertExpectations(t)
+		})
+	}
+}
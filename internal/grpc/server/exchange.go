@@ -0,0 +1,105 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	libjwt "github.com/golang-jwt/jwt/v5"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/audit"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/jwt"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/rbac"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (a *AuthenticationServer) ExchangeToken(ctx context.Context, data *authpb.ExchangeTokenRequest) (response *authpb.ExchangeTokenResponse, err error) {
+	event := audit.NewEvent(ctx, audit.EventExchangeToken)
+	defer func() { a.recordAudit(ctx, event, err) }()
+
+	invalidRequest := status.Error(codes.InvalidArgument, constant.OAuthErrorInvalidRequest)
+
+	if data.SubjectToken == "" || data.Audience == "" ||
+		!isSupportedTokenType(data.SubjectTokenType) ||
+		!isSupportedTokenType(data.RequestedTokenType) ||
+		(data.ActorToken != "" && !isSupportedTokenType(data.ActorTokenType)) {
+		return nil, invalidRequest
+	}
+
+	// RFC 8693 section 2.2.2 reports unusable subject or actor tokens as
+	// invalid_request rather than an authentication failure.
+	subject, err := parseAndValidateExchangeToken(ctx, a.JWTManager, data.SubjectToken)
+	if err != nil {
+		return nil, invalidRequest
+	}
+	event.UserID = claimsUserID(subject)
+
+	var actor libjwt.MapClaims
+	if data.ActorToken != "" {
+		if actor, err = parseAndValidateExchangeToken(ctx, a.JWTManager, data.ActorToken); err != nil {
+			return nil, invalidRequest
+		}
+	}
+
+	scope, ok := downscope(subject, data.Scope)
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, constant.OAuthErrorInvalidScope)
+	}
+
+	_, span := startSpan(ctx, SpanSignToken, UserIDKey.Int(event.UserID))
+	token, expiry, err := a.JWTManager.NewExchangedToken(&jwt.TokenExchange{
+		Subject:  subject,
+		Actor:    actor,
+		Audience: data.Audience,
+		Scope:    scope,
+	})
+	endSpan(span, err)
+	if errors.Is(err, jwt.ErrAudienceNotAllowed) {
+		return nil, status.Error(codes.InvalidArgument, constant.OAuthErrorInvalidTarget)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, constant.OAuthErrorServerError)
+	}
+
+	response = &authpb.ExchangeTokenResponse{
+		Message: constant.MessageOK,
+		Data: &authpb.ExchangeTokenResponseData{
+			AccessToken:     token,
+			IssuedTokenType: constant.TokenTypeAccessToken,
+			ExpiresIn:       expiry - time.Now().Unix(),
+			Scope:           scope,
+		},
+	}
+	return response, nil
+}
+
+// downscope checks the requested scope against the subject token. Scopes are
+// globs as in rbac.Allowed, so a subject holding "videos:*" may ask for
+// "videos:read", but one holding "videos:read" can't ask for "videos:*".
+// Nothing is requested, the subject's scope is kept as is. A subject without a
+// scope claim, or with an empty one, holds no permissions, as far as Authorize
+// is concerned, so it can't ask for any either.
+func downscope(subject libjwt.MapClaims, requested string) (string, bool) {
+	granted, restricted := subject["scope"].(string)
+	if requested == "" {
+		return granted, true
+	}
+	if !restricted {
+		return "", false
+	}
+
+	grantedScopes := strings.Fields(granted)
+	for _, s := range strings.Fields(requested) {
+		if !rbac.Allowed(grantedScopes, s, "") {
+			return "", false
+		}
+	}
+	return requested, true
+}
+
+func isSupportedTokenType(tokenType string) bool {
+	return tokenType == "" || tokenType == constant.TokenTypeAccessToken || tokenType == constant.TokenTypeJWT
+}
@@ -0,0 +1,184 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	libjwt "github.com/golang-jwt/jwt/v5"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/jwt"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthenticationServer_ExchangeToken(t *testing.T) {
+	subject := libjwt.MapClaims{"id": float64(dummyUser.Id), "username": dummyUser.Name, "jti": "subject"}
+	scoped := libjwt.MapClaims{"id": float64(dummyUser.Id), "username": dummyUser.Name, "jti": "subject", "scope": "videos:read videos:write"}
+	actor := libjwt.MapClaims{"id": float64(2), "username": "upload-service", "jti": "actor"}
+	expiry := time.Now().Add(5 * time.Minute).Unix()
+
+	validSubject := func(j *MockJWTManager, claims libjwt.MapClaims) {
+		j.On("ParseToken", "subject-token").Return(claims, nil)
+		j.On("IsBlacklisted", mock.Anything, "subject").Return(false)
+	}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(j *MockJWTManager)
+		input         *authpb.ExchangeTokenRequest
+		expectCode    codes.Code
+		expectedMsg   string
+		expectedScope string
+	}{
+		{
+			name: "delegation with actor",
+			setupMocks: func(j *MockJWTManager) {
+				validSubject(j, scoped)
+				j.On("ParseToken", "actor-token").Return(actor, nil)
+				j.On("IsBlacklisted", mock.Anything, "actor").Return(false)
+				j.On("NewExchangedToken", &jwt.TokenExchange{
+					Subject: scoped, Actor: actor, Audience: "video-service", Scope: "videos:read",
+				}).Return("exchanged", expiry, nil)
+			},
+			input: &authpb.ExchangeTokenRequest{
+				SubjectToken:     "subject-token",
+				SubjectTokenType: constant.TokenTypeAccessToken,
+				ActorToken:       "actor-token",
+				ActorTokenType:   constant.TokenTypeJWT,
+				Audience:         "video-service",
+				Scope:            "videos:read",
+			},
+			expectCode:    codes.OK,
+			expectedScope: "videos:read",
+		},
+		{
+			name: "downscoping a scoped token",
+			setupMocks: func(j *MockJWTManager) {
+				validSubject(j, scoped)
+				j.On("NewExchangedToken", mock.Anything).Return("exchanged", expiry, nil)
+			},
+			input:         &authpb.ExchangeTokenRequest{SubjectToken: "subject-token", Audience: "video-service", Scope: "videos:write"},
+			expectCode:    codes.OK,
+			expectedScope: "videos:write",
+		},
+		{
+			name: "inherits subject scope when none requested",
+			setupMocks: func(j *MockJWTManager) {
+				validSubject(j, scoped)
+				j.On("NewExchangedToken", mock.Anything).Return("exchanged", expiry, nil)
+			},
+			input:         &authpb.ExchangeTokenRequest{SubjectToken: "subject-token", Audience: "video-service"},
+			expectCode:    codes.OK,
+			expectedScope: "videos:read videos:write",
+		},
+		{
+			name: "downscoping a wildcard grant",
+			setupMocks: func(j *MockJWTManager) {
+				validSubject(j, libjwt.MapClaims{"id": float64(dummyUser.Id), "username": dummyUser.Name, "jti": "subject", "scope": "videos:*"})
+				j.On("NewExchangedToken", mock.Anything).Return("exchanged", expiry, nil)
+			},
+			input:         &authpb.ExchangeTokenRequest{SubjectToken: "subject-token", Audience: "video-service", Scope: "videos:read"},
+			expectCode:    codes.OK,
+			expectedScope: "videos:read",
+		},
+		{
+			name: "wildcard request beyond a literal grant",
+			setupMocks: func(j *MockJWTManager) {
+				validSubject(j, scoped)
+			},
+			input:       &authpb.ExchangeTokenRequest{SubjectToken: "subject-token", Audience: "video-service", Scope: "videos:*"},
+			expectCode:  codes.InvalidArgument,
+			expectedMsg: constant.OAuthErrorInvalidScope,
+		},
+		{
+			name: "scope requested for a subject without one",
+			setupMocks: func(j *MockJWTManager) {
+				validSubject(j, subject)
+			},
+			input:       &authpb.ExchangeTokenRequest{SubjectToken: "subject-token", Audience: "video-service", Scope: "videos:read"},
+			expectCode:  codes.InvalidArgument,
+			expectedMsg: constant.OAuthErrorInvalidScope,
+		},
+		{
+			name: "scope escalation",
+			setupMocks: func(j *MockJWTManager) {
+				validSubject(j, scoped)
+			},
+			input:       &authpb.ExchangeTokenRequest{SubjectToken: "subject-token", Audience: "video-service", Scope: "admin"},
+			expectCode:  codes.InvalidArgument,
+			expectedMsg: constant.OAuthErrorInvalidScope,
+		},
+		{
+			name: "revoked subject token",
+			setupMocks: func(j *MockJWTManager) {
+				j.On("ParseToken", "subject-token").Return(subject, nil)
+				j.On("IsBlacklisted", mock.Anything, "subject").Return(true)
+			},
+			input:       &authpb.ExchangeTokenRequest{SubjectToken: "subject-token", Audience: "video-service"},
+			expectCode:  codes.InvalidArgument,
+			expectedMsg: constant.OAuthErrorInvalidRequest,
+		},
+		{
+			name: "invalid actor token",
+			setupMocks: func(j *MockJWTManager) {
+				validSubject(j, subject)
+				j.On("ParseToken", "actor-token").Return(nil, errors.New("bad signature"))
+			},
+			input:       &authpb.ExchangeTokenRequest{SubjectToken: "subject-token", ActorToken: "actor-token", Audience: "video-service"},
+			expectCode:  codes.InvalidArgument,
+			expectedMsg: constant.OAuthErrorInvalidRequest,
+		},
+		{
+			name: "audience not allowed",
+			setupMocks: func(j *MockJWTManager) {
+				validSubject(j, subject)
+				j.On("NewExchangedToken", mock.Anything).Return("", int64(0), jwt.ErrAudienceNotAllowed)
+			},
+			input:       &authpb.ExchangeTokenRequest{SubjectToken: "subject-token", Audience: "billing-service"},
+			expectCode:  codes.InvalidArgument,
+			expectedMsg: constant.OAuthErrorInvalidTarget,
+		},
+		{
+			name:        "missing audience",
+			input:       &authpb.ExchangeTokenRequest{SubjectToken: "subject-token"},
+			expectCode:  codes.InvalidArgument,
+			expectedMsg: constant.OAuthErrorInvalidRequest,
+		},
+		{
+			name:        "unsupported token type",
+			input:       &authpb.ExchangeTokenRequest{SubjectToken: "subject-token", SubjectTokenType: "urn:ietf:params:oauth:token-type:saml2", Audience: "video-service"},
+			expectCode:  codes.InvalidArgument,
+			expectedMsg: constant.OAuthErrorInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := new(MockJWTManager)
+			if tt.setupMocks != nil {
+				tt.setupMocks(j)
+			}
+
+			s := &server.AuthenticationServer{JWTManager: j}
+			resp, err := s.ExchangeToken(context.Background(), tt.input)
+
+			assert.Equal(t, tt.expectCode, status.Code(err))
+			if tt.expectCode == codes.OK {
+				assert.Equal(t, "exchanged", resp.Data.AccessToken)
+				assert.Equal(t, constant.TokenTypeAccessToken, resp.Data.IssuedTokenType)
+				assert.Equal(t, tt.expectedScope, resp.Data.Scope)
+				assert.InDelta(t, 300, resp.Data.ExpiresIn, 2)
+			} else {
+				assert.Equal(t, tt.expectedMsg, status.Convert(err).Message())
+			}
+
+			j.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"strconv"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ExtAuthzServer implements Envoy's external authorization API so tokens can
+// be checked once at the edge. It runs the same validation as VerifyToken and
+// passes the user on to upstream services as x-user-* headers.
+type ExtAuthzServer struct {
+	authv3.UnimplementedAuthorizationServer
+	AuthServer authpb.AuthenticationServiceServer
+}
+
+func (e *ExtAuthzServer) Check(ctx context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	// Envoy lowercases HTTP/2 style header names in the check request.
+	header := req.GetAttributes().GetRequest().GetHttp().GetHeaders()[constant.HeaderAuthorization]
+	if header == "" {
+		return deniedCheckResponse(codes.Unauthenticated), nil
+	}
+
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(constant.HeaderAuthorization, header))
+	res, err := e.AuthServer.VerifyToken(ctx, &authpb.VerifyTokenRequest{})
+	if err != nil {
+		code := status.Code(err)
+		if code != codes.Unauthenticated && code != codes.PermissionDenied {
+			// Left to Envoy's failure_mode_allow and status_on_error settings.
+			return nil, err
+		}
+		return deniedCheckResponse(code), nil
+	}
+
+	user := res.Data.User
+	response := &authv3.CheckResponse{
+		Status: &rpcstatus.Status{Code: int32(codes.OK)},
+		HttpResponse: &authv3.CheckResponse_OkResponse{
+			OkResponse: &authv3.OkHttpResponse{
+				// Overwrites any x-user-* headers sent by the client.
+				Headers: []*corev3.HeaderValueOption{
+					upstreamHeader(constant.HeaderUserID, strconv.Itoa(int(user.Id))),
+					upstreamHeader(constant.HeaderUserEmail, user.Email),
+				},
+			},
+		},
+	}
+	return response, nil
+}
+
+func deniedCheckResponse(code codes.Code) *authv3.CheckResponse {
+	httpStatus, message := typev3.StatusCode_Unauthorized, constant.MessageUnauthorized
+	if code == codes.PermissionDenied {
+		httpStatus, message = typev3.StatusCode_Forbidden, constant.MessageForbidden
+	}
+
+	headers := []*corev3.HeaderValueOption{upstreamHeader("content-type", "application/json")}
+	if code == codes.Unauthenticated {
+		headers = append(headers, upstreamHeader("www-authenticate", "Bearer"))
+	}
+
+	return &authv3.CheckResponse{
+		Status: &rpcstatus.Status{Code: int32(code), Message: message},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{
+			DeniedResponse: &authv3.DeniedHttpResponse{
+				Status:  &typev3.HttpStatus{Code: httpStatus},
+				Headers: headers,
+				Body:    `{"message":"` + message + `"}`,
+			},
+		},
+	}
+}
+
+func upstreamHeader(key string, value string) *corev3.HeaderValueOption {
+	return &corev3.HeaderValueOption{
+		Header:       &corev3.HeaderValue{Key: key, Value: value},
+		AppendAction: corev3.HeaderValueOption_OVERWRITE_IF_EXISTS_OR_ADD,
+	}
+}
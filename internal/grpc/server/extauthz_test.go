@@ -0,0 +1,119 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	libjwt "github.com/golang-jwt/jwt/v5"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server"
+	userpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func checkRequest(headers map[string]string) *authv3.CheckRequest {
+	return &authv3.CheckRequest{
+		Attributes: &authv3.AttributeContext{
+			Request: &authv3.AttributeContext_Request{
+				Http: &authv3.AttributeContext_HttpRequest{Method: "GET", Path: "/videos", Headers: headers},
+			},
+		},
+	}
+}
+
+func headerMap(headers []*corev3.HeaderValueOption) map[string]string {
+	m := map[string]string{}
+	for _, h := range headers {
+		m[h.Header.Key] = h.Header.Value
+	}
+	return m
+}
+
+func TestExtAuthzServer_Check(t *testing.T) {
+	bearer := map[string]string{"authorization": "Bearer validtoken"}
+
+	tests := []struct {
+		name            string
+		setupMocks      func(u *MockUserClient, j *MockJWTManager)
+		headers         map[string]string
+		expectErr       bool
+		expectedCode    codes.Code
+		expectedHTTP    typev3.StatusCode
+		expectedHeaders map[string]string
+	}{
+		{
+			name: "valid token",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
+				j.On("ParseToken", "validtoken").Return(libjwt.MapClaims{"id": float64(dummyUser.Id), "jti": "1"}, nil)
+				j.On("IsBlacklisted", mock.Anything, "1").Return(false)
+				u.On("FindById", mock.Anything, mock.Anything).
+					Return(&userpb.FindByIdResponse{Data: &userpb.FindByIdResponseData{User: dummyUser}}, nil)
+			},
+			headers:         bearer,
+			expectedCode:    codes.OK,
+			expectedHeaders: map[string]string{constant.HeaderUserID: "1", constant.HeaderUserEmail: dummyUser.Email},
+		},
+		{
+			name: "revoked token",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
+				j.On("ParseToken", "validtoken").Return(libjwt.MapClaims{"id": float64(dummyUser.Id), "jti": "1"}, nil)
+				j.On("IsBlacklisted", mock.Anything, "1").Return(true)
+			},
+			headers:         bearer,
+			expectedCode:    codes.Unauthenticated,
+			expectedHTTP:    typev3.StatusCode_Unauthorized,
+			expectedHeaders: map[string]string{"content-type": "application/json", "www-authenticate": "Bearer"},
+		},
+		{
+			name: "invalid token",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
+				j.On("ParseToken", "validtoken").Return(nil, errors.New("bad signature"))
+			},
+			headers:         bearer,
+			expectedCode:    codes.Unauthenticated,
+			expectedHTTP:    typev3.StatusCode_Unauthorized,
+			expectedHeaders: map[string]string{"content-type": "application/json", "www-authenticate": "Bearer"},
+		},
+		{
+			name:            "missing authorization header",
+			headers:         map[string]string{},
+			expectedCode:    codes.Unauthenticated,
+			expectedHTTP:    typev3.StatusCode_Unauthorized,
+			expectedHeaders: map[string]string{"content-type": "application/json", "www-authenticate": "Bearer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := new(MockUserClient)
+			j := new(MockJWTManager)
+			if tt.setupMocks != nil {
+				tt.setupMocks(u, j)
+			}
+
+			e := &server.ExtAuthzServer{AuthServer: &server.AuthenticationServer{UserClient: u, JWTManager: j}}
+			resp, err := e.Check(context.Background(), checkRequest(tt.headers))
+			require.NoError(t, err)
+
+			assert.Equal(t, int32(tt.expectedCode), resp.Status.Code)
+			if tt.expectedCode == codes.OK {
+				assert.Equal(t, tt.expectedHeaders, headerMap(resp.GetOkResponse().Headers))
+			} else {
+				denied := resp.GetDeniedResponse()
+				assert.Equal(t, tt.expectedHTTP, denied.Status.Code)
+				assert.Equal(t, tt.expectedHeaders, headerMap(denied.Headers))
+				assert.JSONEq(t, `{"message":"`+constant.MessageUnauthorized+`"}`, denied.Body)
+			}
+
+			u.AssertExpectations(t)
+			j.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/audit"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/federation"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	userpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (a *AuthenticationServer) StartFederatedLogin(ctx context.Context, data *authpb.StartFederatedLoginRequest) (response *authpb.StartFederatedLoginResponse, err error) {
+	event := audit.NewEvent(ctx, audit.EventStartFederatedLogin)
+	defer func() { a.recordAudit(ctx, event, err) }()
+
+	url, state, err := a.FederationManager.AuthCodeURL(ctx, data.Provider)
+	if errors.Is(err, federation.ErrUnknownProvider) {
+		return nil, status.Error(codes.InvalidArgument, constant.MessageBadRequest)
+	}
+	if err != nil {
+		logger.ErrorCtx(ctx, "Federated login start failed for %q: %v", data.Provider, err)
+		return nil, status.Error(codes.Unavailable, constant.MessageInternalServerError)
+	}
+
+	response = &authpb.StartFederatedLoginResponse{
+		Message: constant.MessageOK,
+		Data: &authpb.StartFederatedLoginResponseData{
+			AuthorizationUrl: url,
+			State:            state,
+		},
+	}
+	return response, nil
+}
+
+func (a *AuthenticationServer) CompleteFederatedLogin(ctx context.Context, data *authpb.CompleteFederatedLoginRequest) (response *authpb.CompleteFederatedLoginResponse, err error) {
+	event := audit.NewEvent(ctx, audit.EventCompleteFederatedLogin)
+	defer func() { a.recordAudit(ctx, event, err) }()
+
+	identity, err := a.FederationManager.Exchange(ctx, data.State, data.Code)
+	if err != nil {
+		logger.WarnCtx(ctx, "Federated login exchange failed: %v", err)
+		return nil, status.Error(codes.Unauthenticated, constant.MessageUnauthorized)
+	}
+
+	event.EmailHash = audit.HashEmail(identity.Email)
+
+	user, err := a.findOrCreateFederatedUser(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+	event.UserID = int(user.Id)
+
+	token, err := a.signToken(ctx, uint(user.Id), user.Name, "", a.ProfileFallback.tokenProfile(user))
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, constant.MessageUnauthorized)
+	}
+
+	response = &authpb.CompleteFederatedLoginResponse{
+		Message: constant.MessageOK,
+		Data: &authpb.CompleteFederatedLoginResponseData{
+			Token: token,
+			User: &authpb.User{
+				Id:        user.Id,
+				Name:      user.Name,
+				Email:     user.Email,
+				Image:     user.Image,
+				CreatedAt: user.CreatedAt,
+				UpdatedAt: user.UpdatedAt,
+			},
+		},
+	}
+	return response, nil
+}
+
+// findOrCreateFederatedUser resolves an upstream identity to a local user:
+// an existing link wins, otherwise a verified email is registered with the
+// user service and the identity is linked to it. An account that already has
+// the email is only linked for providers trusted to vouch for emails, as
+// anyone able to claim that address upstream would otherwise take it over.
+// Links are kept by the user service, next to the accounts they point at.
+func (a *AuthenticationServer) findOrCreateFederatedUser(ctx context.Context, identity *federation.Identity) (*userpb.User, error) {
+	linked, err := a.users().FindByFederatedIdentity(ctx, &userpb.FindByFederatedIdentityRequest{
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+	})
+	switch status.Code(err) {
+	case codes.OK:
+		return linked.Data.User, nil
+	case codes.NotFound:
+	default:
+		return nil, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, status.Error(codes.PermissionDenied, constant.MessageForbidden)
+	}
+
+	var user *userpb.User
+	res, err := a.users().FindByEmail(ctx, &userpb.FindByEmailRequest{Email: identity.Email})
+	switch status.Code(err) {
+	case codes.OK:
+		if !identity.TrustEmail {
+			logger.WarnCtx(ctx, "Refusing to link %q identity to existing user %d by email", identity.Provider, res.Data.User.Id)
+			return nil, status.Error(codes.PermissionDenied, constant.MessageForbidden)
+		}
+		user = res.Data.User
+	case codes.NotFound:
+		password, err := randomPassword()
+		if err != nil {
+			return nil, status.Error(codes.Internal, constant.MessageInternalServerError)
+		}
+		storeRes, err := a.users().Store(ctx, &userpb.StoreRequest{
+			Name:     identity.Name,
+			Email:    identity.Email,
+			Password: password,
+		})
+		if err != nil {
+			return nil, err
+		}
+		user = storeRes.Data.User
+	default:
+		return nil, err
+	}
+
+	_, err = a.users().LinkFederatedIdentity(ctx, &userpb.LinkFederatedIdentityRequest{
+		UserId:   user.Id,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+	})
+	if err != nil {
+		logger.ErrorCtx(ctx, "Failed to link federated identity to user %d: %v", user.Id, err)
+		return nil, status.Error(codes.Internal, constant.MessageInternalServerError)
+	}
+
+	return user, nil
+}
+
+// randomPassword satisfies the user service for accounts that only ever sign
+// in through an identity provider; nobody knows it, so password login is off.
+func randomPassword() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,219 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/federation"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	userpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthenticationServer_StartFederatedLogin(t *testing.T) {
+	tests := []struct {
+		name       string
+		setupMocks func(f *MockFederationManager)
+		provider   string
+		expectCode codes.Code
+	}{
+		{
+			name: "success",
+			setupMocks: func(f *MockFederationManager) {
+				f.On("AuthCodeURL", mock.Anything, "google").Return("https://idp.example.com/authorize?state=s1", "s1", nil)
+			},
+			provider:   "google",
+			expectCode: codes.OK,
+		},
+		{
+			name: "unknown provider",
+			setupMocks: func(f *MockFederationManager) {
+				f.On("AuthCodeURL", mock.Anything, "myspace").Return("", "", federation.ErrUnknownProvider)
+			},
+			provider:   "myspace",
+			expectCode: codes.InvalidArgument,
+		},
+		{
+			name: "provider unreachable",
+			setupMocks: func(f *MockFederationManager) {
+				f.On("AuthCodeURL", mock.Anything, "google").Return("", "", errors.New("discovery failed"))
+			},
+			provider:   "google",
+			expectCode: codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := new(MockFederationManager)
+			tt.setupMocks(f)
+
+			s := &server.AuthenticationServer{FederationManager: f}
+			resp, err := s.StartFederatedLogin(context.Background(), &authpb.StartFederatedLoginRequest{Provider: tt.provider})
+
+			assert.Equal(t, tt.expectCode, status.Code(err))
+			if tt.expectCode == codes.OK {
+				assert.Equal(t, "https://idp.example.com/authorize?state=s1", resp.Data.AuthorizationUrl)
+				assert.Equal(t, "s1", resp.Data.State)
+			}
+			f.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthenticationServer_CompleteFederatedLogin(t *testing.T) {
+	identity := &federation.Identity{
+		Provider:      "google",
+		Subject:       "upstream-42",
+		Email:         dummyUser.Email,
+		EmailVerified: true,
+		Name:          dummyUser.Name,
+	}
+	trusted := &federation.Identity{
+		Provider:      "google",
+		Subject:       "upstream-42",
+		Email:         dummyUser.Email,
+		EmailVerified: true,
+		Name:          dummyUser.Name,
+		TrustEmail:    true,
+	}
+	unverified := &federation.Identity{Provider: "google", Subject: "upstream-43", Email: dummyUser.Email}
+	input := &authpb.CompleteFederatedLoginRequest{State: "s1", Code: "code1"}
+
+	tests := []struct {
+		name          string
+		setupMocks    func(u *MockUserClient, j *MockJWTManager, f *MockFederationManager)
+		expectCode    codes.Code
+		expectedToken string
+	}{
+		{
+			name: "already linked identity",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager, f *MockFederationManager) {
+				f.On("Exchange", mock.Anything, "s1", "code1").Return(identity, nil)
+				u.On("FindByFederatedIdentity", mock.Anything, &userpb.FindByFederatedIdentityRequest{Provider: "google", Subject: "upstream-42"}).
+					Return(&userpb.FindByFederatedIdentityResponse{Data: &userpb.FindByFederatedIdentityResponseData{User: dummyUser}}, nil)
+				j.On("NewToken", uint(dummyUser.Id), dummyUser.Name).Return("token123", nil)
+			},
+			expectCode:    codes.OK,
+			expectedToken: "token123",
+		},
+		{
+			name: "links existing user by email from trusted provider",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager, f *MockFederationManager) {
+				f.On("Exchange", mock.Anything, "s1", "code1").Return(trusted, nil)
+				u.On("FindByFederatedIdentity", mock.Anything, &userpb.FindByFederatedIdentityRequest{Provider: "google", Subject: "upstream-42"}).
+					Return(nil, status.Error(codes.NotFound, "not linked"))
+				u.On("FindByEmail", mock.Anything, &userpb.FindByEmailRequest{Email: dummyUser.Email}).
+					Return(&userpb.FindByEmailResponse{Data: &userpb.FindByEmailResponseData{User: dummyUser}}, nil)
+				u.On("LinkFederatedIdentity", mock.Anything, &userpb.LinkFederatedIdentityRequest{UserId: dummyUser.Id, Provider: "google", Subject: "upstream-42"}).
+					Return(&userpb.LinkFederatedIdentityResponse{}, nil)
+				j.On("NewToken", uint(dummyUser.Id), dummyUser.Name).Return("token123", nil)
+			},
+			expectCode:    codes.OK,
+			expectedToken: "token123",
+		},
+		{
+			name: "existing user is not linked by email from untrusted provider",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager, f *MockFederationManager) {
+				f.On("Exchange", mock.Anything, "s1", "code1").Return(identity, nil)
+				u.On("FindByFederatedIdentity", mock.Anything, &userpb.FindByFederatedIdentityRequest{Provider: "google", Subject: "upstream-42"}).
+					Return(nil, status.Error(codes.NotFound, "not linked"))
+				u.On("FindByEmail", mock.Anything, &userpb.FindByEmailRequest{Email: dummyUser.Email}).
+					Return(&userpb.FindByEmailResponse{Data: &userpb.FindByEmailResponseData{User: dummyUser}}, nil)
+			},
+			expectCode: codes.PermissionDenied,
+		},
+		{
+			name: "creates and links new user",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager, f *MockFederationManager) {
+				f.On("Exchange", mock.Anything, "s1", "code1").Return(identity, nil)
+				u.On("FindByFederatedIdentity", mock.Anything, &userpb.FindByFederatedIdentityRequest{Provider: "google", Subject: "upstream-42"}).
+					Return(nil, status.Error(codes.NotFound, "not linked"))
+				u.On("FindByEmail", mock.Anything, mock.Anything).Return(nil, status.Error(codes.NotFound, "not found"))
+				u.On("Store", mock.Anything, mock.MatchedBy(func(in *userpb.StoreRequest) bool {
+					return in.Email == dummyUser.Email && in.Name == dummyUser.Name && len(in.Password) >= 32
+				})).Return(&userpb.StoreResponse{Data: &userpb.StoreResponseData{User: dummyUser}}, nil)
+				u.On("LinkFederatedIdentity", mock.Anything, &userpb.LinkFederatedIdentityRequest{UserId: dummyUser.Id, Provider: "google", Subject: "upstream-42"}).
+					Return(&userpb.LinkFederatedIdentityResponse{}, nil)
+				j.On("NewToken", uint(dummyUser.Id), dummyUser.Name).Return("token123", nil)
+			},
+			expectCode:    codes.OK,
+			expectedToken: "token123",
+		},
+		{
+			name: "unverified email is not linked",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager, f *MockFederationManager) {
+				f.On("Exchange", mock.Anything, "s1", "code1").Return(unverified, nil)
+				u.On("FindByFederatedIdentity", mock.Anything, &userpb.FindByFederatedIdentityRequest{Provider: "google", Subject: "upstream-43"}).
+					Return(nil, status.Error(codes.NotFound, "not linked"))
+			},
+			expectCode: codes.PermissionDenied,
+		},
+		{
+			name: "exchange fails",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager, f *MockFederationManager) {
+				f.On("Exchange", mock.Anything, "s1", "code1").Return(nil, federation.ErrInvalidState)
+			},
+			expectCode: codes.Unauthenticated,
+		},
+		{
+			name: "link lookup fails",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager, f *MockFederationManager) {
+				f.On("Exchange", mock.Anything, "s1", "code1").Return(identity, nil)
+				u.On("FindByFederatedIdentity", mock.Anything, mock.Anything).Return(nil, status.Error(codes.Unavailable, "down"))
+			},
+			expectCode: codes.Unavailable,
+		},
+		{
+			name: "linking fails",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager, f *MockFederationManager) {
+				f.On("Exchange", mock.Anything, "s1", "code1").Return(trusted, nil)
+				u.On("FindByFederatedIdentity", mock.Anything, &userpb.FindByFederatedIdentityRequest{Provider: "google", Subject: "upstream-42"}).
+					Return(nil, status.Error(codes.NotFound, "not linked"))
+				u.On("FindByEmail", mock.Anything, mock.Anything).
+					Return(&userpb.FindByEmailResponse{Data: &userpb.FindByEmailResponseData{User: dummyUser}}, nil)
+				u.On("LinkFederatedIdentity", mock.Anything, mock.Anything).Return(nil, status.Error(codes.Unavailable, "down"))
+			},
+			expectCode: codes.Internal,
+		},
+		{
+			name: "user service unavailable",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager, f *MockFederationManager) {
+				f.On("Exchange", mock.Anything, "s1", "code1").Return(identity, nil)
+				u.On("FindByFederatedIdentity", mock.Anything, &userpb.FindByFederatedIdentityRequest{Provider: "google", Subject: "upstream-42"}).
+					Return(nil, status.Error(codes.NotFound, "not linked"))
+				u.On("FindByEmail", mock.Anything, mock.Anything).Return(nil, status.Error(codes.Unavailable, "down"))
+			},
+			expectCode: codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := new(MockUserClient)
+			j := new(MockJWTManager)
+			f := new(MockFederationManager)
+			tt.setupMocks(u, j, f)
+
+			s := &server.AuthenticationServer{UserClient: u, JWTManager: j, FederationManager: f}
+			resp, err := s.CompleteFederatedLogin(context.Background(), input)
+
+			assert.Equal(t, tt.expectCode, status.Code(err))
+			if tt.expectCode == codes.OK {
+				assert.Equal(t, constant.MessageOK, resp.Message)
+				assert.Equal(t, tt.expectedToken, resp.Data.Token)
+				assert.Equal(t, dummyUser.Email, resp.Data.User.Email)
+			}
+
+			u.AssertExpectations(t)
+			j.AssertExpectations(t)
+			f.AssertExpectations(t)
+		})
+	}
+}
@@ -2,41 +2,307 @@ package server
 
 import (
 	"context"
+	"slices"
+	"sync"
+	"time"
 
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
 	user "github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/client/user"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
-	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/prometheus"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc/codes"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 )
 
+// Services reported by the health server. The empty name is the server as a
+// whole, as in the gRPC health checking protocol.
+const (
+	HealthServiceOverall        = ""
+	HealthServiceRedis          = "redis"
+	HealthServiceUser           = "user-service"
+	HealthServiceAuthentication = "authentication.AuthenticationService"
+)
+
+var (
+	healthServices     = []string{HealthServiceOverall, HealthServiceRedis, HealthServiceUser, HealthServiceAuthentication}
+	healthDependencies = []string{HealthServiceRedis, HealthServiceUser}
+)
+
+// HealthServer answers health checks from the statuses found by the last
+// Refresh, so probes never wait on a dependency. Run keeps them current, and
+// every change is pushed to the Watch streams of that service.
 type HealthServer struct {
 	healthpb.HealthServer
 	UserClient  user.UserService
 	RedisClient redis.RedisService
+	// Timeout bounds each dependency check, none when zero.
+	Timeout time.Duration
+	// NonCritical names the dependencies the service keeps serving without,
+	// in a degraded state. Every other dependency is critical: when it is
+	// down, so is the service.
+	NonCritical []string
+
+	mu          sync.RWMutex
+	statuses    map[string]healthpb.HealthCheckResponse_ServingStatus
+	watchers    map[string]map[chan healthpb.HealthCheckResponse_ServingStatus]struct{}
+	shutdown    bool
+	interval    time.Duration
+	refreshedAt time.Time
 }
 
 func (h *HealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
-	status := healthpb.HealthCheckResponse_SERVING
-	if err := h.RedisClient.Health(ctx); err != nil {
-		status = healthpb.HealthCheckResponse_NOT_SERVING
+	servingStatus, ok := h.Status(req.GetService())
+	if !ok {
+		return nil, status.Error(codes.NotFound, constant.MessageNotFound)
 	}
 
-	if err := h.UserClient.Health(ctx); err != nil {
-		status = healthpb.HealthCheckResponse_NOT_SERVING
+	return &healthpb.HealthCheckResponse{Status: servingStatus}, nil
+}
+
+// Watch sends the status of the requested service right away and then again
+// whenever it changes, until the client goes away or the server shuts down.
+// As the health checking protocol asks, an unknown service is reported as
+// SERVICE_UNKNOWN instead of failing the call.
+func (h *HealthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	service := req.GetService()
+
+	updates := make(chan healthpb.HealthCheckResponse_ServingStatus, 1)
+	current, watching := h.watch(service, updates)
+	if watching {
+		defer h.unwatch(service, updates)
 	}
 
-	logger.Info("Overall health status: %q", status)
+	if err := stream.Send(&healthpb.HealthCheckResponse{Status: current}); err != nil {
+		return err
+	}
+	if !watching {
+		return status.Error(codes.Unavailable, constant.MessageServiceUnavailable)
+	}
 
-	response := &healthpb.HealthCheckResponse{
-		Status: status,
+	for {
+		select {
+		case servingStatus, ok := <-updates:
+			if !ok {
+				return status.Error(codes.Unavailable, constant.MessageServiceUnavailable)
+			}
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: servingStatus}); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
 	}
+}
+
+// Status returns the last known status of service, UNKNOWN until the first
+// Refresh. It reports false for services this server doesn't know.
+func (h *HealthServer) Status(service string) (healthpb.HealthCheckResponse_ServingStatus, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
 
-	if status == healthpb.HealthCheckResponse_NOT_SERVING {
-		prometheus.ServiceHealth.Set(0)
-		return response, nil
+	return h.statusLocked(service)
+}
+
+func (h *HealthServer) statusLocked(service string) (healthpb.HealthCheckResponse_ServingStatus, bool) {
+	if !slices.Contains(healthServices, service) {
+		return healthpb.HealthCheckResponse_SERVICE_UNKNOWN, false
 	}
 
-	prometheus.ServiceHealth.Set(1)
-	return response, nil
+	servingStatus, ok := h.statuses[service]
+	if !ok {
+		return healthpb.HealthCheckResponse_UNKNOWN, true
+	}
+	return servingStatus, true
+}
+
+// watch subscribes updates to the changes of service and returns its current
+// status. Both happen under one lock, so no change falls in between. Nothing
+// is subscribed once the server is shutting down.
+func (h *HealthServer) watch(service string, updates chan healthpb.HealthCheckResponse_ServingStatus) (healthpb.HealthCheckResponse_ServingStatus, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	current, _ := h.statusLocked(service)
+	if h.shutdown {
+		return current, false
+	}
+
+	if h.watchers == nil {
+		h.watchers = map[string]map[chan healthpb.HealthCheckResponse_ServingStatus]struct{}{}
+	}
+	if h.watchers[service] == nil {
+		h.watchers[service] = map[chan healthpb.HealthCheckResponse_ServingStatus]struct{}{}
+	}
+	h.watchers[service][updates] = struct{}{}
+	return current, true
+}
+
+func (h *HealthServer) unwatch(service string, updates chan healthpb.HealthCheckResponse_ServingStatus) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.watchers[service], updates)
+	if len(h.watchers[service]) == 0 {
+		delete(h.watchers, service)
+	}
+}
+
+// Shutdown reports every service NOT_SERVING and ends all Watch streams, so
+// clients move to another instance and GracefulStop isn't held up by streams
+// that would otherwise never finish. Later refreshes are ignored.
+func (h *HealthServer) Shutdown() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, service := range healthServices {
+		h.setStatusLocked(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	for _, watchers := range h.watchers {
+		for updates := range watchers {
+			close(updates)
+		}
+	}
+	h.watchers = nil
+	h.shutdown = true
+}
+
+// Run refreshes the statuses right away and then every interval until ctx is
+// done.
+func (h *HealthServer) Run(ctx context.Context, interval time.Duration) {
+	h.mu.Lock()
+	h.interval = interval
+	h.mu.Unlock()
+
+	h.Refresh(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.Refresh(ctx)
+		}
+	}
+}
+
+// Refresh checks every dependency concurrently and updates the statuses and
+// health gauges. The authentication service serves while all of its critical
+// dependencies do.
+func (h *HealthServer) Refresh(ctx context.Context) {
+	checks := map[string]func(context.Context) error{
+		HealthServiceRedis: h.RedisClient.Health,
+		HealthServiceUser:  h.UserClient.Health,
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = make(map[string]healthpb.HealthCheckResponse_ServingStatus, len(checks))
+	)
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			checkCtx, cancel := h.checkContext(ctx)
+			defer cancel()
+
+			servingStatus := healthpb.HealthCheckResponse_SERVING
+			if err := check(checkCtx); err != nil {
+				servingStatus = healthpb.HealthCheckResponse_NOT_SERVING
+			}
+
+			mu.Lock()
+			results[name] = servingStatus
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	overall := healthpb.HealthCheckResponse_SERVING
+	for name, servingStatus := range results {
+		metrics.DependencyHealth.Record(ctx, healthValue(servingStatus), metric.WithAttributes(metrics.DependencyKey.String(name)))
+		if servingStatus != healthpb.HealthCheckResponse_SERVING && h.critical(name) {
+			overall = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+	results[HealthServiceAuthentication] = overall
+	results[HealthServiceOverall] = overall
+	metrics.ServiceHealth.Record(ctx, healthValue(overall))
+
+	h.setStatuses(results)
+}
+
+func (h *HealthServer) critical(dependency string) bool {
+	return !slices.Contains(h.NonCritical, dependency)
+}
+
+func (h *HealthServer) checkContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if h.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, h.Timeout)
+}
+
+// setStatuses stores the new statuses, logging only the ones that changed so
+// a steady state stays quiet.
+func (h *HealthServer) setStatuses(statuses map[string]healthpb.HealthCheckResponse_ServingStatus) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.shutdown {
+		return
+	}
+	for service, servingStatus := range statuses {
+		h.setStatusLocked(service, servingStatus)
+	}
+	h.refreshedAt = time.Now()
+}
+
+// setStatusLocked records a change and passes it on to the watchers of
+// service. A watcher that hasn't read the previous change only gets the
+// latest, so a slow client never blocks the checker.
+func (h *HealthServer) setStatusLocked(service string, servingStatus healthpb.HealthCheckResponse_ServingStatus) {
+	if previous, ok := h.statuses[service]; ok && previous == servingStatus {
+		return
+	}
+
+	if servingStatus == healthpb.HealthCheckResponse_SERVING {
+		logger.Info("Health status of %q is %s", healthServiceName(service), servingStatus)
+	} else {
+		logger.Warn("Health status of %q is %s", healthServiceName(service), servingStatus)
+	}
+
+	if h.statuses == nil {
+		h.statuses = make(map[string]healthpb.HealthCheckResponse_ServingStatus, len(healthServices))
+	}
+	h.statuses[service] = servingStatus
+
+	for updates := range h.watchers[service] {
+		select {
+		case <-updates:
+		default:
+		}
+		updates <- servingStatus
+	}
+}
+
+func healthServiceName(service string) string {
+	if service == HealthServiceOverall {
+		return "overall"
+	}
+	return service
+}
+
+func healthValue(servingStatus healthpb.HealthCheckResponse_ServingStatus) int64 {
+	if servingStatus == healthpb.HealthCheckResponse_SERVING {
+		return 1
+	}
+	return 0
 }
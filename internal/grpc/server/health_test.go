@@ -2,87 +2,287 @@ package server_test
 
 import (
 	"context"
+	"net"
 	"testing"
+	"time"
 
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server"
-	myprom "github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/prometheus"
-	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics/metricstest"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis/redistest"
+)
+
+const (
+	serving    = healthpb.HealthCheckResponse_SERVING
+	notServing = healthpb.HealthCheckResponse_NOT_SERVING
 )
 
+func checkHealth(t *testing.T, hs *server.HealthServer, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := hs.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return resp.Status
+}
+
 func TestHealthServer_Check(t *testing.T) {
 	tests := []struct {
-		name           string
-		redisErr       error
-		userErr        error
-		expectedStatus healthpb.HealthCheckResponse_ServingStatus
-		expectedMetric float64
+		name          string
+		redisErr      error
+		userErr       error
+		expectedRedis healthpb.HealthCheckResponse_ServingStatus
+		expectedUser  healthpb.HealthCheckResponse_ServingStatus
+		expectedAll   healthpb.HealthCheckResponse_ServingStatus
 	}{
 		{
-			name:           "all healthy",
-			redisErr:       nil,
-			userErr:        nil,
-			expectedStatus: healthpb.HealthCheckResponse_SERVING,
-			expectedMetric: 1,
+			name:          "all healthy",
+			expectedRedis: serving,
+			expectedUser:  serving,
+			expectedAll:   serving,
 		},
 		{
-			name:           "redis down",
-			redisErr:       assert.AnError,
-			userErr:        nil,
-			expectedStatus: healthpb.HealthCheckResponse_NOT_SERVING,
-			expectedMetric: 0,
+			name:          "redis down",
+			redisErr:      assert.AnError,
+			expectedRedis: notServing,
+			expectedUser:  serving,
+			expectedAll:   notServing,
 		},
 		{
-			name:           "user down",
-			redisErr:       nil,
-			userErr:        assert.AnError,
-			expectedStatus: healthpb.HealthCheckResponse_NOT_SERVING,
-			expectedMetric: 0,
+			name:          "user down",
+			userErr:       assert.AnError,
+			expectedRedis: serving,
+			expectedUser:  notServing,
+			expectedAll:   notServing,
 		},
 		{
-			name:           "both down",
-			redisErr:       assert.AnError,
-			userErr:        assert.AnError,
-			expectedStatus: healthpb.HealthCheckResponse_NOT_SERVING,
-			expectedMetric: 0,
+			name:          "both down",
+			redisErr:      assert.AnError,
+			userErr:       assert.AnError,
+			expectedRedis: notServing,
+			expectedUser:  notServing,
+			expectedAll:   notServing,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			redisMock := new(MockRedisClient)
-			redisMock.On("Health", mock.Anything).Return(tt.redisErr)
+			redisMock := new(redistest.Client)
+			redisMock.On("Health", mock.Anything).Return(tt.redisErr).Once()
 
 			userMock := new(MockUserClient)
-			userMock.On("Health", mock.Anything).Return(tt.userErr)
+			userMock.On("Health", mock.Anything).Return(tt.userErr).Once()
 
-			reg := prometheus.NewRegistry()
-			myprom.RegisterMetrics(reg)
+			reader := metricstest.New(t)
 
 			hs := &server.HealthServer{
 				RedisClient: redisMock,
 				UserClient:  userMock,
 			}
+			hs.Refresh(context.Background())
 
+			assert.Equal(t, tt.expectedRedis, checkHealth(t, hs, server.HealthServiceRedis))
+			assert.Equal(t, tt.expectedUser, checkHealth(t, hs, server.HealthServiceUser))
+			assert.Equal(t, tt.expectedAll, checkHealth(t, hs, server.HealthServiceAuthentication))
+			assert.Equal(t, tt.expectedAll, checkHealth(t, hs, server.HealthServiceOverall))
+
+			// Probes are answered from the last refresh, the mocks fail if
+			// a dependency is checked again.
 			resp, err := hs.Check(context.Background(), nil)
-			assert.NoError(t, err)
-			assert.Equal(t, tt.expectedStatus, resp.Status)
-
-			metrics, err := reg.Gather()
-			assert.NoError(t, err)
-
-			found := false
-			for _, m := range metrics {
-				if m.GetName() == "service_health_status" {
-					value := m.GetMetric()[0].GetGauge().GetValue()
-					assert.Equal(t, tt.expectedMetric, value)
-					found = true
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedAll, resp.Status)
+
+			healthValue := func(s healthpb.HealthCheckResponse_ServingStatus) float64 {
+				if s == serving {
+					return 1
 				}
+				return 0
 			}
-			assert.True(t, found, "service_health_status metric should be registered")
+			assert.Equal(t, healthValue(tt.expectedAll), reader.Value(t, "service_health_status"))
+			assert.Equal(t, healthValue(tt.expectedRedis), reader.Value(t, "dependency_health_status", metrics.DependencyKey.String("redis")))
+			assert.Equal(t, healthValue(tt.expectedUser), reader.Value(t, "dependency_health_status", metrics.DependencyKey.String("user-service")))
+			redisMock.AssertExpectations(t)
+			userMock.AssertExpectations(t)
 		})
 	}
 }
+
+func TestHealthServer_Check_BeforeRefresh(t *testing.T) {
+	hs := &server.HealthServer{}
+
+	assert.Equal(t, healthpb.HealthCheckResponse_UNKNOWN, checkHealth(t, hs, server.HealthServiceOverall))
+	assert.Equal(t, healthpb.HealthCheckResponse_UNKNOWN, checkHealth(t, hs, server.HealthServiceRedis))
+}
+
+func TestHealthServer_Check_UnknownService(t *testing.T) {
+	hs := &server.HealthServer{}
+
+	_, err := hs.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "billing"})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestHealthServer_Refresh_Timeout(t *testing.T) {
+	metricstest.New(t)
+
+	redisMock := new(redistest.Client)
+	redisMock.On("Health", mock.Anything).Return(context.DeadlineExceeded).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	})
+	userMock := new(MockUserClient)
+	userMock.On("Health", mock.Anything).Return(nil)
+
+	hs := &server.HealthServer{RedisClient: redisMock, UserClient: userMock, Timeout: 10 * time.Millisecond}
+	hs.Refresh(context.Background())
+
+	assert.Equal(t, notServing, checkHealth(t, hs, server.HealthServiceRedis))
+	assert.Equal(t, serving, checkHealth(t, hs, server.HealthServiceUser))
+}
+
+func TestHealthServer_Run(t *testing.T) {
+	metricstest.New(t)
+
+	redisMock := new(redistest.Client)
+	redisMock.On("Health", mock.Anything).Return(nil).Once()
+	redisMock.On("Health", mock.Anything).Return(assert.AnError)
+	userMock := new(MockUserClient)
+	userMock.On("Health", mock.Anything).Return(nil)
+
+	hs := &server.HealthServer{RedisClient: redisMock, UserClient: userMock}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		hs.Run(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	// The first refresh finds redis healthy, a later one sees it go down.
+	assert.Eventually(t, func() bool {
+		s, _ := hs.Status(server.HealthServiceRedis)
+		return s == notServing
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after the context was canceled")
+	}
+}
+
+// serveHealth serves hs on a local port and returns a client for it. The
+// server is stopped gracefully on cleanup, after hs.Shutdown has ended any
+// open Watch streams.
+func serveHealth(t *testing.T, hs *server.HealthServer) healthpb.HealthClient {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := server.NewServer(&server.AuthenticationServer{}, hs)
+	go func() { _ = server.ServeListener(lis, s) }()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		hs.Shutdown()
+		s.GracefulStop()
+		conn.Close()
+	})
+
+	return healthpb.NewHealthClient(conn)
+}
+
+func recvStatus(t *testing.T, stream healthpb.Health_WatchClient) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	return resp.Status
+}
+
+func TestHealthServer_Watch(t *testing.T) {
+	metricstest.New(t)
+
+	redisMock := new(redistest.Client)
+	redisMock.On("Health", mock.Anything).Return(nil).Twice()
+	redisMock.On("Health", mock.Anything).Return(assert.AnError).Once()
+	userMock := new(MockUserClient)
+	userMock.On("Health", mock.Anything).Return(nil)
+
+	hs := &server.HealthServer{RedisClient: redisMock, UserClient: userMock}
+	client := serveHealth(t, hs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	redisStream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: server.HealthServiceRedis})
+	require.NoError(t, err)
+	overallStream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	// The current status comes first, nothing has been checked yet.
+	assert.Equal(t, healthpb.HealthCheckResponse_UNKNOWN, recvStatus(t, redisStream))
+	assert.Equal(t, healthpb.HealthCheckResponse_UNKNOWN, recvStatus(t, overallStream))
+
+	hs.Refresh(ctx)
+	assert.Equal(t, serving, recvStatus(t, redisStream))
+	assert.Equal(t, serving, recvStatus(t, overallStream))
+
+	// An unchanged status isn't sent again: the next message is the outage.
+	hs.Refresh(ctx)
+	hs.Refresh(ctx)
+	assert.Equal(t, notServing, recvStatus(t, redisStream))
+	assert.Equal(t, notServing, recvStatus(t, overallStream))
+}
+
+func TestHealthServer_Watch_UnknownService(t *testing.T) {
+	client := serveHealth(t, &server.HealthServer{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "billing"})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVICE_UNKNOWN, recvStatus(t, stream))
+
+	// The call stays open instead of failing.
+	_, err = stream.Recv()
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestHealthServer_Watch_Shutdown(t *testing.T) {
+	metricstest.New(t)
+
+	redisMock := new(redistest.Client)
+	redisMock.On("Health", mock.Anything).Return(nil)
+	userMock := new(MockUserClient)
+	userMock.On("Health", mock.Anything).Return(nil)
+
+	hs := &server.HealthServer{RedisClient: redisMock, UserClient: userMock}
+	hs.Refresh(context.Background())
+	client := serveHealth(t, hs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: server.HealthServiceAuthentication})
+	require.NoError(t, err)
+	assert.Equal(t, serving, recvStatus(t, stream))
+
+	hs.Shutdown()
+	assert.Equal(t, notServing, recvStatus(t, stream))
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// Refreshes after shutdown don't bring the service back.
+	hs.Refresh(context.Background())
+	assert.Equal(t, notServing, checkHealth(t, hs, server.HealthServiceAuthentication))
+}
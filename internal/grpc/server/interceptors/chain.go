@@ -0,0 +1,24 @@
+package interceptors
+
+import "google.golang.org/grpc"
+
+// UnaryChain lists the unary interceptors in the order they wrap a call, the
+// first being outermost. Logging comes first so every later step logs with
+// the request ID, and recovery sits innermost so recovered panics are still
+// logged and counted as Internal.
+func UnaryChain() []grpc.UnaryServerInterceptor {
+	return []grpc.UnaryServerInterceptor{
+		LoggingUnaryInterceptor,
+		MetricsUnaryInterceptor,
+		RecoveryUnaryInterceptor,
+	}
+}
+
+// StreamChain is the streaming counterpart of UnaryChain.
+func StreamChain() []grpc.StreamServerInterceptor {
+	return []grpc.StreamServerInterceptor{
+		LoggingStreamInterceptor,
+		MetricsStreamInterceptor,
+		RecoveryStreamInterceptor,
+	}
+}
@@ -0,0 +1,41 @@
+package interceptors_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server/interceptors"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics/metricstest"
+)
+
+// chainUnary composes interceptors the way grpc.ChainUnaryInterceptor does.
+func chainUnary(chain []grpc.UnaryServerInterceptor, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) grpc.UnaryHandler {
+	for i := len(chain) - 1; i >= 0; i-- {
+		interceptor, next := chain[i], handler
+		handler = func(ctx context.Context, req any) (any, error) {
+			return interceptor(ctx, req, info, next)
+		}
+	}
+	return handler
+}
+
+func TestUnaryChain_CountsRecoveredPanics(t *testing.T) {
+	reader := metricstest.New(t)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Panics"}
+	handler := chainUnary(interceptors.UnaryChain(), info, func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	})
+
+	_, err := handler(context.Background(), "req")
+
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, 1.0, reader.Value(t, "grpc_requests_total",
+		metrics.MethodKey.String(info.FullMethod), metrics.StatusKey.String("Internal")))
+}
@@ -0,0 +1,101 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/helper"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/certs"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// LoggingUnaryInterceptor starts request-scoped logging, keyed by the caller's
+// x-request-id or a generated one which is echoed back in the response
+// headers, and writes one access log line per call. Calls over mTLS carry the
+// client certificate's identity as well.
+func LoggingUnaryInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	start := time.Now()
+	ctx = requestLogContext(ctx, info.FullMethod)
+
+	response, err := handler(ctx, req)
+
+	accessLog(ctx, start, err)
+	return response, err
+}
+
+func LoggingStreamInterceptor(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	start := time.Now()
+	ctx := requestLogContext(ss.Context(), info.FullMethod)
+
+	err := handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+
+	accessLog(ctx, start, err)
+	return err
+}
+
+func requestLogContext(ctx context.Context, method string) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	requestID, _ := helper.GetGRPCMetadataValue(md, constant.HeaderRequestID)
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(constant.HeaderRequestID, requestID))
+
+	fields := map[string]any{
+		logger.FieldRequestID:  requestID,
+		logger.FieldGRPCMethod: method,
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields[logger.FieldPeer] = p.Addr.String()
+	}
+	if identity, ok := certs.PeerIdentity(ctx); ok {
+		fields[logger.FieldPeerIdentity] = identity
+	}
+
+	return logger.NewContext(ctx, fields)
+}
+
+func accessLog(ctx context.Context, start time.Time, err error) {
+	code := status.Code(err)
+
+	var event *zerolog.Event
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded:
+		event = logger.Ctx(ctx).Error()
+	default:
+		event = logger.Ctx(ctx).Info()
+	}
+
+	event.
+		Str("grpc_code", code.String()).
+		Dur("duration", time.Since(start)).
+		Msg("gRPC request finished")
+}
+
+// contextServerStream swaps in a derived context for stream handlers.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (c *contextServerStream) Context() context.Context {
+	return c.ctx
+}
@@ -0,0 +1,216 @@
+package interceptors_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server/interceptors"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/certs"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/certs/certstest"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+)
+
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { log.Logger = previous })
+	return &buf
+}
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var lines []map[string]any
+	for _, raw := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(raw, &line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestLoggingUnaryInterceptor(t *testing.T) {
+	peerCtx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 50123},
+	})
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		handlerErr    error
+		expectedID    string
+		expectedCode  string
+		expectedLevel string
+	}{
+		{
+			name:          "request ID from metadata",
+			ctx:           metadata.NewIncomingContext(peerCtx, metadata.Pairs("x-request-id", "req-1")),
+			expectedID:    "req-1",
+			expectedCode:  "OK",
+			expectedLevel: "info",
+		},
+		{
+			name:          "generated request ID",
+			ctx:           peerCtx,
+			expectedCode:  "OK",
+			expectedLevel: "info",
+		},
+		{
+			name:          "client error",
+			ctx:           peerCtx,
+			handlerErr:    status.Error(codes.Unauthenticated, "unauthorized"),
+			expectedCode:  "Unauthenticated",
+			expectedLevel: "info",
+		},
+		{
+			name:          "server error",
+			ctx:           peerCtx,
+			handlerErr:    status.Error(codes.Internal, "internal"),
+			expectedCode:  "Internal",
+			expectedLevel: "error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureLogs(t)
+			info := &grpc.UnaryServerInfo{FullMethod: "/test.Method"}
+
+			handler := func(ctx context.Context, req any) (any, error) {
+				logger.AddField(ctx, logger.FieldUserID, 1)
+				logger.InfoCtx(ctx, "handling")
+				return "response", tt.handlerErr
+			}
+
+			resp, err := interceptors.LoggingUnaryInterceptor(tt.ctx, "req", info, handler)
+			assert.Equal(t, "response", resp)
+			assert.Equal(t, tt.handlerErr, err)
+
+			lines := logLines(t, buf)
+			require.Len(t, lines, 2)
+			handling, access := lines[0], lines[1]
+
+			requestID := handling[logger.FieldRequestID]
+			if tt.expectedID != "" {
+				assert.Equal(t, tt.expectedID, requestID)
+			} else {
+				assert.NotEmpty(t, requestID)
+			}
+			assert.Equal(t, "/test.Method", handling[logger.FieldGRPCMethod])
+			assert.Equal(t, "10.0.0.7:50123", handling[logger.FieldPeer])
+
+			assert.Equal(t, requestID, access[logger.FieldRequestID])
+			assert.Equal(t, float64(1), access[logger.FieldUserID])
+			assert.Equal(t, tt.expectedCode, access["grpc_code"])
+			assert.Equal(t, tt.expectedLevel, access["level"])
+			assert.Contains(t, access, "duration")
+		})
+	}
+}
+
+func TestLoggingStreamInterceptor(t *testing.T) {
+	buf := captureLogs(t)
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Stream"}
+	ss := &fakeServerStream{ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-1"))}
+
+	err := interceptors.LoggingStreamInterceptor(nil, ss, info, func(srv any, stream grpc.ServerStream) error {
+		logger.InfoCtx(stream.Context(), "streaming")
+		return nil
+	})
+	require.NoError(t, err)
+
+	lines := logLines(t, buf)
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.Equal(t, "req-1", line[logger.FieldRequestID])
+		assert.Equal(t, "/test.Stream", line[logger.FieldGRPCMethod])
+	}
+	assert.Equal(t, "OK", lines[1]["grpc_code"])
+}
+
+func TestLoggingUnaryInterceptor_PeerIdentity(t *testing.T) {
+	ca := certstest.NewCA(t, "test-ca")
+	serverPair := ca.Issue(t, "auth-service", "")
+
+	tests := []struct {
+		name             string
+		clientPair       *certstest.Pair
+		expectedIdentity any
+	}{
+		{
+			name:             "URI SAN",
+			clientPair:       ca.Issue(t, "gateway", "spiffe://cluster.local/ns/default/sa/gateway"),
+			expectedIdentity: "spiffe://cluster.local/ns/default/sa/gateway",
+		},
+		{
+			name:             "common name",
+			clientPair:       ca.Issue(t, "gateway", ""),
+			expectedIdentity: "gateway",
+		},
+		{
+			name:             "no client certificate",
+			expectedIdentity: nil,
+		},
+	}
+
+	serverTLS, err := certs.NewReloader(serverPair.CertFile, serverPair.KeyFile, ca.File, time.Minute)
+	require.NoError(t, err)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(serverTLS.ServerConfig(true))),
+		grpc.UnaryInterceptor(interceptors.LoggingUnaryInterceptor),
+	)
+	healthpb.RegisterHealthServer(s, health.NewServer())
+	go func() { _ = s.Serve(lis) }()
+	t.Cleanup(s.Stop)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureLogs(t)
+
+			certFile, keyFile := "", ""
+			if tt.clientPair != nil {
+				certFile, keyFile = tt.clientPair.CertFile, tt.clientPair.KeyFile
+			}
+			clientTLS, err := certs.NewReloader(certFile, keyFile, ca.File, time.Minute)
+			require.NoError(t, err)
+
+			conn, err := grpc.NewClient(
+				lis.Addr().String(),
+				grpc.WithTransportCredentials(credentials.NewTLS(clientTLS.ClientConfig("localhost", tls.VersionTLS12))),
+			)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+			require.NoError(t, err)
+
+			lines := logLines(t, buf)
+			require.Len(t, lines, 1)
+			assert.Equal(t, tt.expectedIdentity, lines[0][logger.FieldPeerIdentity])
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+func MetricsUnaryInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	start := time.Now()
+
+	response, err := handler(ctx, req)
+
+	method := metrics.MethodKey.String(info.FullMethod)
+	statusCode := metrics.StatusKey.String(status.Code(err).String())
+	metrics.GRPCRequests.Add(ctx, 1, metric.WithAttributes(method, statusCode))
+	metrics.GRPCRequestDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(method))
+
+	return response, err
+}
+
+// MetricsStreamInterceptor counts streams by final status like unary calls,
+// and records how long each stream stayed open and how many messages it
+// carried in each direction.
+func MetricsStreamInterceptor(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	start := time.Now()
+	method := metrics.MethodKey.String(info.FullMethod)
+
+	err := handler(srv, &countingServerStream{ServerStream: ss, method: method})
+
+	ctx := ss.Context()
+	metrics.GRPCRequests.Add(ctx, 1, metric.WithAttributes(method, metrics.StatusKey.String(status.Code(err).String())))
+	metrics.GRPCStreamDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(method))
+
+	return err
+}
+
+type countingServerStream struct {
+	grpc.ServerStream
+	method attribute.KeyValue
+}
+
+func (c *countingServerStream) SendMsg(m any) error {
+	err := c.ServerStream.SendMsg(m)
+	if err == nil {
+		metrics.GRPCStreamMessages.Add(c.Context(), 1, metric.WithAttributes(c.method, metrics.DirectionKey.String("sent")))
+	}
+	return err
+}
+
+func (c *countingServerStream) RecvMsg(m any) error {
+	err := c.ServerStream.RecvMsg(m)
+	if err == nil {
+		metrics.GRPCStreamMessages.Add(c.Context(), 1, metric.WithAttributes(c.method, metrics.DirectionKey.String("received")))
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package interceptors_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server/interceptors"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics/metricstest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetricsUnaryInterceptor(t *testing.T) {
+	tests := []struct {
+		name         string
+		handlerErr   error
+		expectedCode string
+	}{
+		{"success", nil, "OK"},
+		{"internal_error", status.Error(codes.Internal, "oops"), "Internal"},
+		{"custom_error", errors.New("custom"), "Unknown"}, // non-gRPC error
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := metricstest.New(t)
+
+			// Create a dummy handler that returns specified error
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return "response", tt.handlerErr
+			}
+
+			info := &grpc.UnaryServerInfo{FullMethod: "/test.Method"}
+
+			resp, err := interceptors.MetricsUnaryInterceptor(context.Background(), "req", info, handler)
+
+			assert.Equal(t, "response", resp)
+			assert.Equal(t, tt.handlerErr, err)
+
+			method := metrics.MethodKey.String(info.FullMethod)
+			assert.Equal(t, 1.0, reader.Value(t, "grpc_requests_total", method, metrics.StatusKey.String(tt.expectedCode)),
+				"counter should be incremented with correct labels")
+			assert.Equal(t, 1.0, reader.Value(t, "grpc_request_duration_seconds", method), "latency metric should be observed")
+		})
+	}
+}
+
+func TestMetricsStreamInterceptor(t *testing.T) {
+	tests := []struct {
+		name         string
+		handlerErr   error
+		expectedCode string
+	}{
+		{"success", nil, "OK"},
+		{"canceled", status.Error(codes.Canceled, "client went away"), "Canceled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := metricstest.New(t)
+
+			info := &grpc.StreamServerInfo{FullMethod: "/test.Stream"}
+			ss := &fakeServerStream{ctx: context.Background(), recv: []any{"a", "b"}}
+
+			err := interceptors.MetricsStreamInterceptor(nil, ss, info, func(srv any, stream grpc.ServerStream) error {
+				for stream.RecvMsg(nil) == nil {
+					_ = stream.SendMsg("reply")
+				}
+				return tt.handlerErr
+			})
+			assert.Equal(t, tt.handlerErr, err)
+			assert.Equal(t, 2, ss.sent)
+
+			method := metrics.MethodKey.String(info.FullMethod)
+			assert.Equal(t, 1.0, reader.Value(t, "grpc_requests_total", method, metrics.StatusKey.String(tt.expectedCode)))
+			assert.Equal(t, 2.0, reader.Value(t, "grpc_stream_messages_total", method, metrics.DirectionKey.String("sent")))
+			assert.Equal(t, 2.0, reader.Value(t, "grpc_stream_messages_total", method, metrics.DirectionKey.String("received")))
+			assert.Equal(t, 1.0, reader.Value(t, "grpc_stream_duration_seconds", method))
+		})
+	}
+}
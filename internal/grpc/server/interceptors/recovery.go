@@ -0,0 +1,49 @@
+package interceptors
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RecoveryUnaryInterceptor turns a handler panic into codes.Internal instead
+// of crashing the process, logging the stack trace.
+func RecoveryUnaryInterceptor(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (response any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(ctx, info.FullMethod, r)
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+func RecoveryStreamInterceptor(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(ss.Context(), info.FullMethod, r)
+		}
+	}()
+
+	return handler(srv, ss)
+}
+
+func recovered(ctx context.Context, method string, r any) error {
+	logger.ErrorCtx(ctx, "gRPC handler %s panicked: %v\n%s", method, r, debug.Stack())
+	return status.Error(codes.Internal, constant.MessageInternalServerError)
+}
@@ -0,0 +1,131 @@
+package interceptors_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server/interceptors"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+)
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent int
+	recv []any
+}
+
+func (f *fakeServerStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeServerStream) SendMsg(m any) error {
+	f.sent++
+	return nil
+}
+
+func (f *fakeServerStream) RecvMsg(m any) error {
+	if len(f.recv) == 0 {
+		return errors.New("EOF")
+	}
+	f.recv = f.recv[1:]
+	return nil
+}
+
+func TestRecoveryUnaryInterceptor(t *testing.T) {
+	handlerErr := status.Error(codes.NotFound, "not found")
+
+	tests := []struct {
+		name         string
+		handler      grpc.UnaryHandler
+		expectedResp any
+		expectedErr  error
+		expectedCode codes.Code
+	}{
+		{
+			name: "no panic",
+			handler: func(ctx context.Context, req any) (any, error) {
+				return "response", nil
+			},
+			expectedResp: "response",
+			expectedCode: codes.OK,
+		},
+		{
+			name: "handler error passes through",
+			handler: func(ctx context.Context, req any) (any, error) {
+				return nil, handlerErr
+			},
+			expectedErr:  handlerErr,
+			expectedCode: codes.NotFound,
+		},
+		{
+			name: "failed type assertion",
+			handler: func(ctx context.Context, req any) (any, error) {
+				claims := map[string]any{"id": "not-a-number"}
+				return claims["id"].(float64), nil
+			},
+			expectedCode: codes.Internal,
+		},
+		{
+			name: "nil pointer dereference",
+			handler: func(ctx context.Context, req any) (any, error) {
+				var m map[string]int
+				m["x"] = 1
+				return nil, nil
+			},
+			expectedCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &grpc.UnaryServerInfo{FullMethod: "/test.Method"}
+
+			resp, err := interceptors.RecoveryUnaryInterceptor(context.Background(), "req", info, tt.handler)
+
+			assert.Equal(t, tt.expectedResp, resp)
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+			if tt.expectedErr != nil {
+				assert.Equal(t, tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestRecoveryStreamInterceptor(t *testing.T) {
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Stream"}
+	ss := &fakeServerStream{ctx: context.Background()}
+
+	err := interceptors.RecoveryStreamInterceptor(nil, ss, info, func(srv any, ss grpc.ServerStream) error {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	err = interceptors.RecoveryStreamInterceptor(nil, ss, info, func(srv any, ss grpc.ServerStream) error {
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestRecoveryUnaryInterceptor_LogsWithRequestFields(t *testing.T) {
+	buf := captureLogs(t)
+	ctx := logger.NewContext(context.Background(), map[string]any{logger.FieldRequestID: "req-123"})
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Method"}
+
+	_, err := interceptors.RecoveryUnaryInterceptor(ctx, "req", info, func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	lines := logLines(t, buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "error", lines[0]["level"])
+	assert.Equal(t, "req-123", lines[0][logger.FieldRequestID])
+}
@@ -0,0 +1,133 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	libjwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics/metricstest"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	userpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/user"
+)
+
+func TestAuthenticationServer_LoginAttemptsMetric(t *testing.T) {
+	loginResp := &userpb.FindByCredentialResponse{Data: &userpb.FindByCredentialResponseData{User: dummyUser}}
+
+	tests := []struct {
+		name            string
+		setupMocks      func(u *MockUserClient, j *MockJWTManager)
+		expectedOutcome string
+		expectedReason  string
+	}{
+		{
+			name: "success",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
+				u.On("FindByCredential", mock.Anything, mock.Anything).Return(loginResp, nil)
+				j.On("NewToken", uint(dummyUser.Id), dummyUser.Name).Return("token123", nil)
+			},
+			expectedOutcome: "success",
+			expectedReason:  constant.MetricLoginNone,
+		},
+		{
+			name: "wrong password",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
+				u.On("FindByCredential", mock.Anything, mock.Anything).Return(nil, status.Error(codes.Unauthenticated, "invalid credentials"))
+			},
+			expectedOutcome: "failure",
+			expectedReason:  constant.MetricLoginInvalidCredentials,
+		},
+		{
+			name: "invalid request",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
+				u.On("FindByCredential", mock.Anything, mock.Anything).Return(nil, status.Error(codes.InvalidArgument, "email is required"))
+			},
+			expectedOutcome: "failure",
+			expectedReason:  constant.MetricLoginInvalidRequest,
+		},
+		{
+			name: "user service down",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
+				u.On("FindByCredential", mock.Anything, mock.Anything).Return(nil, status.Error(codes.Unavailable, "connection refused"))
+			},
+			expectedOutcome: "failure",
+			expectedReason:  constant.MetricLoginUserService,
+		},
+		{
+			name: "token signing fails",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
+				u.On("FindByCredential", mock.Anything, mock.Anything).Return(loginResp, nil)
+				j.On("NewToken", uint(dummyUser.Id), dummyUser.Name).Return("", fmt.Errorf("signing failed"))
+			},
+			expectedOutcome: "failure",
+			expectedReason:  constant.MetricLoginTokenError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, j := new(MockUserClient), new(MockJWTManager)
+			tt.setupMocks(u, j)
+			s := &server.AuthenticationServer{UserClient: u, JWTManager: j}
+			reader := metricstest.New(t)
+
+			_, _ = s.Login(context.Background(), &authpb.LoginRequest{Email: dummyUser.Email, Password: "secret"})
+
+			assert.Equal(t, 1.0, reader.Value(t, "auth_login_attempts_total",
+				metrics.OutcomeKey.String(tt.expectedOutcome), metrics.ReasonKey.String(tt.expectedReason)))
+		})
+	}
+}
+
+func TestAuthenticationServer_TokenVerificationFailuresMetric(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupMocks     func(j *MockJWTManager)
+		expectedReason string
+	}{
+		{
+			name: "expired",
+			setupMocks: func(j *MockJWTManager) {
+				j.On("ParseToken", "validtoken").Return(nil, fmt.Errorf("%w: %w", libjwt.ErrTokenInvalidClaims, libjwt.ErrTokenExpired))
+			},
+			expectedReason: constant.MetricVerifyExpired,
+		},
+		{
+			name: "bad signature",
+			setupMocks: func(j *MockJWTManager) {
+				j.On("ParseToken", "validtoken").Return(nil, libjwt.ErrTokenSignatureInvalid)
+			},
+			expectedReason: constant.MetricVerifyBadSignature,
+		},
+		{
+			name: "revoked",
+			setupMocks: func(j *MockJWTManager) {
+				j.On("ParseToken", "validtoken").Return(libjwt.MapClaims{"id": float64(dummyUser.Id), "jti": "1"}, nil)
+				j.On("IsBlacklisted", mock.Anything, "1").Return(true)
+			},
+			expectedReason: constant.MetricVerifyRevoked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := new(MockJWTManager)
+			tt.setupMocks(j)
+			s := &server.AuthenticationServer{JWTManager: j}
+			reader := metricstest.New(t)
+
+			_, err := s.VerifyToken(withBearer("validtoken"), &authpb.VerifyTokenRequest{})
+
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			assert.Equal(t, 1.0, reader.Value(t, "auth_token_verification_failures_total", metrics.ReasonKey.String(tt.expectedReason)))
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Probe and check results. A degraded probe still passes.
+const (
+	ProbeStatusOK       = "ok"
+	ProbeStatusDegraded = "degraded"
+	ProbeStatusFailed   = "failed"
+)
+
+// Probe check names besides the dependencies
+const (
+	ProbeCheckHealthChecker = "health_checker"
+	ProbeCheckShutdown      = "shutdown"
+)
+
+// A checker that hasn't refreshed for this many intervals is considered stuck.
+const staleRefreshIntervals = 3
+
+// ProbeResponse is the JSON body of /livez, /readyz and /startupz.
+type ProbeResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]*ProbeCheck `json:"checks"`
+}
+
+type ProbeCheck struct {
+	Status   string `json:"status"`
+	Critical bool   `json:"critical,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// Probes returns the orchestrator probes by path, for the metrics server:
+//
+//   - /livez fails only when the background checker is stuck, so a restart
+//     can help. A dependency outage never fails it.
+//   - /readyz fails while a critical dependency is down, before the first
+//     check and once shutdown has begun, taking the instance out of rotation.
+//     A non-critical dependency being down only degrades it.
+//   - /startupz passes once the first check has completed.
+func (h *HealthServer) Probes() map[string]http.Handler {
+	return map[string]http.Handler{
+		"/livez":    probeHandler(h.Liveness),
+		"/readyz":   probeHandler(h.Readiness),
+		"/startupz": probeHandler(h.Startup),
+	}
+}
+
+func (h *HealthServer) Liveness() *ProbeResponse {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	check := &ProbeCheck{Status: ProbeStatusOK}
+	if h.interval > 0 && !h.refreshedAt.IsZero() {
+		limit := staleRefreshIntervals*h.interval + h.Timeout
+		if since := time.Since(h.refreshedAt); since > limit {
+			check.Status = ProbeStatusFailed
+			check.Message = fmt.Sprintf("no health check for %s", since.Round(time.Second))
+		}
+	}
+
+	return newProbeResponse(map[string]*ProbeCheck{ProbeCheckHealthChecker: check})
+}
+
+func (h *HealthServer) Readiness() *ProbeResponse {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	checks := make(map[string]*ProbeCheck, len(healthDependencies)+1)
+	for _, dependency := range healthDependencies {
+		servingStatus, _ := h.statusLocked(dependency)
+		check := &ProbeCheck{Status: ProbeStatusOK, Critical: h.critical(dependency), Message: servingStatus.String()}
+		if servingStatus != healthpb.HealthCheckResponse_SERVING {
+			check.Status = ProbeStatusDegraded
+			if check.Critical {
+				check.Status = ProbeStatusFailed
+			}
+		}
+		checks[dependency] = check
+	}
+
+	if h.shutdown {
+		checks[ProbeCheckShutdown] = &ProbeCheck{Status: ProbeStatusFailed, Critical: true, Message: "shutting down"}
+	}
+
+	return newProbeResponse(checks)
+}
+
+func (h *HealthServer) Startup() *ProbeResponse {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	check := &ProbeCheck{Status: ProbeStatusOK}
+	if h.refreshedAt.IsZero() {
+		check.Status = ProbeStatusFailed
+		check.Message = "waiting for the first health check"
+	}
+
+	return newProbeResponse(map[string]*ProbeCheck{ProbeCheckHealthChecker: check})
+}
+
+// newProbeResponse takes the worst of the check results as the probe status.
+func newProbeResponse(checks map[string]*ProbeCheck) *ProbeResponse {
+	res := &ProbeResponse{Status: ProbeStatusOK, Checks: checks}
+	for _, check := range checks {
+		switch {
+		case check.Status == ProbeStatusFailed:
+			res.Status = ProbeStatusFailed
+		case check.Status == ProbeStatusDegraded && res.Status == ProbeStatusOK:
+			res.Status = ProbeStatusDegraded
+		}
+	}
+	return res
+}
+
+func probeHandler(probe func() *ProbeResponse) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res := probe()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if res.Status == ProbeStatusFailed {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	})
+}
@@ -0,0 +1,177 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics/metricstest"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis/redistest"
+)
+
+func probe(t *testing.T, hs *server.HealthServer, path string) (int, *server.ProbeResponse) {
+	t.Helper()
+
+	handler, ok := hs.Probes()[path]
+	require.True(t, ok, "no probe at %s", path)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	res := &server.ProbeResponse{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), res))
+	return rec.Code, res
+}
+
+func newRefreshedHealthServer(t *testing.T, redisErr, userErr error, nonCritical ...string) *server.HealthServer {
+	t.Helper()
+	metricstest.New(t)
+
+	redisMock := new(redistest.Client)
+	redisMock.On("Health", mock.Anything).Return(redisErr)
+	userMock := new(MockUserClient)
+	userMock.On("Health", mock.Anything).Return(userErr)
+
+	hs := &server.HealthServer{RedisClient: redisMock, UserClient: userMock, NonCritical: nonCritical}
+	hs.Refresh(context.Background())
+	return hs
+}
+
+func TestHealthServer_Readiness(t *testing.T) {
+	tests := []struct {
+		name           string
+		redisErr       error
+		userErr        error
+		nonCritical    []string
+		expectedCode   int
+		expectedStatus string
+		expectedChecks map[string]string
+		expectedGRPC   bool
+	}{
+		{
+			name:           "all healthy",
+			expectedCode:   http.StatusOK,
+			expectedStatus: server.ProbeStatusOK,
+			expectedChecks: map[string]string{"redis": server.ProbeStatusOK, "user-service": server.ProbeStatusOK},
+			expectedGRPC:   true,
+		},
+		{
+			name:           "critical dependency down",
+			redisErr:       assert.AnError,
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: server.ProbeStatusFailed,
+			expectedChecks: map[string]string{"redis": server.ProbeStatusFailed, "user-service": server.ProbeStatusOK},
+		},
+		{
+			name:           "non-critical dependency down",
+			userErr:        assert.AnError,
+			nonCritical:    []string{"user-service"},
+			expectedCode:   http.StatusOK,
+			expectedStatus: server.ProbeStatusDegraded,
+			expectedChecks: map[string]string{"redis": server.ProbeStatusOK, "user-service": server.ProbeStatusDegraded},
+			expectedGRPC:   true,
+		},
+		{
+			name:           "critical and non-critical down",
+			redisErr:       assert.AnError,
+			userErr:        assert.AnError,
+			nonCritical:    []string{"user-service"},
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: server.ProbeStatusFailed,
+			expectedChecks: map[string]string{"redis": server.ProbeStatusFailed, "user-service": server.ProbeStatusDegraded},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hs := newRefreshedHealthServer(t, tt.redisErr, tt.userErr, tt.nonCritical...)
+
+			code, res := probe(t, hs, "/readyz")
+
+			assert.Equal(t, tt.expectedCode, code)
+			assert.Equal(t, tt.expectedStatus, res.Status)
+			require.Len(t, res.Checks, len(tt.expectedChecks))
+			for name, expected := range tt.expectedChecks {
+				assert.Equal(t, expected, res.Checks[name].Status, name)
+			}
+			assert.Equal(t, tt.expectedGRPC, checkHealth(t, hs, server.HealthServiceOverall) == serving)
+
+			// A dependency outage never fails liveness.
+			code, _ = probe(t, hs, "/livez")
+			assert.Equal(t, http.StatusOK, code)
+		})
+	}
+}
+
+func TestHealthServer_Readiness_Shutdown(t *testing.T) {
+	hs := newRefreshedHealthServer(t, nil, nil)
+
+	hs.Shutdown()
+	code, res := probe(t, hs, "/readyz")
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, server.ProbeStatusFailed, res.Checks[server.ProbeCheckShutdown].Status)
+}
+
+func TestHealthServer_Startup(t *testing.T) {
+	metricstest.New(t)
+	redisMock := new(redistest.Client)
+	redisMock.On("Health", mock.Anything).Return(assert.AnError)
+	userMock := new(MockUserClient)
+	userMock.On("Health", mock.Anything).Return(nil)
+	hs := &server.HealthServer{RedisClient: redisMock, UserClient: userMock}
+
+	code, _ := probe(t, hs, "/startupz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	code, _ = probe(t, hs, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+
+	// Started once checked, even with a dependency down.
+	hs.Refresh(context.Background())
+	code, res := probe(t, hs, "/startupz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, server.ProbeStatusOK, res.Status)
+}
+
+func TestHealthServer_Liveness_StuckChecker(t *testing.T) {
+	metricstest.New(t)
+	redisMock := new(redistest.Client)
+	redisMock.On("Health", mock.Anything).Return(nil)
+	userMock := new(MockUserClient)
+	userMock.On("Health", mock.Anything).Return(nil)
+	hs := &server.HealthServer{RedisClient: redisMock, UserClient: userMock}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		hs.Run(ctx, 5*time.Millisecond)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool {
+		code, _ := probe(t, hs, "/startupz")
+		return code == http.StatusOK
+	}, time.Second, time.Millisecond)
+
+	code, _ := probe(t, hs, "/livez")
+	assert.Equal(t, http.StatusOK, code)
+
+	// With the checker gone, liveness fails after a few missed intervals.
+	cancel()
+	<-done
+	assert.Eventually(t, func() bool {
+		code, _ := probe(t, hs, "/livez")
+		return code == http.StatusServiceUnavailable
+	}, time.Second, 5*time.Millisecond)
+
+	_, res := probe(t, hs, "/livez")
+	assert.Equal(t, server.ProbeStatusFailed, res.Checks[server.ProbeCheckHealthChecker].Status)
+}
@@ -2,47 +2,67 @@
 package server
 
 import (
+	"errors"
 	"net"
 
-	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/client/user"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server/interceptors"
-	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/jwt"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/certs"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
-	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis"
 	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/otel"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
+var ErrTLSKeyPair = errors.New("both GRPC_SERVER_TLS_CERT_FILE and GRPC_SERVER_TLS_KEY_FILE must be set")
+
 func NewServer(
-	userClient user.UserService,
-	redisClient redis.RedisService,
-	jwtManager jwt.JWTManager,
+	authServer *AuthenticationServer,
+	healthServer *HealthServer,
+	opts ...grpc.ServerOption,
 ) *grpc.Server {
 	// Create gRPC server with interceptors & tracing
-	s := grpc.NewServer(
-		grpc.UnaryInterceptor(interceptors.PrometheusUnaryInterceptor),
+	s := grpc.NewServer(append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(interceptors.UnaryChain()...),
+		grpc.ChainStreamInterceptor(interceptors.StreamChain()...),
 		grpc.StatsHandler(otelgrpc.NewServerHandler(
 			otelgrpc.WithTracerProvider(otel.GetTracerProvider()),
 			otelgrpc.WithPropagators(otel.GetTextMapPropagator()),
 		)),
-	)
-
-	authpb.RegisterAuthenticationServiceServer(s, &AuthenticationServer{
-		UserClient: userClient,
-		JWTManager: jwtManager,
-	})
+	}, opts...)...)
 
-	healthpb.RegisterHealthServer(s, &HealthServer{
-		UserClient:  userClient,
-		RedisClient: redisClient,
-	})
+	authpb.RegisterAuthenticationServiceServer(s, authServer)
+	healthpb.RegisterHealthServer(s, healthServer)
+	authv3.RegisterAuthorizationServer(s, &ExtAuthzServer{AuthServer: authServer})
 
 	return s
 }
 
+// TLSOptions returns the server options for transport security. It returns
+// none when no certificate is configured and the server stays plaintext. With
+// a client CA bundle clients are authenticated with mTLS, and their identity is
+// added to the request log fields and audit events.
+func TLSOptions(cfg *config.GRPCServer) ([]grpc.ServerOption, error) {
+	if cfg.TLSCertFile == "" && cfg.TLSKeyFile == "" {
+		return nil, nil
+	}
+	if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+		return nil, ErrTLSKeyPair
+	}
+
+	reloader, err := certs.NewReloader(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCAFile, cfg.TLSReloadInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := reloader.ServerConfig(cfg.TLSClientCertOptional)
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsConfig))}, nil
+}
+
 func ServeListener(listener net.Listener, server *grpc.Server) error {
 	logger.Info("gRPC server started on %q", listener.Addr().String())
 	if err := server.Serve(listener); err != nil {
@@ -5,6 +5,11 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/apikey"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/audit"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/device"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/federation"
+	libjwt "github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/jwt"
 	userpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/user"
 	"github.com/stretchr/testify/mock"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
@@ -45,6 +50,36 @@ func (m *MockUserClient) Store(ctx context.Context, in *userpb.StoreRequest) (*u
 	return args.Get(0).(*userpb.StoreResponse), nil
 }
 
+func (m *MockUserClient) FindByEmail(ctx context.Context, in *userpb.FindByEmailRequest) (*userpb.FindByEmailResponse, error) {
+	args := m.Called(ctx, in)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(*userpb.FindByEmailResponse), nil
+}
+
+func (m *MockUserClient) FindByFederatedIdentity(ctx context.Context, in *userpb.FindByFederatedIdentityRequest) (*userpb.FindByFederatedIdentityResponse, error) {
+	args := m.Called(ctx, in)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(*userpb.FindByFederatedIdentityResponse), nil
+}
+
+func (m *MockUserClient) LinkFederatedIdentity(ctx context.Context, in *userpb.LinkFederatedIdentityRequest) (*userpb.LinkFederatedIdentityResponse, error) {
+	args := m.Called(ctx, in)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(*userpb.LinkFederatedIdentityResponse), nil
+}
+
 func (m *MockUserClient) Health(ctx context.Context) error {
 	args := m.Called(ctx)
 
@@ -71,80 +106,164 @@ func (m *MockHealthClient) Check(ctx context.Context, in *healthpb.HealthCheckRe
 	return args.Get(0).(*healthpb.HealthCheckResponse), args.Error(1)
 }
 
-// ===== Mock Redis Client =====
-type MockRedisClient struct {
+// ===== Mock JWT Manager =====
+type MockJWTManager struct {
 	mock.Mock
 }
 
-func (m *MockRedisClient) Get(ctx context.Context, key string) (string, error) {
-	args := m.Called(ctx, key)
+func (m *MockJWTManager) NewToken(id uint, username string) (string, error) {
+	args := m.Called(id, username)
+	return args.String(0), args.Error(1)
+}
 
-	if err := args.Error(1); err != nil {
-		return "", err
-	}
+func (m *MockJWTManager) NewScopedToken(id uint, username string, scope string) (string, error) {
+	args := m.Called(id, username, scope)
+	return args.String(0), args.Error(1)
+}
 
-	return args.Get(0).(string), nil
+func (m *MockJWTManager) NewProfileToken(id uint, username string, profile *libjwt.Profile) (string, error) {
+	args := m.Called(id, username, profile)
+	return args.String(0), args.Error(1)
 }
 
-func (m *MockRedisClient) Set(ctx context.Context, key string, val string, expiration time.Duration) error {
-	args := m.Called(ctx, key, val, expiration)
+func (m *MockJWTManager) NewExchangedToken(exchange *libjwt.TokenExchange) (string, int64, error) {
+	args := m.Called(exchange)
+	return args.String(0), args.Get(1).(int64), args.Error(2)
+}
 
-	if err := args.Error(0); err != nil {
-		return err
+func (m *MockJWTManager) ParseToken(token string) (jwt.MapClaims, error) {
+	args := m.Called(token)
+	if claims, ok := args.Get(0).(jwt.MapClaims); ok {
+		return claims, args.Error(1)
 	}
+	return nil, args.Error(1)
+}
 
-	return nil
+func (m *MockJWTManager) AddToBlacklist(ctx context.Context, jti string, expiry int64) error {
+	args := m.Called(ctx, jti, expiry)
+	return args.Error(0)
+}
 
+func (m *MockJWTManager) IsBlacklisted(ctx context.Context, jti string) bool {
+	args := m.Called(ctx, jti)
+	return args.Bool(0)
 }
 
-func (m *MockRedisClient) Del(ctx context.Context, keys string) error {
-	args := m.Called(ctx, keys)
+// ===== Mock Device Manager =====
+type MockDeviceManager struct {
+	mock.Mock
+}
 
-	if err := args.Error(0); err != nil {
-		return err
+func (m *MockDeviceManager) Authorize(ctx context.Context, clientID string, scope string) (*device.Authorization, error) {
+	args := m.Called(ctx, clientID, scope)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return args.Get(0).(*device.Authorization), nil
 }
 
-func (m *MockRedisClient) Health(ctx context.Context) error {
-	args := m.Called(ctx)
+func (m *MockDeviceManager) Lookup(ctx context.Context, userCode string) (*device.Authorization, error) {
+	args := m.Called(ctx, userCode)
 
-	if err := args.Error(0); err != nil {
-		return err
+	if err := args.Error(1); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return args.Get(0).(*device.Authorization), nil
 }
 
-func (m *MockRedisClient) Close() error {
-	return nil
+func (m *MockDeviceManager) Approve(ctx context.Context, userCode string, userID uint, username string) error {
+	args := m.Called(ctx, userCode, userID, username)
+	return args.Error(0)
 }
 
-// ===== Mock JWT Manager =====
-type MockJWTManager struct {
+func (m *MockDeviceManager) Deny(ctx context.Context, userCode string) error {
+	args := m.Called(ctx, userCode)
+	return args.Error(0)
+}
+
+func (m *MockDeviceManager) Poll(ctx context.Context, deviceCode string, clientID string) (*device.Authorization, error) {
+	args := m.Called(ctx, deviceCode, clientID)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(*device.Authorization), nil
+}
+
+// ===== Mock Federation Manager =====
+type MockFederationManager struct {
 	mock.Mock
 }
 
-func (m *MockJWTManager) NewToken(id uint, username string) (string, error) {
-	args := m.Called(id, username)
-	return args.String(0), args.Error(1)
+func (m *MockFederationManager) AuthCodeURL(ctx context.Context, provider string) (string, string, error) {
+	args := m.Called(ctx, provider)
+	return args.String(0), args.String(1), args.Error(2)
 }
 
-func (m *MockJWTManager) ParseToken(token string) (jwt.MapClaims, error) {
-	args := m.Called(token)
-	if claims, ok := args.Get(0).(jwt.MapClaims); ok {
-		return claims, args.Error(1)
+func (m *MockFederationManager) Exchange(ctx context.Context, state string, code string) (*federation.Identity, error) {
+	args := m.Called(ctx, state, code)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
 	}
-	return nil, args.Error(1)
+
+	return args.Get(0).(*federation.Identity), nil
 }
 
-func (m *MockJWTManager) AddToBlacklist(ctx context.Context, jti string, expiry int64) error {
-	args := m.Called(ctx, jti, expiry)
+// ===== Mock API Key Manager =====
+type MockAPIKeyManager struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyManager) Create(ctx context.Context, userID uint, username string, name string, scopes []string, expiresIn time.Duration) (string, *apikey.APIKey, error) {
+	args := m.Called(ctx, userID, username, name, scopes, expiresIn)
+
+	if err := args.Error(2); err != nil {
+		return "", nil, err
+	}
+
+	return args.String(0), args.Get(1).(*apikey.APIKey), nil
+}
+
+func (m *MockAPIKeyManager) List(ctx context.Context, userID uint) ([]*apikey.APIKey, error) {
+	args := m.Called(ctx, userID)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).([]*apikey.APIKey), nil
+}
+
+func (m *MockAPIKeyManager) Revoke(ctx context.Context, userID uint, id string) error {
+	args := m.Called(ctx, userID, id)
 	return args.Error(0)
 }
 
-func (m *MockJWTManager) IsBlacklisted(ctx context.Context, jti string) bool {
-	args := m.Called(ctx, jti)
-	return args.Bool(0)
+func (m *MockAPIKeyManager) Verify(ctx context.Context, key string) (*apikey.APIKey, error) {
+	args := m.Called(ctx, key)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(*apikey.APIKey), nil
+}
+
+// ===== Mock Auditor =====
+type MockAuditor struct {
+	mock.Mock
+}
+
+func (m *MockAuditor) Record(event *audit.Event) {
+	m.Called(event)
+}
+
+func (m *MockAuditor) Close(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis/redistest"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
@@ -24,9 +25,10 @@ func TestNewServer_ServiceRegistration(t *testing.T) {
 	}{
 		{"AuthenticationService_registered", "auth.AuthenticationService"},
 		{"HealthService_registered", "grpc.health.v1.Health"},
+		{"ExtAuthzService_registered", "envoy.service.auth.v3.Authorization"},
 	}
 
-	s := server.NewServer(nil, nil, nil)
+	s := server.NewServer(&server.AuthenticationServer{}, &server.HealthServer{})
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -42,14 +44,20 @@ func TestServeListener_Integration(t *testing.T) {
 	require.NoError(t, err)
 
 	mockUserClient := new(MockUserClient)
-	mockRedis := new(MockRedisClient)
+	mockRedis := new(redistest.Client)
 	mockJWT := new(MockJWTManager)
 
-	//HealthCheck rpc calls redis, userClient "Health"
+	//HealthCheck rpc answers from the last redis, userClient "Health" refresh
 	mockRedis.On("Health", mock.Anything).Return(nil)
 	mockUserClient.On("Health", mock.Anything).Return(nil)
 
-	s := server.NewServer(mockUserClient, mockRedis, mockJWT)
+	healthServer := &server.HealthServer{UserClient: mockUserClient, RedisClient: mockRedis}
+	healthServer.Refresh(context.Background())
+
+	s := server.NewServer(
+		&server.AuthenticationServer{UserClient: mockUserClient, JWTManager: mockJWT},
+		healthServer,
+	)
 
 	go func() {
 		_ = server.ServeListener(lis, s)
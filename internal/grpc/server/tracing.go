@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/client/user"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/jwt"
+	userpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/user"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const tracerName = "github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server"
+
+// Span attributes set on the authentication spans and on the RPC span itself.
+// Token ids are hashed, so a trace can be matched to a token without holding
+// anything a caller could present.
+const (
+	UserIDKey        = attribute.Key("auth.user_id")
+	TokenIDHashKey   = attribute.Key("auth.token.jti_hash")
+	OutcomeKey       = attribute.Key("auth.outcome")
+	FailureReasonKey = attribute.Key("auth.failure_reason")
+	BlacklistKey     = attribute.Key("auth.blacklist.result")
+)
+
+// Span names for the steps of an authentication RPC
+const (
+	SpanSignToken      = "auth.sign_token"
+	SpanParseToken     = "auth.parse_token"
+	SpanBlacklistCheck = "auth.blacklist_lookup"
+)
+
+// Span names for user-service calls, after the RPC they wrap
+const (
+	SpanUserFindByID                = "user.FindById"
+	SpanUserFindByCredential        = "user.FindByCredential"
+	SpanUserFindByEmail             = "user.FindByEmail"
+	SpanUserStore                   = "user.Store"
+	SpanUserFindByFederatedIdentity = "user.FindByFederatedIdentity"
+	SpanUserLinkFederatedIdentity   = "user.LinkFederatedIdentity"
+)
+
+// Span events for security relevant occurrences
+const (
+	EventTokenRejected = "auth.token_rejected"
+	EventTokenRevoked  = "auth.token_revoked"
+)
+
+// startSpan starts a child span of the one in ctx. The tracer is looked up on
+// every call so spans follow the provider set up by jaeger.Init.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan marks span failed when err is set and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// hashTokenID returns a SHA-256 hex digest of a token's jti.
+func hashTokenID(jti string) string {
+	sum := sha256.Sum256([]byte(jti))
+	return hex.EncodeToString(sum[:])
+}
+
+// signToken issues an access token inside its own span. A non-empty scope
+// restricts the token to it instead of everything the user's roles grant, and
+// a profile is signed into the token for degraded mode.
+func (a *AuthenticationServer) signToken(ctx context.Context, userId uint, username string, scope string, profile *jwt.Profile) (string, error) {
+	_, span := startSpan(ctx, SpanSignToken, UserIDKey.Int(int(userId)))
+	var (
+		token string
+		err   error
+	)
+	switch {
+	case scope != "":
+		token, err = a.JWTManager.NewScopedToken(userId, username, scope)
+	case profile != nil:
+		token, err = a.JWTManager.NewProfileToken(userId, username, profile)
+	default:
+		token, err = a.JWTManager.NewToken(userId, username)
+	}
+	endSpan(span, err)
+	return token, err
+}
+
+// users returns the user service client with a span around every call, named
+// after the method and tagged with the user it resolved.
+func (a *AuthenticationServer) users() user.UserService {
+	return tracedUserService{a.UserClient}
+}
+
+type tracedUserService struct {
+	user.UserService
+}
+
+func (u tracedUserService) FindById(ctx context.Context, in *userpb.FindByIdRequest) (*userpb.FindByIdResponse, error) {
+	ctx, span := startSpan(ctx, SpanUserFindByID, UserIDKey.Int(int(in.Id)))
+	res, err := u.UserService.FindById(ctx, in)
+	endSpan(span, err)
+	return res, err
+}
+
+func (u tracedUserService) FindByCredential(ctx context.Context, in *userpb.FindByCredentialRequest) (*userpb.FindByCredentialResponse, error) {
+	ctx, span := startSpan(ctx, SpanUserFindByCredential)
+	res, err := u.UserService.FindByCredential(ctx, in)
+	if err == nil {
+		span.SetAttributes(UserIDKey.Int(int(res.Data.User.Id)))
+	}
+	endSpan(span, err)
+	return res, err
+}
+
+func (u tracedUserService) FindByEmail(ctx context.Context, in *userpb.FindByEmailRequest) (*userpb.FindByEmailResponse, error) {
+	ctx, span := startSpan(ctx, SpanUserFindByEmail)
+	res, err := u.UserService.FindByEmail(ctx, in)
+	switch status.Code(err) {
+	case grpccodes.OK:
+		span.SetAttributes(UserIDKey.Int(int(res.Data.User.Id)))
+		endSpan(span, nil)
+	case grpccodes.NotFound:
+		// An unknown email is an answer, not a failure: federated login
+		// registers the user next.
+		endSpan(span, nil)
+	default:
+		endSpan(span, err)
+	}
+	return res, err
+}
+
+func (u tracedUserService) Store(ctx context.Context, in *userpb.StoreRequest) (*userpb.StoreResponse, error) {
+	ctx, span := startSpan(ctx, SpanUserStore)
+	res, err := u.UserService.Store(ctx, in)
+	if err == nil {
+		span.SetAttributes(UserIDKey.Int(int(res.Data.User.Id)))
+	}
+	endSpan(span, err)
+	return res, err
+}
+
+func (u tracedUserService) FindByFederatedIdentity(ctx context.Context, in *userpb.FindByFederatedIdentityRequest) (*userpb.FindByFederatedIdentityResponse, error) {
+	ctx, span := startSpan(ctx, SpanUserFindByFederatedIdentity)
+	res, err := u.UserService.FindByFederatedIdentity(ctx, in)
+	switch status.Code(err) {
+	case grpccodes.OK:
+		span.SetAttributes(UserIDKey.Int(int(res.Data.User.Id)))
+		endSpan(span, nil)
+	case grpccodes.NotFound:
+		// A first login through the provider, linked next.
+		endSpan(span, nil)
+	default:
+		endSpan(span, err)
+	}
+	return res, err
+}
+
+func (u tracedUserService) LinkFederatedIdentity(ctx context.Context, in *userpb.LinkFederatedIdentityRequest) (*userpb.LinkFederatedIdentityResponse, error) {
+	ctx, span := startSpan(ctx, SpanUserLinkFederatedIdentity, UserIDKey.Int(int(in.UserId)))
+	res, err := u.UserService.LinkFederatedIdentity(ctx, in)
+	endSpan(span, err)
+	return res, err
+}
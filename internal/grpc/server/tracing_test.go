@@ -0,0 +1,207 @@
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	libjwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/grpc/server"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	userpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/user"
+)
+
+// startRPCSpan records spans for the test and returns ctx carrying a span that
+// stands in for the gRPC server span.
+func startRPCSpan(t *testing.T, ctx context.Context) (context.Context, trace.Span, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	ctx, span := otel.Tracer("test").Start(ctx, "rpc")
+	return ctx, span, recorder
+}
+
+func findSpan(t *testing.T, recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+	require.Failf(t, "span not found", "no span named %q", name)
+	return nil
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]string {
+	attrs := map[attribute.Key]string{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	return attrs
+}
+
+func spanEvents(span sdktrace.ReadOnlySpan) []string {
+	var names []string
+	for _, event := range span.Events() {
+		names = append(names, event.Name)
+	}
+	return names
+}
+
+func TestAuthenticationServer_LoginSpans(t *testing.T) {
+	u, j := new(MockUserClient), new(MockJWTManager)
+	u.On("FindByCredential", mock.Anything, mock.Anything).Return(&userpb.FindByCredentialResponse{
+		Data: &userpb.FindByCredentialResponseData{User: dummyUser},
+	}, nil)
+	j.On("NewToken", uint(dummyUser.Id), dummyUser.Name).Return("token123", nil)
+	s := &server.AuthenticationServer{UserClient: u, JWTManager: j}
+
+	ctx, rpc, recorder := startRPCSpan(t, context.Background())
+	_, err := s.Login(ctx, &authpb.LoginRequest{Email: dummyUser.Email, Password: "secret"})
+	rpc.End()
+	require.NoError(t, err)
+
+	lookup := findSpan(t, recorder, server.SpanUserFindByCredential)
+	assert.Equal(t, rpc.SpanContext().SpanID(), lookup.Parent().SpanID())
+	assert.Equal(t, "1", spanAttributes(lookup)[server.UserIDKey])
+
+	sign := findSpan(t, recorder, server.SpanSignToken)
+	assert.Equal(t, rpc.SpanContext().SpanID(), sign.Parent().SpanID())
+	assert.Equal(t, "1", spanAttributes(sign)[server.UserIDKey])
+
+	attrs := spanAttributes(findSpan(t, recorder, "rpc"))
+	assert.Equal(t, "success", attrs[server.OutcomeKey])
+	assert.Equal(t, "1", attrs[server.UserIDKey])
+	assert.NotContains(t, attrs, server.FailureReasonKey)
+}
+
+func TestAuthenticationServer_LoginSpans_UserServiceError(t *testing.T) {
+	u := new(MockUserClient)
+	u.On("FindByCredential", mock.Anything, mock.Anything).Return(nil, status.Error(codes.Unauthenticated, "invalid credentials"))
+	s := &server.AuthenticationServer{UserClient: u, JWTManager: new(MockJWTManager)}
+
+	ctx, rpc, recorder := startRPCSpan(t, context.Background())
+	_, err := s.Login(ctx, &authpb.LoginRequest{Email: dummyUser.Email, Password: "wrong"})
+	rpc.End()
+	require.Error(t, err)
+
+	assert.Equal(t, otelcodes.Error, findSpan(t, recorder, server.SpanUserFindByCredential).Status().Code)
+
+	attrs := spanAttributes(findSpan(t, recorder, "rpc"))
+	assert.Equal(t, "failure", attrs[server.OutcomeKey])
+	assert.Equal(t, "Unauthenticated", attrs[server.FailureReasonKey])
+}
+
+func TestAuthenticationServer_VerifyTokenSpans(t *testing.T) {
+	claims := libjwt.MapClaims{"id": float64(dummyUser.Id), "jti": "1"}
+
+	tests := []struct {
+		name              string
+		setupMocks        func(u *MockUserClient, j *MockJWTManager)
+		expectedSpans     []string
+		expectedParse     map[attribute.Key]string
+		expectedBlacklist string
+		expectedRPCEvents []string
+	}{
+		{
+			name: "valid token",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
+				j.On("ParseToken", "validtoken").Return(claims, nil)
+				j.On("IsBlacklisted", mock.Anything, "1").Return(false)
+				u.On("FindById", mock.Anything, mock.Anything).Return(&userpb.FindByIdResponse{
+					Data: &userpb.FindByIdResponseData{User: dummyUser},
+				}, nil)
+			},
+			expectedSpans: []string{server.SpanParseToken, server.SpanBlacklistCheck, server.SpanUserFindByID},
+			expectedParse: map[attribute.Key]string{
+				server.OutcomeKey: "success",
+				server.UserIDKey:  "1",
+			},
+			expectedBlacklist: constant.MetricBlacklistMiss,
+		},
+		{
+			name: "revoked token",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
+				j.On("ParseToken", "validtoken").Return(claims, nil)
+				j.On("IsBlacklisted", mock.Anything, "1").Return(true)
+			},
+			expectedSpans: []string{server.SpanParseToken, server.SpanBlacklistCheck},
+			expectedParse: map[attribute.Key]string{
+				server.OutcomeKey: "success",
+				server.UserIDKey:  "1",
+			},
+			expectedBlacklist: constant.MetricBlacklistHit,
+			expectedRPCEvents: []string{server.EventTokenRevoked},
+		},
+		{
+			name: "expired token",
+			setupMocks: func(u *MockUserClient, j *MockJWTManager) {
+				j.On("ParseToken", "validtoken").Return(nil, fmt.Errorf("%w: %w", libjwt.ErrTokenInvalidClaims, libjwt.ErrTokenExpired))
+			},
+			expectedSpans: []string{server.SpanParseToken},
+			expectedParse: map[attribute.Key]string{
+				server.OutcomeKey:       "failure",
+				server.FailureReasonKey: constant.MetricVerifyExpired,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, j := new(MockUserClient), new(MockJWTManager)
+			tt.setupMocks(u, j)
+			s := &server.AuthenticationServer{UserClient: u, JWTManager: j}
+
+			ctx, rpc, recorder := startRPCSpan(t, withBearer("validtoken"))
+			_, _ = s.VerifyToken(ctx, &authpb.VerifyTokenRequest{})
+			rpc.End()
+
+			var names []string
+			for _, span := range recorder.Ended() {
+				if span.Name() != "rpc" {
+					names = append(names, span.Name())
+				}
+			}
+			assert.Equal(t, tt.expectedSpans, names)
+
+			parse := findSpan(t, recorder, server.SpanParseToken)
+			parseAttrs := spanAttributes(parse)
+			for key, value := range tt.expectedParse {
+				assert.Equal(t, value, parseAttrs[key], key)
+			}
+
+			if tt.expectedBlacklist == "" {
+				assert.Equal(t, otelcodes.Error, parse.Status().Code)
+				assert.Equal(t, []string{server.EventTokenRejected, "exception"}, spanEvents(parse))
+				return
+			}
+
+			// The jti only ever shows up hashed.
+			assert.Len(t, parseAttrs[server.TokenIDHashKey], 64)
+			assert.NotEqual(t, "1", parseAttrs[server.TokenIDHashKey])
+
+			blacklist := findSpan(t, recorder, server.SpanBlacklistCheck)
+			assert.Equal(t, tt.expectedBlacklist, spanAttributes(blacklist)[server.BlacklistKey])
+			assert.Equal(t, parseAttrs[server.TokenIDHashKey], spanAttributes(blacklist)[server.TokenIDHashKey])
+			assert.Equal(t, tt.expectedRPCEvents, spanEvents(blacklist))
+			assert.Equal(t, tt.expectedRPCEvents, spanEvents(findSpan(t, recorder, "rpc")))
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"google.golang.org/grpc/metadata"
@@ -43,6 +44,22 @@ func GetEnvInt(key string, defaultVal int) int {
 	return defaultVal
 }
 
+func GetEnvFloat(key string, defaultVal float64) float64 {
+	if val, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return val
+	}
+
+	return defaultVal
+}
+
+func GetEnvBool(key string, defaultVal bool) bool {
+	if val, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return val
+	}
+
+	return defaultVal
+}
+
 func GetEnvDurationSeconds(key string, defaultVal time.Duration) time.Duration {
 	if val, err := strconv.Atoi(os.Getenv(key)); err == nil {
 		return time.Duration(val) * time.Second
@@ -50,3 +67,38 @@ func GetEnvDurationSeconds(key string, defaultVal time.Duration) time.Duration {
 
 	return defaultVal * time.Second
 }
+
+func GetEnvSlice(key string, defaultVal []string) []string {
+	val := os.Getenv(key)
+	if val == "" {
+		return defaultVal
+	}
+
+	var items []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// GetEnvMap reads comma separated key=value pairs, e.g. "a=1,b=2". Items
+// without a key are dropped.
+func GetEnvMap(key string) map[string]string {
+	items := GetEnvSlice(key, nil)
+	if len(items) == 0 {
+		return nil
+	}
+
+	m := make(map[string]string, len(items))
+	for _, item := range items {
+		k, v, _ := strings.Cut(item, "=")
+		if k = strings.TrimSpace(k); k != "" {
+			m[k] = strings.TrimSpace(v)
+		}
+	}
+
+	return m
+}
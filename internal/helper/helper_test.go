@@ -190,3 +190,174 @@ func TestGetEnvDurationSeconds(t *testing.T) {
 		})
 	}
 }
+
+func TestGetEnvSlice(t *testing.T) {
+	tests := []struct {
+		name       string
+		envKey     string
+		envValue   string
+		defaultVal []string
+		expected   []string
+	}{
+		{
+			name:       "comma separated values from env",
+			envKey:     "TEST_ENV_SLICE",
+			envValue:   "openid, email ,profile",
+			defaultVal: []string{"openid"},
+			expected:   []string{"openid", "email", "profile"},
+		},
+		{
+			name:       "empty items are dropped",
+			envKey:     "TEST_ENV_SLICE_EMPTY_ITEMS",
+			envValue:   "a,,b,",
+			defaultVal: nil,
+			expected:   []string{"a", "b"},
+		},
+		{
+			name:       "env not set, fallback",
+			envKey:     "TEST_ENV_SLICE_NOT_SET",
+			envValue:   "",
+			defaultVal: []string{"openid"},
+			expected:   []string{"openid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.envValue != "" {
+				t.Setenv(tt.envKey, tt.envValue)
+			}
+			got := helper.GetEnvSlice(tt.envKey, tt.defaultVal)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestGetEnvBool(t *testing.T) {
+	tests := []struct {
+		name       string
+		envKey     string
+		envValue   string
+		defaultVal bool
+		expected   bool
+	}{
+		{
+			name:       "true from env",
+			envKey:     "TEST_ENV_BOOL_TRUE",
+			envValue:   "true",
+			defaultVal: false,
+			expected:   true,
+		},
+		{
+			name:       "false from env",
+			envKey:     "TEST_ENV_BOOL_FALSE",
+			envValue:   "0",
+			defaultVal: true,
+			expected:   false,
+		},
+		{
+			name:       "invalid value, fallback",
+			envKey:     "TEST_ENV_BOOL_INVALID",
+			envValue:   "maybe",
+			defaultVal: true,
+			expected:   true,
+		},
+		{
+			name:       "env not set, fallback",
+			envKey:     "TEST_ENV_BOOL_NOT_SET",
+			envValue:   "",
+			defaultVal: true,
+			expected:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.envValue != "" {
+				t.Setenv(tt.envKey, tt.envValue)
+			}
+			got := helper.GetEnvBool(tt.envKey, tt.defaultVal)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestGetEnvFloat(t *testing.T) {
+	tests := []struct {
+		name       string
+		envKey     string
+		envValue   string
+		defaultVal float64
+		expected   float64
+	}{
+		{
+			name:       "valid float from env",
+			envKey:     "TEST_ENV_FLOAT",
+			envValue:   "0.25",
+			defaultVal: 1,
+			expected:   0.25,
+		},
+		{
+			name:       "invalid value, fallback",
+			envKey:     "TEST_ENV_FLOAT_INVALID",
+			envValue:   "a quarter",
+			defaultVal: 1,
+			expected:   1,
+		},
+		{
+			name:       "env not set, fallback",
+			envKey:     "TEST_ENV_FLOAT_NOT_SET",
+			envValue:   "",
+			defaultVal: 1,
+			expected:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.envValue != "" {
+				t.Setenv(tt.envKey, tt.envValue)
+			}
+			got := helper.GetEnvFloat(tt.envKey, tt.defaultVal)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestGetEnvMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		envKey   string
+		envValue string
+		expected map[string]string
+	}{
+		{
+			name:     "key value pairs from env",
+			envKey:   "TEST_ENV_MAP",
+			envValue: "team=identity, region = eu-west-1,flag",
+			expected: map[string]string{"team": "identity", "region": "eu-west-1", "flag": ""},
+		},
+		{
+			name:     "items without key are dropped",
+			envKey:   "TEST_ENV_MAP_NO_KEY",
+			envValue: "=value,a=1",
+			expected: map[string]string{"a": "1"},
+		},
+		{
+			name:     "env not set",
+			envKey:   "TEST_ENV_MAP_NOT_SET",
+			envValue: "",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.envValue != "" {
+				t.Setenv(tt.envKey, tt.envValue)
+			}
+			got := helper.GetEnvMap(tt.envKey)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package server
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	libjwt "github.com/golang-jwt/jwt/v5"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Forward-auth response headers, read by the proxy and copied upstream
+const (
+	HeaderForwardUserID    = "X-User-Id"
+	HeaderForwardUserEmail = "X-User-Email"
+	HeaderForwardUserName  = "X-User-Name"
+)
+
+// ForwardAuthHandler answers NGINX auth_request and Traefik ForwardAuth
+// subrequests. Successful verifications are cached briefly by token hash, so a
+// revoked token can keep passing for up to the cache TTL, but never past its
+// own expiry.
+type ForwardAuthHandler struct {
+	AuthServer authpb.AuthenticationServiceServer
+	cookie     string
+	cache      *verifyCache
+}
+
+func NewForwardAuthHandler(cfg *config.ForwardAuth, authServer authpb.AuthenticationServiceServer) *ForwardAuthHandler {
+	return &ForwardAuthHandler{
+		AuthServer: authServer,
+		cookie:     cfg.Cookie,
+		cache:      newVerifyCache(cfg.CacheTTL, cfg.CacheSize),
+	}
+}
+
+func (f *ForwardAuthHandler) Forward(w http.ResponseWriter, r *http.Request) {
+	token := f.token(r)
+	if token == "" {
+		writeForwardAuthError(w, codes.Unauthenticated)
+		return
+	}
+
+	key := sha256.Sum256([]byte(token))
+	user, ok := f.cache.get(key)
+	if !ok {
+		ctx := metadata.NewIncomingContext(r.Context(), metadata.Pairs(constant.HeaderAuthorization, constant.HeaderBearerPrefix+token))
+		res, err := f.AuthServer.VerifyToken(ctx, &authpb.VerifyTokenRequest{})
+		if err != nil {
+			code := status.Code(err)
+			if code != codes.Unauthenticated && code != codes.PermissionDenied {
+				logger.Error("Forward auth verification failed: %v", err)
+			}
+			writeForwardAuthError(w, code)
+			return
+		}
+
+		user = res.Data.User
+		f.cache.set(key, token, user)
+	}
+
+	w.Header().Set(HeaderForwardUserID, strconv.Itoa(int(user.Id)))
+	w.Header().Set(HeaderForwardUserEmail, user.Email)
+	w.Header().Set(HeaderForwardUserName, user.Name)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
+
+// token reads the bearer token from the Authorization header, falling back to
+// the configured cookie which holds the bare token.
+func (f *ForwardAuthHandler) token(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), constant.HeaderBearerPrefix); ok {
+		return token
+	}
+	if f.cookie == "" {
+		return ""
+	}
+	if c, err := r.Cookie(f.cookie); err == nil {
+		return c.Value
+	}
+	return ""
+}
+
+func writeForwardAuthError(w http.ResponseWriter, code codes.Code) {
+	switch code {
+	case codes.Unauthenticated:
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeJSON(w, http.StatusUnauthorized, &gatewayErrorResponse{Message: constant.MessageUnauthorized})
+	case codes.PermissionDenied:
+		writeJSON(w, http.StatusForbidden, &gatewayErrorResponse{Message: constant.MessageForbidden})
+	default:
+		writeJSON(w, httpStatusFromCode(code), &gatewayErrorResponse{Message: constant.MessageInternalServerError})
+	}
+}
+
+type verifyCacheEntry struct {
+	key       [sha256.Size]byte
+	user      *authpb.User
+	expiresAt time.Time
+}
+
+// verifyCache is a small LRU cache of verified users keyed by token hash.
+// Entries live for the TTL or until the token expires, whichever is sooner,
+// and once full the least recently used entry makes room for the new one.
+type verifyCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	order   *list.List
+	entries map[[sha256.Size]byte]*list.Element
+}
+
+func newVerifyCache(ttl time.Duration, size int) *verifyCache {
+	return &verifyCache{
+		ttl:     ttl,
+		size:    size,
+		order:   list.New(),
+		entries: map[[sha256.Size]byte]*list.Element{},
+	}
+}
+
+func (c *verifyCache) get(key [sha256.Size]byte) (*authpb.User, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*verifyCacheEntry)
+	if !time.Now().Before(entry.expiresAt) {
+		c.remove(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.user, true
+}
+
+// set caches user until the TTL runs out or, for JWTs, until the token's own
+// exp, so a cached entry never outlives the token it was verified from.
+func (c *verifyCache) set(key [sha256.Size]byte, token string, user *authpb.User) {
+	if c.ttl <= 0 || c.size <= 0 {
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(c.ttl)
+	if exp, ok := tokenExpiry(token); ok && exp.Before(expiresAt) {
+		expiresAt = exp
+	}
+	if !now.Before(expiresAt) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*verifyCacheEntry)
+		entry.user, entry.expiresAt = user, expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+	for c.order.Len() >= c.size {
+		c.remove(c.order.Back())
+	}
+	c.entries[key] = c.order.PushFront(&verifyCacheEntry{key: key, user: user, expiresAt: expiresAt})
+}
+
+func (c *verifyCache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*verifyCacheEntry).key)
+}
+
+// tokenExpiry reads exp from a token the authentication server has just
+// verified, so the signature isn't checked again. API keys and tokens without
+// exp report false.
+func tokenExpiry(token string) (time.Time, bool) {
+	claims := libjwt.MapClaims{}
+	if _, _, err := libjwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return time.Time{}, false
+	}
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return time.Time{}, false
+	}
+	return exp.Time, true
+}
@@ -0,0 +1,182 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	libjwt "github.com/golang-jwt/jwt/v5"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/http/server"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var forwardAuthCfg = &config.ForwardAuth{Cookie: "session", CacheTTL: time.Minute, CacheSize: 10}
+
+func forward(h http.Handler, setup func(r *http.Request)) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/auth/forward", nil)
+	setup(req)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestForwardAuthHandler(t *testing.T) {
+	verified := &authpb.VerifyTokenResponse{
+		Message: constant.MessageOK,
+		Data:    &authpb.VerifyTokenResponseData{User: &authpb.User{Id: 7, Name: "john", Email: "john@example.com"}},
+	}
+
+	tests := []struct {
+		name            string
+		setupMocks      func(a *MockAuthenticationServer)
+		setupRequest    func(r *http.Request)
+		expectedCode    int
+		expectedHeaders map[string]string
+	}{
+		{
+			name: "bearer token",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("VerifyToken", hasAuthorization("Bearer token123"), mock.Anything).Return(verified, nil)
+			},
+			setupRequest: func(r *http.Request) { r.Header.Set("Authorization", "Bearer token123") },
+			expectedCode: http.StatusOK,
+			expectedHeaders: map[string]string{
+				server.HeaderForwardUserID:    "7",
+				server.HeaderForwardUserEmail: "john@example.com",
+				server.HeaderForwardUserName:  "john",
+			},
+		},
+		{
+			name: "session cookie",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("VerifyToken", hasAuthorization("Bearer token123"), mock.Anything).Return(verified, nil)
+			},
+			setupRequest: func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "session", Value: "token123"}) },
+			expectedCode: http.StatusOK,
+			expectedHeaders: map[string]string{
+				server.HeaderForwardUserID: "7",
+			},
+		},
+		{
+			name: "invalid token",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("VerifyToken", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.Unauthenticated, constant.MessageUnauthorized))
+			},
+			setupRequest:    func(r *http.Request) { r.Header.Set("Authorization", "Bearer token123") },
+			expectedCode:    http.StatusUnauthorized,
+			expectedHeaders: map[string]string{"WWW-Authenticate": "Bearer"},
+		},
+		{
+			name: "forbidden",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("VerifyToken", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.PermissionDenied, constant.MessageForbidden))
+			},
+			setupRequest: func(r *http.Request) { r.Header.Set("Authorization", "Bearer token123") },
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:            "no credentials",
+			setupMocks:      func(a *MockAuthenticationServer) {},
+			setupRequest:    func(r *http.Request) {},
+			expectedCode:    http.StatusUnauthorized,
+			expectedHeaders: map[string]string{"WWW-Authenticate": "Bearer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := new(MockAuthenticationServer)
+			tt.setupMocks(a)
+
+			rec := forward(server.NewServer("", a, forwardAuthCfg).Handler, tt.setupRequest)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			for k, v := range tt.expectedHeaders {
+				assert.Equal(t, v, rec.Header().Get(k))
+			}
+			a.AssertExpectations(t)
+		})
+	}
+}
+
+func TestForwardAuthHandler_Cache(t *testing.T) {
+	verified := &authpb.VerifyTokenResponse{
+		Message: constant.MessageOK,
+		Data:    &authpb.VerifyTokenResponseData{User: &authpb.User{Id: 7, Name: "john", Email: "john@example.com"}},
+	}
+	withToken := func(token string) func(r *http.Request) {
+		return func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }
+	}
+
+	t.Run("successful verification is reused", func(t *testing.T) {
+		a := new(MockAuthenticationServer)
+		a.On("VerifyToken", mock.Anything, mock.Anything).Return(verified, nil).Once()
+		h := server.NewServer("", a, forwardAuthCfg).Handler
+
+		for range 3 {
+			rec := forward(h, withToken("token123"))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "7", rec.Header().Get(server.HeaderForwardUserID))
+		}
+		a.AssertExpectations(t)
+	})
+
+	t.Run("failures are not cached", func(t *testing.T) {
+		a := new(MockAuthenticationServer)
+		a.On("VerifyToken", mock.Anything, mock.Anything).
+			Return(nil, status.Error(codes.Unauthenticated, constant.MessageUnauthorized)).Twice()
+		h := server.NewServer("", a, forwardAuthCfg).Handler
+
+		assert.Equal(t, http.StatusUnauthorized, forward(h, withToken("token123")).Code)
+		assert.Equal(t, http.StatusUnauthorized, forward(h, withToken("token123")).Code)
+		a.AssertExpectations(t)
+	})
+
+	t.Run("disabled with zero ttl", func(t *testing.T) {
+		a := new(MockAuthenticationServer)
+		a.On("VerifyToken", mock.Anything, mock.Anything).Return(verified, nil).Twice()
+		h := server.NewServer("", a, &config.ForwardAuth{CacheSize: 10}).Handler
+
+		forward(h, withToken("token123"))
+		forward(h, withToken("token123"))
+		a.AssertExpectations(t)
+	})
+
+	t.Run("full cache evicts least recently used", func(t *testing.T) {
+		a := new(MockAuthenticationServer)
+		a.On("VerifyToken", hasAuthorization("Bearer first"), mock.Anything).Return(verified, nil).Twice()
+		a.On("VerifyToken", hasAuthorization("Bearer second"), mock.Anything).Return(verified, nil).Once()
+		h := server.NewServer("", a, &config.ForwardAuth{CacheTTL: time.Minute, CacheSize: 1}).Handler
+
+		forward(h, withToken("first"))
+		forward(h, withToken("second"))
+		forward(h, withToken("second"))
+		forward(h, withToken("first"))
+		a.AssertExpectations(t)
+	})
+
+	t.Run("entries never outlive the token", func(t *testing.T) {
+		expired, err := libjwt.NewWithClaims(libjwt.SigningMethodHS256, libjwt.MapClaims{
+			"id":  7,
+			"exp": time.Now().Add(-time.Second).Unix(),
+		}).SignedString([]byte("secret"))
+		assert.NoError(t, err)
+
+		a := new(MockAuthenticationServer)
+		a.On("VerifyToken", hasAuthorization("Bearer "+expired), mock.Anything).Return(verified, nil).Twice()
+		h := server.NewServer("", a, forwardAuthCfg).Handler
+
+		forward(h, withToken(expired))
+		forward(h, withToken(expired))
+		a.AssertExpectations(t)
+	})
+}
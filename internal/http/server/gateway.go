@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// GatewayHandler transcodes JSON requests to AuthenticationService calls for
+// clients that can't speak gRPC. Bodies use the proto field names, and the
+// Authorization header is passed on as the gRPC "authorization" metadata.
+type GatewayHandler struct {
+	AuthServer authpb.AuthenticationServiceServer
+}
+
+type gatewayErrorResponse struct {
+	Message string `json:"message"`
+}
+
+// maxGatewayBodySize bounds request bodies, which are only ever a few fields
+// of credentials.
+const maxGatewayBodySize = 64 << 10
+
+var (
+	gatewayUnmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+	gatewayMarshal   = protojson.MarshalOptions{UseProtoNames: true}
+)
+
+func (g *GatewayHandler) Register(w http.ResponseWriter, r *http.Request) {
+	transcode(w, r, &authpb.RegisterRequest{}, g.AuthServer.Register)
+}
+
+func (g *GatewayHandler) Login(w http.ResponseWriter, r *http.Request) {
+	transcode(w, r, &authpb.LoginRequest{}, g.AuthServer.Login)
+}
+
+func (g *GatewayHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	transcode(w, r, &authpb.VerifyTokenRequest{}, g.AuthServer.VerifyToken)
+}
+
+func (g *GatewayHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	transcode(w, r, &authpb.LogoutRequest{}, g.AuthServer.Logout)
+}
+
+func transcode[Req proto.Message, Res proto.Message](
+	w http.ResponseWriter,
+	r *http.Request,
+	in Req,
+	call func(context.Context, Req) (Res, error),
+) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxGatewayBodySize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeJSON(w, http.StatusRequestEntityTooLarge, &gatewayErrorResponse{Message: constant.MessageRequestTooLarge})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, &gatewayErrorResponse{Message: constant.MessageBadRequest})
+		return
+	}
+	if len(body) > 0 {
+		if err := gatewayUnmarshal.Unmarshal(body, in); err != nil {
+			writeJSON(w, http.StatusBadRequest, &gatewayErrorResponse{Message: constant.MessageBadRequest})
+			return
+		}
+	}
+
+	ctx := r.Context()
+	if header := r.Header.Get("Authorization"); header != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(constant.HeaderAuthorization, header))
+	}
+
+	res, err := call(ctx, in)
+	if err != nil {
+		writeGatewayStatusError(w, err)
+		return
+	}
+
+	b, err := gatewayMarshal.Marshal(res)
+	if err != nil {
+		logger.Error("Failed to encode gateway response: %v", err)
+		writeJSON(w, http.StatusInternalServerError, &gatewayErrorResponse{Message: constant.MessageInternalServerError})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(b); err != nil {
+		logger.Error("Failed to write HTTP response: %v", err)
+	}
+}
+
+func writeGatewayStatusError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	code := httpStatusFromCode(st.Code())
+	if code >= http.StatusInternalServerError {
+		logger.Error("Gateway request failed: %v", err)
+	}
+
+	writeJSON(w, code, &gatewayErrorResponse{Message: st.Message()})
+}
+
+// httpStatusFromCode follows the mapping in google.rpc.Code.
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
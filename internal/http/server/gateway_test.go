@@ -0,0 +1,180 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/http/server"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func hasAuthorization(value string) any {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		md, _ := metadata.FromIncomingContext(ctx)
+		return len(md.Get(constant.HeaderAuthorization)) == 1 && md.Get(constant.HeaderAuthorization)[0] == value
+	})
+}
+
+func TestGatewayHandler(t *testing.T) {
+	user := &authpb.User{Id: 1, Name: "john", Email: "john@example.com"}
+
+	tests := []struct {
+		name         string
+		setupMocks   func(a *MockAuthenticationServer)
+		path         string
+		body         string
+		header       string
+		expectedCode int
+		expectedBody map[string]any
+	}{
+		{
+			name: "login",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("Login", mock.Anything, mock.MatchedBy(func(in *authpb.LoginRequest) bool {
+					return in.Email == "john@example.com" && in.Password == "secret"
+				})).Return(&authpb.LoginResponse{
+					Message: constant.MessageOK,
+					Data:    &authpb.LoginResponseData{Token: "token123", User: user},
+				}, nil)
+			},
+			path:         "/v1/auth/login",
+			body:         `{"email": "john@example.com", "password": "secret"}`,
+			expectedCode: http.StatusOK,
+			expectedBody: map[string]any{
+				"message": constant.MessageOK,
+				"data": map[string]any{
+					"token": "token123",
+					"user":  map[string]any{"id": float64(1), "name": "john", "email": "john@example.com"},
+				},
+			},
+		},
+		{
+			name: "register conflict",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("Register", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.AlreadyExists, "Email already taken"))
+			},
+			path:         "/v1/auth/register",
+			body:         `{"name": "john", "email": "john@example.com", "password": "secret"}`,
+			expectedCode: http.StatusConflict,
+			expectedBody: map[string]any{"message": "Email already taken"},
+		},
+		{
+			name: "verify forwards authorization header",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("VerifyToken", hasAuthorization("Bearer token123"), mock.Anything).Return(&authpb.VerifyTokenResponse{
+					Message: constant.MessageOK,
+					Data:    &authpb.VerifyTokenResponseData{User: user},
+				}, nil)
+			},
+			path:         "/v1/auth/verify",
+			header:       "Bearer token123",
+			expectedCode: http.StatusOK,
+			expectedBody: map[string]any{
+				"message": constant.MessageOK,
+				"data": map[string]any{
+					"user": map[string]any{"id": float64(1), "name": "john", "email": "john@example.com"},
+				},
+			},
+		},
+		{
+			name: "logout unauthenticated",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("Logout", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.Unauthenticated, constant.MessageUnauthorized))
+			},
+			path:         "/v1/auth/logout",
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: map[string]any{"message": constant.MessageUnauthorized},
+		},
+		{
+			name:         "malformed body",
+			setupMocks:   func(a *MockAuthenticationServer) {},
+			path:         "/v1/auth/login",
+			body:         `{"email": `,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: map[string]any{"message": constant.MessageBadRequest},
+		},
+		{
+			name:         "body too large",
+			setupMocks:   func(a *MockAuthenticationServer) {},
+			path:         "/v1/auth/login",
+			body:         `{"email": "` + strings.Repeat("a", 64<<10) + `"}`,
+			expectedCode: http.StatusRequestEntityTooLarge,
+			expectedBody: map[string]any{"message": constant.MessageRequestTooLarge},
+		},
+		{
+			name: "user service down",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("Login", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.Unavailable, "connection refused"))
+			},
+			path:         "/v1/auth/login",
+			body:         `{}`,
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: map[string]any{"message": "connection refused"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := new(MockAuthenticationServer)
+			tt.setupMocks(a)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			server.NewServer("", a, &config.ForwardAuth{}).Handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			body := map[string]any{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedBody, body)
+			a.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGatewayHandler_PropagatesTraceContext(t *testing.T) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	a := new(MockAuthenticationServer)
+	a.On("VerifyToken", mock.MatchedBy(func(ctx context.Context) bool {
+		return trace.SpanContextFromContext(ctx).TraceID().String() == traceID
+	}), mock.Anything).Return(&authpb.VerifyTokenResponse{Message: constant.MessageOK}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/auth/verify", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	server.NewServer("", a, &config.ForwardAuth{}).Handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	a.AssertExpectations(t)
+}
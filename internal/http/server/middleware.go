@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// withMiddleware gives the HTTP handlers what the gRPC interceptor chain gives
+// RPCs, in the same order: request-scoped logging outermost, then metrics,
+// then panic recovery. The handlers call the gRPC server methods in process,
+// so the gRPC interceptors never see these requests.
+func withMiddleware(next http.Handler) http.Handler {
+	return loggingMiddleware(metricsMiddleware(recoveryMiddleware(next)))
+}
+
+// loggingMiddleware starts request-scoped logging, keyed by the caller's
+// X-Request-Id or a generated one which is echoed back, and writes one access
+// log line per request.
+func loggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := recordStatus(w)
+
+		requestID := r.Header.Get(constant.HeaderRequestID)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		rec.Header().Set(constant.HeaderRequestID, requestID)
+
+		ctx := logger.NewContext(r.Context(), map[string]any{
+			logger.FieldRequestID:  requestID,
+			logger.FieldHTTPMethod: r.Method,
+			logger.FieldHTTPPath:   r.URL.Path,
+			logger.FieldPeer:       r.RemoteAddr,
+		})
+
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		accessLog(ctx, start, rec.status)
+	})
+}
+
+func accessLog(ctx context.Context, start time.Time, code int) {
+	var event *zerolog.Event
+	if code >= http.StatusInternalServerError {
+		event = logger.Ctx(ctx).Error()
+	} else {
+		event = logger.Ctx(ctx).Info()
+	}
+
+	event.
+		Int("http_status", code).
+		Dur("duration", time.Since(start)).
+		Msg("HTTP request finished")
+}
+
+// metricsMiddleware counts requests by mux pattern rather than path, so
+// unmatched paths can't blow up the label cardinality.
+func metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := recordStatus(w)
+
+		next.ServeHTTP(rec, r)
+
+		// The mux sets the pattern on the request it was handed.
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx := r.Context()
+		routeKey := metrics.RouteKey.String(route)
+		statusCode := metrics.StatusKey.String(strconv.Itoa(rec.status))
+		metrics.HTTPRequests.Add(ctx, 1, metric.WithAttributes(routeKey, statusCode))
+		metrics.HTTPRequestDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(routeKey))
+	})
+}
+
+// recoveryMiddleware turns a handler panic into a 500 instead of crashing the
+// process, logging the stack trace.
+func recoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := recordStatus(w)
+
+		defer func() {
+			if p := recover(); p != nil {
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+
+				logger.ErrorCtx(r.Context(), "HTTP handler %s %s panicked: %v\n%s", r.Method, r.URL.Path, p, debug.Stack())
+				if !rec.wroteHeader {
+					writeJSON(rec, http.StatusInternalServerError, &gatewayErrorResponse{Message: constant.MessageInternalServerError})
+				}
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// statusRecorder keeps the status code written by the handler. It is shared
+// by the middlewares of one request.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func recordStatus(w http.ResponseWriter) *statusRecorder {
+	if rec, ok := w.(*statusRecorder); ok {
+		return rec
+	}
+	return &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if !s.wroteHeader {
+		s.status = code
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
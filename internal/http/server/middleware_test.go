@@ -0,0 +1,126 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/http/server"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics/metricstest"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { log.Logger = previous })
+	return &buf
+}
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var lines []map[string]any
+	for _, raw := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(raw, &line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		requestID     string
+		setupMocks    func(a *MockAuthenticationServer)
+		expectedCode  int
+		expectedRoute string
+		expectedLevel string
+	}{
+		{
+			name:      "request ID from header",
+			path:      "/v1/auth/verify",
+			requestID: "req-123",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("VerifyToken", mock.Anything, mock.Anything).Return(&authpb.VerifyTokenResponse{Message: constant.MessageOK}, nil)
+			},
+			expectedCode:  http.StatusOK,
+			expectedRoute: "POST /v1/auth/verify",
+			expectedLevel: "info",
+		},
+		{
+			name: "handler panic",
+			path: "/v1/auth/verify",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("VerifyToken", mock.Anything, mock.Anything).Run(func(mock.Arguments) { panic("boom") })
+			},
+			expectedCode:  http.StatusInternalServerError,
+			expectedRoute: "POST /v1/auth/verify",
+			expectedLevel: "error",
+		},
+		{
+			name:          "unmatched path",
+			path:          "/nope",
+			setupMocks:    func(a *MockAuthenticationServer) {},
+			expectedCode:  http.StatusNotFound,
+			expectedRoute: "unmatched",
+			expectedLevel: "info",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := metricstest.New(t)
+			buf := captureLogs(t)
+			a := new(MockAuthenticationServer)
+			tt.setupMocks(a)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			if tt.requestID != "" {
+				req.Header.Set(constant.HeaderRequestID, tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+			server.NewServer("", a, &config.ForwardAuth{}).Handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			requestID := rec.Header().Get(constant.HeaderRequestID)
+			assert.NotEmpty(t, requestID)
+			if tt.requestID != "" {
+				assert.Equal(t, tt.requestID, requestID)
+			}
+
+			// Every line of the request, the recovered panic included, carries
+			// the request ID, and the access log line comes last.
+			lines := logLines(t, buf)
+			require.NotEmpty(t, lines)
+			for _, line := range lines {
+				assert.Equal(t, requestID, line[logger.FieldRequestID])
+			}
+			last := lines[len(lines)-1]
+			assert.Equal(t, tt.expectedLevel, last["level"])
+			assert.Equal(t, float64(tt.expectedCode), last["http_status"])
+			assert.Equal(t, tt.path, last[logger.FieldHTTPPath])
+
+			assert.Equal(t, 1.0, reader.Value(t, "http_requests_total",
+				metrics.RouteKey.String(tt.expectedRoute), metrics.StatusKey.String(strconv.Itoa(tt.expectedCode))))
+			a.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// OAuthHandler exposes the OAuth 2.0 endpoints over their standard
+// form-encoded HTTP wire format, delegating to the gRPC implementation.
+type OAuthHandler struct {
+	AuthServer authpb.AuthenticationServiceServer
+}
+
+type deviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+type tokenResponse struct {
+	AccessToken     string `json:"access_token"`
+	TokenType       string `json:"token_type"`
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
+	ExpiresIn       int64  `json:"expires_in,omitempty"`
+	Scope           string `json:"scope,omitempty"`
+}
+
+type errorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// oauthErrors are the error codes the gRPC handlers put in status messages
+// for callers to relay as is.
+var oauthErrors = []string{
+	constant.OAuthErrorInvalidRequest,
+	constant.OAuthErrorInvalidGrant,
+	constant.OAuthErrorInvalidClient,
+	constant.OAuthErrorUnsupportedGrantType,
+	constant.OAuthErrorInvalidScope,
+	constant.OAuthErrorInvalidTarget,
+	constant.OAuthErrorAuthorizationPending,
+	constant.OAuthErrorSlowDown,
+	constant.OAuthErrorAccessDenied,
+	constant.OAuthErrorExpiredToken,
+}
+
+func (o *OAuthHandler) DeviceAuthorization(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, constant.OAuthErrorInvalidRequest, "")
+		return
+	}
+
+	res, err := o.AuthServer.DeviceAuthorization(r.Context(), &authpb.DeviceAuthorizationRequest{
+		ClientId: r.PostForm.Get("client_id"),
+		Scope:    r.PostForm.Get("scope"),
+	})
+	if err != nil {
+		writeOAuthStatusError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &deviceAuthorizationResponse{
+		DeviceCode:              res.Data.DeviceCode,
+		UserCode:                res.Data.UserCode,
+		VerificationURI:         res.Data.VerificationUri,
+		VerificationURIComplete: res.Data.VerificationUriComplete,
+		ExpiresIn:               res.Data.ExpiresIn,
+		Interval:                res.Data.Interval,
+	})
+}
+
+func (o *OAuthHandler) Token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, constant.OAuthErrorInvalidRequest, "")
+		return
+	}
+
+	switch r.PostForm.Get("grant_type") {
+	case constant.GrantTypeDeviceCode:
+		o.deviceCodeGrant(w, r)
+	case constant.GrantTypeTokenExchange:
+		o.tokenExchangeGrant(w, r)
+	default:
+		writeOAuthError(w, http.StatusBadRequest, constant.OAuthErrorUnsupportedGrantType, "")
+	}
+}
+
+func (o *OAuthHandler) deviceCodeGrant(w http.ResponseWriter, r *http.Request) {
+	res, err := o.AuthServer.DeviceToken(r.Context(), &authpb.DeviceTokenRequest{
+		DeviceCode: r.PostForm.Get("device_code"),
+		ClientId:   r.PostForm.Get("client_id"),
+	})
+	if err != nil {
+		writeOAuthStatusError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &tokenResponse{
+		AccessToken: res.Data.Token,
+		TokenType:   "Bearer",
+	})
+}
+
+func (o *OAuthHandler) tokenExchangeGrant(w http.ResponseWriter, r *http.Request) {
+	res, err := o.AuthServer.ExchangeToken(r.Context(), &authpb.ExchangeTokenRequest{
+		SubjectToken:       r.PostForm.Get("subject_token"),
+		SubjectTokenType:   r.PostForm.Get("subject_token_type"),
+		ActorToken:         r.PostForm.Get("actor_token"),
+		ActorTokenType:     r.PostForm.Get("actor_token_type"),
+		Audience:           r.PostForm.Get("audience"),
+		Scope:              r.PostForm.Get("scope"),
+		RequestedTokenType: r.PostForm.Get("requested_token_type"),
+	})
+	if err != nil {
+		writeOAuthStatusError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &tokenResponse{
+		AccessToken:     res.Data.AccessToken,
+		TokenType:       "Bearer",
+		IssuedTokenType: res.Data.IssuedTokenType,
+		ExpiresIn:       res.Data.ExpiresIn,
+		Scope:           res.Data.Scope,
+	})
+}
+
+// writeOAuthStatusError relays the OAuth error code carried in the gRPC status
+// message. Any other status is mapped to the closest RFC 6749 section 5.2
+// code by its gRPC code, with the message as error_description. Token errors
+// are 400s, except a failed client authentication which is a 401 and failures
+// on our side which are reported as server_error.
+func writeOAuthStatusError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.Internal, codes.Unknown, codes.Unavailable, codes.DeadlineExceeded:
+		logger.Error("OAuth request failed: %v", err)
+		writeOAuthError(w, http.StatusInternalServerError, constant.OAuthErrorServerError, "")
+		return
+	}
+
+	if slices.Contains(oauthErrors, st.Message()) {
+		writeOAuthError(w, http.StatusBadRequest, st.Message(), "")
+		return
+	}
+
+	switch st.Code() {
+	case codes.Unauthenticated:
+		writeOAuthError(w, http.StatusUnauthorized, constant.OAuthErrorInvalidClient, st.Message())
+	case codes.NotFound, codes.PermissionDenied, codes.FailedPrecondition:
+		writeOAuthError(w, http.StatusBadRequest, constant.OAuthErrorInvalidGrant, st.Message())
+	default:
+		writeOAuthError(w, http.StatusBadRequest, constant.OAuthErrorInvalidRequest, st.Message())
+	}
+}
+
+func writeOAuthError(w http.ResponseWriter, code int, oauthErr string, description string) {
+	writeJSON(w, code, &errorResponse{Error: oauthErr, ErrorDescription: description})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Error("Failed to write HTTP response: %v", err)
+	}
+}
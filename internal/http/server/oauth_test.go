@@ -0,0 +1,251 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/http/server"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func postForm(t *testing.T, h http.Handler, path string, form url.Values) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestOAuthHandler_DeviceAuthorization(t *testing.T) {
+	tests := []struct {
+		name         string
+		setupMocks   func(a *MockAuthenticationServer)
+		form         url.Values
+		expectedCode int
+		expectedBody map[string]any
+	}{
+		{
+			name: "success",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("DeviceAuthorization", mock.Anything, mock.MatchedBy(func(in *authpb.DeviceAuthorizationRequest) bool {
+					return in.ClientId == "tv-app" && in.Scope == "profile"
+				})).Return(&authpb.DeviceAuthorizationResponse{
+					Message: constant.MessageOK,
+					Data: &authpb.DeviceAuthorizationResponseData{
+						DeviceCode:      "device123",
+						UserCode:        "BCDF-GHJK",
+						VerificationUri: "https://example.com/device",
+						ExpiresIn:       600,
+						Interval:        5,
+					},
+				}, nil)
+			},
+			form:         url.Values{"client_id": {"tv-app"}, "scope": {"profile"}},
+			expectedCode: http.StatusOK,
+			expectedBody: map[string]any{
+				"device_code":      "device123",
+				"user_code":        "BCDF-GHJK",
+				"verification_uri": "https://example.com/device",
+				"expires_in":       float64(600),
+				"interval":         float64(5),
+			},
+		},
+		{
+			name: "missing client id",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("DeviceAuthorization", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.InvalidArgument, constant.OAuthErrorInvalidRequest))
+			},
+			form:         url.Values{},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: map[string]any{"error": constant.OAuthErrorInvalidRequest},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := new(MockAuthenticationServer)
+			tt.setupMocks(a)
+
+			s := server.NewServer("", a, &config.ForwardAuth{})
+			rec := postForm(t, s.Handler, "/oauth/device_authorization", tt.form)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			body := map[string]any{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedBody, body)
+			a.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOAuthHandler_Token(t *testing.T) {
+	deviceForm := url.Values{
+		"grant_type":  {constant.GrantTypeDeviceCode},
+		"device_code": {"device123"},
+		"client_id":   {"tv-app"},
+	}
+
+	tests := []struct {
+		name         string
+		setupMocks   func(a *MockAuthenticationServer)
+		form         url.Values
+		expectedCode int
+		expectedBody map[string]any
+	}{
+		{
+			name: "device code approved",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("DeviceToken", mock.Anything, mock.MatchedBy(func(in *authpb.DeviceTokenRequest) bool {
+					return in.DeviceCode == "device123" && in.ClientId == "tv-app"
+				})).Return(&authpb.DeviceTokenResponse{
+					Message: constant.MessageOK,
+					Data:    &authpb.DeviceTokenResponseData{Token: "token123"},
+				}, nil)
+			},
+			form:         deviceForm,
+			expectedCode: http.StatusOK,
+			expectedBody: map[string]any{"access_token": "token123", "token_type": "Bearer"},
+		},
+		{
+			name: "authorization pending",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("DeviceToken", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.FailedPrecondition, constant.OAuthErrorAuthorizationPending))
+			},
+			form:         deviceForm,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: map[string]any{"error": constant.OAuthErrorAuthorizationPending},
+		},
+		{
+			name: "slow down",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("DeviceToken", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.ResourceExhausted, constant.OAuthErrorSlowDown))
+			},
+			form:         deviceForm,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: map[string]any{"error": constant.OAuthErrorSlowDown},
+		},
+		{
+			name: "internal failure",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("DeviceToken", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.Internal, constant.OAuthErrorServerError))
+			},
+			form:         deviceForm,
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: map[string]any{"error": constant.OAuthErrorServerError},
+		},
+		{
+			name: "token exchange",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("ExchangeToken", mock.Anything, &authpb.ExchangeTokenRequest{
+					SubjectToken:     "subject-token",
+					SubjectTokenType: constant.TokenTypeAccessToken,
+					Audience:         "video-service",
+					Scope:            "videos:read",
+				}).Return(&authpb.ExchangeTokenResponse{
+					Message: constant.MessageOK,
+					Data: &authpb.ExchangeTokenResponseData{
+						AccessToken:     "exchanged",
+						IssuedTokenType: constant.TokenTypeAccessToken,
+						ExpiresIn:       300,
+						Scope:           "videos:read",
+					},
+				}, nil)
+			},
+			form: url.Values{
+				"grant_type":         {constant.GrantTypeTokenExchange},
+				"subject_token":      {"subject-token"},
+				"subject_token_type": {constant.TokenTypeAccessToken},
+				"audience":           {"video-service"},
+				"scope":              {"videos:read"},
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: map[string]any{
+				"access_token":      "exchanged",
+				"token_type":        "Bearer",
+				"issued_token_type": constant.TokenTypeAccessToken,
+				"expires_in":        float64(300),
+				"scope":             "videos:read",
+			},
+		},
+		{
+			name: "token exchange with escalated scope",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("ExchangeToken", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.InvalidArgument, constant.OAuthErrorInvalidScope))
+			},
+			form:         url.Values{"grant_type": {constant.GrantTypeTokenExchange}},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: map[string]any{"error": constant.OAuthErrorInvalidScope},
+		},
+		{
+			name: "status without an oauth error code",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("ExchangeToken", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.InvalidArgument, "audience is required"))
+			},
+			form:         url.Values{"grant_type": {constant.GrantTypeTokenExchange}},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: map[string]any{"error": constant.OAuthErrorInvalidRequest, "error_description": "audience is required"},
+		},
+		{
+			name: "unknown grant",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("DeviceToken", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.NotFound, constant.MessageNotFound))
+			},
+			form:         deviceForm,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: map[string]any{"error": constant.OAuthErrorInvalidGrant, "error_description": constant.MessageNotFound},
+		},
+		{
+			name: "unauthenticated client",
+			setupMocks: func(a *MockAuthenticationServer) {
+				a.On("ExchangeToken", mock.Anything, mock.Anything).
+					Return(nil, status.Error(codes.Unauthenticated, constant.MessageUnauthorized))
+			},
+			form:         url.Values{"grant_type": {constant.GrantTypeTokenExchange}},
+			expectedCode: http.StatusUnauthorized,
+			expectedBody: map[string]any{"error": constant.OAuthErrorInvalidClient, "error_description": constant.MessageUnauthorized},
+		},
+		{
+			name:         "unsupported grant type",
+			setupMocks:   func(a *MockAuthenticationServer) {},
+			form:         url.Values{"grant_type": {"password"}},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: map[string]any{"error": constant.OAuthErrorUnsupportedGrantType},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := new(MockAuthenticationServer)
+			tt.setupMocks(a)
+
+			s := server.NewServer("", a, &config.ForwardAuth{})
+			rec := postForm(t, s.Handler, "/oauth/token", tt.form)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+			body := map[string]any{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedBody, body)
+			a.AssertExpectations(t)
+		})
+	}
+}
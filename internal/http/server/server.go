@@ -0,0 +1,48 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+)
+
+func NewServer(url string, authServer authpb.AuthenticationServiceServer, forwardAuthCfg *config.ForwardAuth) *http.Server {
+	oauth := &OAuthHandler{AuthServer: authServer}
+	gateway := &GatewayHandler{AuthServer: authServer}
+	forwardAuth := NewForwardAuthHandler(forwardAuthCfg, authServer)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /oauth/device_authorization", oauth.DeviceAuthorization)
+	mux.HandleFunc("POST /oauth/token", oauth.Token)
+	mux.HandleFunc("POST /v1/auth/register", gateway.Register)
+	mux.HandleFunc("POST /v1/auth/login", gateway.Login)
+	mux.HandleFunc("POST /v1/auth/verify", gateway.Verify)
+	mux.HandleFunc("POST /v1/auth/logout", gateway.Logout)
+	mux.HandleFunc("GET /auth/forward", forwardAuth.Forward)
+
+	// Picks up the caller's trace context so spans from the in-process gRPC
+	// handlers and downstream user service calls join the incoming trace.
+	handler := otelhttp.NewHandler(withMiddleware(mux), "http-server",
+		otelhttp.WithTracerProvider(otel.GetTracerProvider()),
+		otelhttp.WithPropagators(otel.GetTextMapPropagator()),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + r.URL.Path
+		}),
+	)
+
+	return &http.Server{Addr: url, Handler: handler}
+}
+
+func Serve(server *http.Server, listen func() error) error {
+	logger.Info("Starting HTTP server %s", server.Addr)
+
+	if err := listen(); err != nil && err != http.ErrServerClosed {
+		logger.Error("HTTP server error! %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package server_test
+
+import (
+	"context"
+
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"github.com/stretchr/testify/mock"
+)
+
+// ===== Mock Authentication Server =====
+type MockAuthenticationServer struct {
+	mock.Mock
+	authpb.UnimplementedAuthenticationServiceServer
+}
+
+func (m *MockAuthenticationServer) DeviceAuthorization(ctx context.Context, in *authpb.DeviceAuthorizationRequest) (*authpb.DeviceAuthorizationResponse, error) {
+	args := m.Called(ctx, in)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(*authpb.DeviceAuthorizationResponse), nil
+}
+
+func (m *MockAuthenticationServer) DeviceToken(ctx context.Context, in *authpb.DeviceTokenRequest) (*authpb.DeviceTokenResponse, error) {
+	args := m.Called(ctx, in)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(*authpb.DeviceTokenResponse), nil
+}
+
+func (m *MockAuthenticationServer) ExchangeToken(ctx context.Context, in *authpb.ExchangeTokenRequest) (*authpb.ExchangeTokenResponse, error) {
+	args := m.Called(ctx, in)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(*authpb.ExchangeTokenResponse), nil
+}
+
+func (m *MockAuthenticationServer) Register(ctx context.Context, in *authpb.RegisterRequest) (*authpb.RegisterResponse, error) {
+	args := m.Called(ctx, in)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(*authpb.RegisterResponse), nil
+}
+
+func (m *MockAuthenticationServer) Login(ctx context.Context, in *authpb.LoginRequest) (*authpb.LoginResponse, error) {
+	args := m.Called(ctx, in)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(*authpb.LoginResponse), nil
+}
+
+func (m *MockAuthenticationServer) VerifyToken(ctx context.Context, in *authpb.VerifyTokenRequest) (*authpb.VerifyTokenResponse, error) {
+	args := m.Called(ctx, in)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(*authpb.VerifyTokenResponse), nil
+}
+
+func (m *MockAuthenticationServer) Logout(ctx context.Context, in *authpb.LogoutRequest) (*authpb.LogoutResponse, error) {
+	args := m.Called(ctx, in)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).(*authpb.LogoutResponse), nil
+}
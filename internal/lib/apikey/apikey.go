@@ -0,0 +1,258 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Refreshing LastUsedAt on every request would turn each verification into a
+// Redis write, so it is only bumped once per interval.
+const lastUsedResolution = time.Minute
+
+var (
+	ErrInvalidKey    = errors.New("invalid or expired api key")
+	ErrNotFound      = errors.New("api key not found")
+	ErrInvalidExpiry = errors.New("api key expiry is missing or beyond the maximum")
+)
+
+type APIKey struct {
+	ID         string   `json:"id"`
+	UserID     uint     `json:"user_id"`
+	Username   string   `json:"username"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes,omitempty"`
+	Hash       string   `json:"hash"`
+	CreatedAt  int64    `json:"created_at"`
+	ExpiresAt  int64    `json:"expires_at,omitempty"`
+	LastUsedAt int64    `json:"last_used_at,omitempty"`
+}
+
+type APIKeyManager interface {
+	Create(ctx context.Context, userID uint, username string, name string, scopes []string, expiresIn time.Duration) (string, *APIKey, error)
+	List(ctx context.Context, userID uint) ([]*APIKey, error)
+	Revoke(ctx context.Context, userID uint, id string) error
+	Verify(ctx context.Context, key string) (*APIKey, error)
+}
+
+type apiKeyManager struct {
+	maxExpiry time.Duration
+	redis     redis.RedisService
+}
+
+func NewAPIKeyManager(cfg *config.APIKey, redis redis.RedisService) APIKeyManager {
+	return &apiKeyManager{
+		maxExpiry: cfg.MaxExpiry,
+		redis:     redis,
+	}
+}
+
+// IsAPIKey tells API keys apart from JWTs in the authorization header.
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, constant.APIKeyPrefix)
+}
+
+// Create returns the plaintext key, which is never stored and can't be
+// recovered afterwards, together with its metadata. Every key expires, at
+// most after the configured maximum.
+func (a *apiKeyManager) Create(ctx context.Context, userID uint, username string, name string, scopes []string, expiresIn time.Duration) (string, *APIKey, error) {
+	if expiresIn <= 0 || expiresIn > a.maxExpiry {
+		return "", nil, ErrInvalidExpiry
+	}
+
+	id, err := randomString(8, hex.EncodeToString)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomString(32, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", nil, err
+	}
+	key := fmt.Sprintf("%s%s_%s", constant.APIKeyPrefix, id, secret)
+
+	now := time.Now()
+	apiKey := &APIKey{
+		ID:        id,
+		UserID:    userID,
+		Username:  username,
+		Name:      name,
+		Scopes:    scopes,
+		Hash:      hash(key),
+		CreatedAt: now.Unix(),
+		ExpiresAt: now.Add(expiresIn).Unix(),
+	}
+
+	if err := a.save(ctx, apiKey); err != nil {
+		return "", nil, err
+	}
+	if err := a.redis.SAdd(ctx, userKeysKey(userID), id); err != nil {
+		return "", nil, err
+	}
+	metrics.TokensIssued.Add(ctx, 1, metric.WithAttributes(metrics.TypeKey.String(constant.MetricTokenAPIKey)))
+
+	return key, apiKey, nil
+}
+
+func (a *apiKeyManager) List(ctx context.Context, userID uint) ([]*APIKey, error) {
+	ids, err := a.redis.SMembers(ctx, userKeysKey(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*APIKey, 0, len(ids))
+	for _, id := range ids {
+		apiKey, err := a.find(ctx, id)
+		if errors.Is(err, redis.Nil) {
+			// Expired keys drop out of Redis on their own, tidy up the index.
+			_ = a.redis.SRem(ctx, userKeysKey(userID), id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, apiKey)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt < keys[j].CreatedAt })
+	return keys, nil
+}
+
+func (a *apiKeyManager) Revoke(ctx context.Context, userID uint, id string) error {
+	apiKey, err := a.find(ctx, id)
+	if errors.Is(err, redis.Nil) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if apiKey.UserID != userID {
+		return ErrNotFound
+	}
+
+	if err := a.redis.Del(ctx, apiKeyKey(id)); err != nil {
+		return err
+	}
+	metrics.TokensRevoked.Add(ctx, 1, metric.WithAttributes(metrics.TypeKey.String(constant.MetricTokenAPIKey)))
+	return a.redis.SRem(ctx, userKeysKey(userID), id)
+}
+
+func (a *apiKeyManager) Verify(ctx context.Context, key string) (*APIKey, error) {
+	id, _, ok := strings.Cut(strings.TrimPrefix(key, constant.APIKeyPrefix), "_")
+	if !IsAPIKey(key) || !ok {
+		return nil, ErrInvalidKey
+	}
+
+	apiKey, err := a.find(ctx, id)
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(apiKey.Hash), []byte(hash(key))) != 1 {
+		return nil, ErrInvalidKey
+	}
+
+	now := time.Now()
+	if apiKey.ExpiresAt != 0 && apiKey.ExpiresAt <= now.Unix() {
+		return nil, ErrInvalidKey
+	}
+	if now.Unix()-apiKey.LastUsedAt >= int64(lastUsedResolution.Seconds()) {
+		apiKey.LastUsedAt = now.Unix()
+		_ = a.touch(ctx, id, now)
+	}
+
+	return apiKey, nil
+}
+
+// touch records the last use of a key. It only rewrites a key that still
+// exists, in a transaction, so a concurrent Revoke can't be undone by it.
+func (a *apiKeyManager) touch(ctx context.Context, id string, now time.Time) error {
+	return a.redis.Update(ctx, apiKeyKey(id), func(val string) (string, time.Duration, error) {
+		apiKey := &APIKey{}
+		if err := json.Unmarshal([]byte(val), apiKey); err != nil {
+			return "", 0, err
+		}
+		apiKey.LastUsedAt = now.Unix()
+		return encode(apiKey)
+	})
+}
+
+func (a *apiKeyManager) find(ctx context.Context, id string) (*APIKey, error) {
+	val, err := a.redis.Get(ctx, apiKeyKey(id))
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := &APIKey{}
+	if err := json.Unmarshal([]byte(val), apiKey); err != nil {
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+func (a *apiKeyManager) save(ctx context.Context, apiKey *APIKey) error {
+	val, ttl, err := encode(apiKey)
+	if err != nil {
+		return err
+	}
+
+	return a.redis.Set(ctx, apiKeyKey(apiKey.ID), val, ttl)
+}
+
+// encode returns the stored form of apiKey and how long it has left, none
+// for keys created without an expiry.
+func encode(apiKey *APIKey) (string, time.Duration, error) {
+	var ttl time.Duration
+	if apiKey.ExpiresAt != 0 {
+		ttl = time.Until(time.Unix(apiKey.ExpiresAt, 0))
+		if ttl <= 0 {
+			return "", 0, ErrInvalidKey
+		}
+	}
+
+	val, err := json.Marshal(apiKey)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return string(val), ttl, nil
+}
+
+func hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(n int, encode func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encode(b), nil
+}
+
+func apiKeyKey(id string) string {
+	return fmt.Sprintf("%s:%s", constant.RedisAPIKey, id)
+}
+
+func userKeysKey(userID uint) string {
+	return fmt.Sprintf("%s:%d", constant.RedisUserAPIKeys, userID)
+}
@@ -0,0 +1,258 @@
+package apikey_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/apikey"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis/redistest"
+)
+
+const userKeysKey = constant.RedisUserAPIKeys + ":1"
+
+var cfg = &config.APIKey{MaxExpiry: 24 * time.Hour}
+
+func encode(t *testing.T, k *apikey.APIKey) string {
+	t.Helper()
+	b, err := json.Marshal(k)
+	require.NoError(t, err)
+	return string(b)
+}
+
+// create issues a key against a throwaway mock and returns the plaintext key
+// together with the record that would have been written to Redis.
+func create(t *testing.T, expiresIn time.Duration) (string, *apikey.APIKey) {
+	t.Helper()
+	mockRedis := new(redistest.Client)
+	mockRedis.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRedis.On("SAdd", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	key, record, err := apikey.NewAPIKeyManager(cfg, mockRedis).Create(context.Background(), 1, "john", "ci", []string{"videos:read"}, expiresIn)
+	require.NoError(t, err)
+	return key, record
+}
+
+func TestAPIKeyManager_Create(t *testing.T) {
+	mockRedis := new(redistest.Client)
+	manager := apikey.NewAPIKeyManager(cfg, mockRedis)
+
+	var stored string
+	mockRedis.On("Set", mock.Anything, mock.MatchedBy(func(k string) bool {
+		return strings.HasPrefix(k, constant.RedisAPIKey+":")
+	}), mock.Anything, mock.MatchedBy(func(ttl time.Duration) bool {
+		return ttl > 59*time.Minute && ttl <= time.Hour
+	})).Run(func(args mock.Arguments) {
+		stored = args.String(2)
+	}).Return(nil).Once()
+	mockRedis.On("SAdd", mock.Anything, userKeysKey, mock.Anything).Return(nil).Once()
+
+	key, record, err := manager.Create(context.Background(), 1, "john", "ci", []string{"videos:read"}, time.Hour)
+	require.NoError(t, err)
+
+	assert.True(t, apikey.IsAPIKey(key))
+	assert.True(t, strings.HasPrefix(key, constant.APIKeyPrefix+record.ID+"_"))
+	assert.Equal(t, uint(1), record.UserID)
+	assert.Equal(t, "ci", record.Name)
+	assert.Equal(t, []string{"videos:read"}, record.Scopes)
+	assert.NotZero(t, record.ExpiresAt)
+	assert.NotContains(t, stored, key)
+	assert.Contains(t, stored, record.Hash)
+	mockRedis.AssertExpectations(t)
+}
+
+func TestAPIKeyManager_Create_InvalidExpiry(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiresIn time.Duration
+	}{
+		{name: "without expiry", expiresIn: 0},
+		{name: "beyond the maximum", expiresIn: cfg.MaxExpiry + time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRedis := new(redistest.Client)
+
+			_, _, err := apikey.NewAPIKeyManager(cfg, mockRedis).Create(context.Background(), 1, "john", "ci", nil, tt.expiresIn)
+			assert.ErrorIs(t, err, apikey.ErrInvalidExpiry)
+			mockRedis.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPIKeyManager_Verify(t *testing.T) {
+	key, record := create(t, time.Hour)
+	recordKey := constant.RedisAPIKey + ":" + record.ID
+
+	recentlyUsed := *record
+	recentlyUsed.LastUsedAt = time.Now().Unix()
+
+	expired := *record
+	expired.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+
+	tests := []struct {
+		name       string
+		key        string
+		setupMocks func(r *redistest.Client)
+		expectErr  error
+	}{
+		{
+			name: "valid key records last use",
+			key:  key,
+			setupMocks: func(r *redistest.Client) {
+				r.On("Get", mock.Anything, recordKey).Return(encode(t, record), nil)
+				r.On("Set", mock.Anything, recordKey, mock.MatchedBy(func(v string) bool {
+					return strings.Contains(v, `"last_used_at"`)
+				}), mock.MatchedBy(func(ttl time.Duration) bool { return ttl > 0 })).Return(nil).Once()
+			},
+		},
+		{
+			name: "key revoked while verifying is not written back",
+			key:  key,
+			setupMocks: func(r *redistest.Client) {
+				r.On("Get", mock.Anything, recordKey).Return(encode(t, record), nil).Once()
+				r.On("Get", mock.Anything, recordKey).Return("", redis.Nil).Once()
+			},
+		},
+		{
+			name: "recently used key is not rewritten",
+			key:  key,
+			setupMocks: func(r *redistest.Client) {
+				r.On("Get", mock.Anything, recordKey).Return(encode(t, &recentlyUsed), nil)
+			},
+		},
+		{
+			name: "wrong secret",
+			key:  constant.APIKeyPrefix + record.ID + "_wrong",
+			setupMocks: func(r *redistest.Client) {
+				r.On("Get", mock.Anything, recordKey).Return(encode(t, record), nil)
+			},
+			expectErr: apikey.ErrInvalidKey,
+		},
+		{
+			name: "revoked key",
+			key:  key,
+			setupMocks: func(r *redistest.Client) {
+				r.On("Get", mock.Anything, recordKey).Return("", redis.Nil)
+			},
+			expectErr: apikey.ErrInvalidKey,
+		},
+		{
+			name: "expired key",
+			key:  key,
+			setupMocks: func(r *redistest.Client) {
+				r.On("Get", mock.Anything, recordKey).Return(encode(t, &expired), nil)
+			},
+			expectErr: apikey.ErrInvalidKey,
+		},
+		{
+			name:       "malformed key",
+			key:        "not-an-api-key",
+			setupMocks: func(r *redistest.Client) {},
+			expectErr:  apikey.ErrInvalidKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRedis := new(redistest.Client)
+			tt.setupMocks(mockRedis)
+
+			got, err := apikey.NewAPIKeyManager(cfg, mockRedis).Verify(context.Background(), tt.key)
+
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, record.ID, got.ID)
+				assert.Equal(t, "john", got.Username)
+			}
+			mockRedis.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAPIKeyManager_List(t *testing.T) {
+	_, first := create(t, time.Hour)
+	_, second := create(t, time.Hour)
+	first.CreatedAt, second.CreatedAt = 100, 200
+
+	mockRedis := new(redistest.Client)
+	mockRedis.On("SMembers", mock.Anything, userKeysKey).Return([]string{second.ID, "gone", first.ID}, nil)
+	mockRedis.On("Get", mock.Anything, constant.RedisAPIKey+":"+first.ID).Return(encode(t, first), nil)
+	mockRedis.On("Get", mock.Anything, constant.RedisAPIKey+":"+second.ID).Return(encode(t, second), nil)
+	mockRedis.On("Get", mock.Anything, constant.RedisAPIKey+":gone").Return("", redis.Nil)
+	mockRedis.On("SRem", mock.Anything, userKeysKey, []string{"gone"}).Return(nil).Once()
+
+	keys, err := apikey.NewAPIKeyManager(cfg, mockRedis).List(context.Background(), 1)
+	require.NoError(t, err)
+
+	require.Len(t, keys, 2)
+	assert.Equal(t, first.ID, keys[0].ID)
+	assert.Equal(t, second.ID, keys[1].ID)
+	mockRedis.AssertExpectations(t)
+}
+
+func TestAPIKeyManager_Revoke(t *testing.T) {
+	_, record := create(t, time.Hour)
+	recordKey := constant.RedisAPIKey + ":" + record.ID
+
+	tests := []struct {
+		name       string
+		userID     uint
+		setupMocks func(r *redistest.Client)
+		expectErr  error
+	}{
+		{
+			name:   "owner revokes key",
+			userID: 1,
+			setupMocks: func(r *redistest.Client) {
+				r.On("Get", mock.Anything, recordKey).Return(encode(t, record), nil)
+				r.On("Del", mock.Anything, recordKey).Return(nil).Once()
+				r.On("SRem", mock.Anything, userKeysKey, []string{record.ID}).Return(nil).Once()
+			},
+		},
+		{
+			name:   "other user's key",
+			userID: 2,
+			setupMocks: func(r *redistest.Client) {
+				r.On("Get", mock.Anything, recordKey).Return(encode(t, record), nil)
+			},
+			expectErr: apikey.ErrNotFound,
+		},
+		{
+			name:   "unknown key",
+			userID: 1,
+			setupMocks: func(r *redistest.Client) {
+				r.On("Get", mock.Anything, recordKey).Return("", redis.Nil)
+			},
+			expectErr: apikey.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRedis := new(redistest.Client)
+			tt.setupMocks(mockRedis)
+
+			err := apikey.NewAPIKeyManager(cfg, mockRedis).Revoke(context.Background(), tt.userID, record.ID)
+
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRedis.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/helper"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/certs"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Event types, one per AuthenticationService RPC
+const (
+	EventRegister               = "register"
+	EventLogin                  = "login"
+	EventVerifyToken            = "verify_token"
+	EventLogout                 = "logout"
+	EventDeviceAuthorization    = "device_authorization"
+	EventVerifyDevice           = "verify_device"
+	EventDeviceToken            = "device_token"
+	EventStartFederatedLogin    = "start_federated_login"
+	EventCompleteFederatedLogin = "complete_federated_login"
+	EventExchangeToken          = "exchange_token"
+	EventCreateAPIKey           = "create_api_key"
+	EventListAPIKeys            = "list_api_keys"
+	EventRevokeAPIKey           = "revoke_api_key"
+	EventAuthorize              = "authorize"
+)
+
+// Event outcomes
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied"
+)
+
+// ReasonUserDenied marks a device login the user turned down.
+const ReasonUserDenied = "user_denied"
+
+// Event is one audit record. Emails are only ever stored hashed.
+type Event struct {
+	Type      string `json:"type"`
+	Outcome   string `json:"outcome"`
+	Reason    string `json:"reason,omitempty"`
+	UserID    int    `json:"user_id,omitempty"`
+	EmailHash string `json:"email_hash,omitempty"`
+	// PeerIP is the address of the connection. ClientIP is the same unless
+	// the peer is a trusted proxy, in which case it is taken from
+	// x-forwarded-for.
+	PeerIP   string `json:"peer_ip,omitempty"`
+	ClientIP string `json:"client_ip,omitempty"`
+	// PeerIdentity names the mTLS client certificate the call was made with.
+	PeerIdentity string    `json:"peer_identity,omitempty"`
+	UserAgent    string    `json:"user_agent,omitempty"`
+	TraceID      string    `json:"trace_id,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+
+	forwardedFor []string
+}
+
+// NewEvent starts an event of the given type with the caller details found in
+// ctx. x-forwarded-for is kept aside until the auditor knows whether the peer
+// is a proxy it trusts.
+func NewEvent(ctx context.Context, eventType string) *Event {
+	e := &Event{Type: eventType, Timestamp: time.Now().UTC()}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	e.UserAgent, _ = helper.GetGRPCMetadataValue(md, "user-agent")
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		e.PeerIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(e.PeerIP); err == nil {
+			e.PeerIP = host
+		}
+	}
+	e.ClientIP = e.PeerIP
+	e.PeerIdentity, _ = certs.PeerIdentity(ctx)
+	for _, forwarded := range md.Get("x-forwarded-for") {
+		for _, addr := range strings.Split(forwarded, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				e.forwardedFor = append(e.forwardedFor, addr)
+			}
+		}
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		e.TraceID = sc.TraceID().String()
+	}
+
+	return e
+}
+
+// Finish sets the outcome from the RPC error unless one was already set, using
+// the gRPC status code as the failure reason when none was given.
+func (e *Event) Finish(err error) *Event {
+	if e.Outcome == "" {
+		e.Outcome = OutcomeSuccess
+		if err != nil {
+			e.Outcome = OutcomeFailure
+		}
+	}
+	if err != nil && e.Reason == "" {
+		e.Reason = status.Code(err).String()
+	}
+	return e
+}
+
+// HashEmail returns a SHA-256 hex digest of the normalized email, so events
+// for the same address can be correlated without storing it.
+func HashEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(email))
+	return hex.EncodeToString(sum[:])
+}
+
+// Sink persists audit events. Write is called from a single goroutine.
+type Sink interface {
+	Write(ctx context.Context, event *Event) error
+	Close() error
+}
+
+type Auditor interface {
+	// Record queues event without blocking. When the buffer is full the
+	// event is dropped and counted, so auditing never slows down requests.
+	// Events recorded after Close are dropped.
+	Record(event *Event)
+	// Close drains the buffer into the sinks until ctx is done.
+	Close(ctx context.Context) error
+}
+
+type auditor struct {
+	events         chan *Event
+	sinks          []Sink
+	trustedProxies []*net.IPNet
+	done           chan struct{}
+	mu             sync.RWMutex
+	closed         bool
+	dropped        atomic.Uint64
+}
+
+// NewAuditor starts writing recorded events to sinks in the background. It
+// fails on a malformed cfg.TrustedProxies entry.
+func NewAuditor(cfg *config.Audit, sinks ...Sink) (Auditor, error) {
+	trustedProxies, err := parseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &auditor{
+		events:         make(chan *Event, max(cfg.BufferSize, 1)),
+		sinks:          sinks,
+		trustedProxies: trustedProxies,
+		done:           make(chan struct{}),
+	}
+	go a.run()
+	return a, nil
+}
+
+// parseTrustedProxies accepts CIDRs and bare IP addresses.
+func parseTrustedProxies(entries []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+func (a *auditor) trusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range a.trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP follows x-forwarded-for back from the peer while each hop is a
+// trusted proxy and returns the first address that isn't. Addresses added by
+// untrusted hops could be anything the client chose to send.
+func (a *auditor) clientIP(event *Event) string {
+	if !a.trusted(event.PeerIP) {
+		return event.PeerIP
+	}
+	client := event.PeerIP
+	for i := len(event.forwardedFor) - 1; i >= 0; i-- {
+		client = event.forwardedFor[i]
+		if !a.trusted(client) {
+			break
+		}
+	}
+	return client
+}
+
+func (a *auditor) Record(event *Event) {
+	event.ClientIP = a.clientIP(event)
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return
+	}
+
+	select {
+	case a.events <- event:
+	default:
+		metrics.AuditEventsDropped.Add(context.Background(), 1)
+		// Warn on the first drop and then every 1000th to avoid flooding.
+		if n := a.dropped.Add(1); n%1000 == 1 {
+			logger.Warn("Audit buffer full, %d events dropped so far", n)
+		}
+	}
+}
+
+func (a *auditor) run() {
+	defer close(a.done)
+
+	for event := range a.events {
+		for _, sink := range a.sinks {
+			if err := sink.Write(context.Background(), event); err != nil {
+				logger.Error("Audit sink %T failed to write %q event: %v", sink, event.Type, err)
+			}
+		}
+	}
+}
+
+func (a *auditor) Close(ctx context.Context) error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.events)
+	}
+	a.mu.Unlock()
+
+	select {
+	case <-a.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	for _, sink := range a.sinks {
+		if err := sink.Close(); err != nil {
+			logger.Error("Audit sink %T failed to close: %v", sink, err)
+		}
+	}
+	return nil
+}
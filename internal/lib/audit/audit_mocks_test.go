@@ -0,0 +1,45 @@
+package audit_test
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/audit"
+)
+
+// ===== Fake Sink =====
+// fakeSink records written events and can be made to block until released.
+type fakeSink struct {
+	mu     sync.Mutex
+	events []*audit.Event
+	block  chan struct{}
+	closed bool
+}
+
+func newFakeSink() *fakeSink {
+	return &fakeSink{}
+}
+
+func (f *fakeSink) Write(ctx context.Context, event *audit.Event) error {
+	if f.block != nil {
+		<-f.block
+	}
+
+	f.mu.Lock()
+	f.events = append(f.events, event)
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *fakeSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *fakeSink) Events() []*audit.Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*audit.Event(nil), f.events...)
+}
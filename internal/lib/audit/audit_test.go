@@ -0,0 +1,275 @@
+package audit_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/audit"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics/metricstest"
+)
+
+func TestNewEvent(t *testing.T) {
+	peerCtx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 50123},
+	})
+	traceID := trace.TraceID{0x0a, 0xf7, 0x65, 0x19, 0x16, 0xcd, 0x43, 0xdd, 0x84, 0x48, 0xeb, 0x21, 0x1c, 0x80, 0x31, 0x9c}
+	traceCtx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  trace.SpanID{0xb7, 0xad, 0x6b, 0x71, 0x69, 0x20, 0x33, 0x31},
+	}))
+	clientCert := &x509.Certificate{}
+	clientCert.Subject.CommonName = "gateway"
+	mtlsCtx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 50123},
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{clientCert}},
+		}},
+	})
+
+	tests := []struct {
+		name                 string
+		ctx                  context.Context
+		expectedPeerIP       string
+		expectedClientIP     string
+		expectedPeerIdentity string
+		expectedUserAgent    string
+		expectedTraceID      string
+	}{
+		{
+			name:             "peer address",
+			ctx:              peerCtx,
+			expectedPeerIP:   "10.0.0.7",
+			expectedClientIP: "10.0.0.7",
+		},
+		{
+			name:              "forwarded for is left to the auditor",
+			ctx:               metadata.NewIncomingContext(peerCtx, metadata.Pairs("x-forwarded-for", "203.0.113.9, 10.0.0.1", "user-agent", "grpc-go/1.70")),
+			expectedPeerIP:    "10.0.0.7",
+			expectedClientIP:  "10.0.0.7",
+			expectedUserAgent: "grpc-go/1.70",
+		},
+		{
+			name:                 "mTLS client certificate",
+			ctx:                  mtlsCtx,
+			expectedPeerIP:       "10.0.0.7",
+			expectedClientIP:     "10.0.0.7",
+			expectedPeerIdentity: "gateway",
+		},
+		{
+			name:            "trace id",
+			ctx:             traceCtx,
+			expectedTraceID: traceID.String(),
+		},
+		{
+			name: "empty context",
+			ctx:  context.Background(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := audit.NewEvent(tt.ctx, audit.EventLogin)
+
+			assert.Equal(t, audit.EventLogin, event.Type)
+			assert.Equal(t, tt.expectedPeerIP, event.PeerIP)
+			assert.Equal(t, tt.expectedClientIP, event.ClientIP)
+			assert.Equal(t, tt.expectedPeerIdentity, event.PeerIdentity)
+			assert.Equal(t, tt.expectedUserAgent, event.UserAgent)
+			assert.Equal(t, tt.expectedTraceID, event.TraceID)
+			assert.WithinDuration(t, time.Now(), event.Timestamp, time.Second)
+		})
+	}
+}
+
+func TestEvent_Finish(t *testing.T) {
+	tests := []struct {
+		name            string
+		event           *audit.Event
+		err             error
+		expectedOutcome string
+		expectedReason  string
+	}{
+		{
+			name:            "success",
+			event:           &audit.Event{},
+			expectedOutcome: audit.OutcomeSuccess,
+		},
+		{
+			name:            "failure takes the status code as reason",
+			event:           &audit.Event{},
+			err:             status.Error(codes.Unauthenticated, "unauthorized"),
+			expectedOutcome: audit.OutcomeFailure,
+			expectedReason:  "Unauthenticated",
+		},
+		{
+			name:            "outcome and reason already set",
+			event:           &audit.Event{Outcome: audit.OutcomeDenied, Reason: audit.ReasonUserDenied},
+			err:             errors.New("denied"),
+			expectedOutcome: audit.OutcomeDenied,
+			expectedReason:  audit.ReasonUserDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.event.Finish(tt.err)
+
+			assert.Equal(t, tt.expectedOutcome, tt.event.Outcome)
+			assert.Equal(t, tt.expectedReason, tt.event.Reason)
+		})
+	}
+}
+
+func TestHashEmail(t *testing.T) {
+	hash := audit.HashEmail("john@example.com")
+
+	assert.Len(t, hash, 64)
+	assert.NotContains(t, hash, "john")
+	assert.Equal(t, hash, audit.HashEmail("  John@Example.com "))
+	assert.NotEqual(t, hash, audit.HashEmail("jane@example.com"))
+	assert.Empty(t, audit.HashEmail(""))
+}
+
+func TestAuditor_RecordAndClose(t *testing.T) {
+	sink := newFakeSink()
+	auditor, err := audit.NewAuditor(&config.Audit{BufferSize: 10}, sink)
+	require.NoError(t, err)
+
+	auditor.Record(&audit.Event{Type: audit.EventLogin})
+	auditor.Record(&audit.Event{Type: audit.EventLogout})
+
+	require.NoError(t, auditor.Close(context.Background()))
+
+	events := sink.Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, audit.EventLogin, events[0].Type)
+	assert.Equal(t, audit.EventLogout, events[1].Type)
+	assert.True(t, sink.closed)
+}
+
+func TestAuditor_RecordAfterClose(t *testing.T) {
+	sink := newFakeSink()
+	auditor, err := audit.NewAuditor(&config.Audit{BufferSize: 10}, sink)
+	require.NoError(t, err)
+	require.NoError(t, auditor.Close(context.Background()))
+
+	assert.NotPanics(t, func() { auditor.Record(&audit.Event{Type: audit.EventLogin}) })
+	assert.Empty(t, sink.Events())
+}
+
+func TestAuditor_ClientIP(t *testing.T) {
+	withPeer := func(addr string, forwardedFor ...string) context.Context {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{
+			Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 50123},
+		})
+		for _, f := range forwardedFor {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-forwarded-for", f))
+		}
+		return ctx
+	}
+
+	tests := []struct {
+		name             string
+		ctx              context.Context
+		expectedClientIP string
+	}{
+		{
+			name:             "direct caller",
+			ctx:              withPeer("203.0.113.9"),
+			expectedClientIP: "203.0.113.9",
+		},
+		{
+			name:             "forwarded for from an untrusted peer is ignored",
+			ctx:              withPeer("203.0.113.9", "198.51.100.1"),
+			expectedClientIP: "203.0.113.9",
+		},
+		{
+			name:             "forwarded for from a trusted proxy",
+			ctx:              withPeer("10.0.0.7", "198.51.100.1"),
+			expectedClientIP: "198.51.100.1",
+		},
+		{
+			name:             "spoofed entries before the last untrusted hop are ignored",
+			ctx:              withPeer("10.0.0.7", "1.2.3.4, 198.51.100.1, 10.0.0.8"),
+			expectedClientIP: "198.51.100.1",
+		},
+		{
+			name:             "trusted proxy without forwarded for",
+			ctx:              withPeer("10.0.0.7"),
+			expectedClientIP: "10.0.0.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := newFakeSink()
+			auditor, err := audit.NewAuditor(&config.Audit{BufferSize: 10, TrustedProxies: []string{"10.0.0.0/8"}}, sink)
+			require.NoError(t, err)
+
+			event := audit.NewEvent(tt.ctx, audit.EventLogin)
+			auditor.Record(event)
+			require.NoError(t, auditor.Close(context.Background()))
+
+			require.Len(t, sink.Events(), 1)
+			assert.Equal(t, tt.expectedClientIP, sink.Events()[0].ClientIP)
+			assert.Equal(t, event.PeerIP, sink.Events()[0].PeerIP)
+		})
+	}
+}
+
+func TestNewAuditor_InvalidTrustedProxy(t *testing.T) {
+	_, err := audit.NewAuditor(&config.Audit{TrustedProxies: []string{"10.0.0.0/33"}})
+	assert.Error(t, err)
+
+	_, err = audit.NewAuditor(&config.Audit{TrustedProxies: []string{"proxy"}})
+	assert.Error(t, err)
+}
+
+func TestAuditor_RecordDropsWhenFull(t *testing.T) {
+	sink := newFakeSink()
+	sink.block = make(chan struct{})
+	auditor, err := audit.NewAuditor(&config.Audit{BufferSize: 1}, sink)
+	require.NoError(t, err)
+	reader := metricstest.New(t)
+
+	// The first event is taken by the writer, which then blocks in the sink;
+	// the second fills the buffer and the third has nowhere to go.
+	auditor.Record(&audit.Event{Type: audit.EventLogin})
+	require.Eventually(t, func() bool {
+		auditor.Record(&audit.Event{Type: audit.EventLogin})
+		return reader.Value(t, "audit_events_dropped_total") > 0
+	}, time.Second, time.Millisecond)
+
+	close(sink.block)
+	require.NoError(t, auditor.Close(context.Background()))
+	assert.NotEmpty(t, sink.Events())
+}
+
+func TestAuditor_CloseTimesOut(t *testing.T) {
+	sink := newFakeSink()
+	sink.block = make(chan struct{})
+	defer close(sink.block)
+	auditor, err := audit.NewAuditor(&config.Audit{BufferSize: 1}, sink)
+	require.NoError(t, err)
+	auditor.Record(&audit.Event{Type: audit.EventLogin})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, auditor.Close(ctx), context.DeadlineExceeded)
+}
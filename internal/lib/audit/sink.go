@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+var ErrUnknownSink = errors.New("unknown audit sink")
+
+type writerSink struct {
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewWriterSink writes events as JSON lines to w, e.g. os.Stdout.
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+// NewFileSink appends events as JSON lines to file, rotating it once it grows
+// past maxSizeMB. Rotated files are never modified again.
+func NewFileSink(file string, maxSizeMB int, maxBackups int) Sink {
+	l := &lumberjack.Logger{
+		Filename:   file,
+		MaxSize:    maxSizeMB,
+		MaxBackups: maxBackups,
+	}
+	return &writerSink{w: l, closer: l}
+}
+
+func (s *writerSink) Write(ctx context.Context, event *Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+func (s *writerSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+type redisStreamSink struct {
+	redis  redis.RedisService
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamSink adds events to a Redis stream, capped at about maxLen
+// entries, for consumers such as a SIEM forwarder.
+func NewRedisStreamSink(redis redis.RedisService, stream string, maxLen int64) Sink {
+	return &redisStreamSink{redis: redis, stream: stream, maxLen: maxLen}
+}
+
+func (s *redisStreamSink) Write(ctx context.Context, event *Event) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.redis.XAdd(ctx, s.stream, s.maxLen, map[string]any{
+		"type":       event.Type,
+		"outcome":    event.Outcome,
+		"reason":     event.Reason,
+		"user_id":    strconv.Itoa(event.UserID),
+		"email_hash": event.EmailHash,
+		"peer_ip":    event.PeerIP,
+		"client_ip":  event.ClientIP,
+		"user_agent": event.UserAgent,
+		"trace_id":   event.TraceID,
+		"timestamp":  event.Timestamp.Format(time.RFC3339Nano),
+	})
+}
+
+func (s *redisStreamSink) Close() error {
+	return nil
+}
+
+// NewSinks builds the sinks listed in cfg.Sinks: "file", "stdout" and "redis".
+func NewSinks(cfg *config.Audit, redis redis.RedisService) ([]Sink, error) {
+	var sinks []Sink
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "file":
+			sinks = append(sinks, NewFileSink(cfg.File, cfg.FileMaxSizeMB, cfg.FileMaxBackups))
+		case "stdout":
+			sinks = append(sinks, NewWriterSink(os.Stdout))
+		case "redis":
+			sinks = append(sinks, NewRedisStreamSink(redis, cfg.RedisStream, cfg.RedisStreamMaxLen))
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrUnknownSink, name)
+		}
+	}
+	return sinks, nil
+}
@@ -0,0 +1,133 @@
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/audit"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis/redistest"
+)
+
+var testEvent = &audit.Event{
+	Type:      audit.EventLogin,
+	Outcome:   audit.OutcomeFailure,
+	Reason:    "Unauthenticated",
+	UserID:    7,
+	EmailHash: audit.HashEmail("john@example.com"),
+	PeerIP:    "10.0.0.7",
+	Timestamp: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := audit.NewWriterSink(&buf)
+
+	require.NoError(t, sink.Write(context.Background(), testEvent))
+	require.NoError(t, sink.Write(context.Background(), testEvent))
+	require.NoError(t, sink.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var got audit.Event
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &got))
+	assert.Equal(t, *testEvent, got)
+}
+
+func TestFileSink(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "audit.log")
+	sink := audit.NewFileSink(file, 1, 0)
+
+	require.NoError(t, sink.Write(context.Background(), testEvent))
+	require.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"type":"login"`)
+	assert.Contains(t, string(data), `"email_hash":"`+testEvent.EmailHash+`"`)
+}
+
+func TestRedisStreamSink(t *testing.T) {
+	tests := []struct {
+		name          string
+		xaddErr       error
+		expectedError error
+	}{
+		{
+			name: "added to stream",
+		},
+		{
+			name:          "redis error",
+			xaddErr:       errors.New("connection refused"),
+			expectedError: errors.New("connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := new(redistest.Client)
+			r.On("XAdd", mock.Anything, "audit-events", int64(1000), mock.MatchedBy(func(values map[string]any) bool {
+				return values["type"] == audit.EventLogin &&
+					values["user_id"] == "7" &&
+					values["timestamp"] == "2025-01-02T03:04:05Z"
+			})).Return(tt.xaddErr)
+
+			sink := audit.NewRedisStreamSink(r, "audit-events", 1000)
+			err := sink.Write(context.Background(), testEvent)
+
+			assert.Equal(t, tt.expectedError, err)
+			r.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNewSinks(t *testing.T) {
+	tests := []struct {
+		name          string
+		sinks         []string
+		expectedCount int
+		expectedError error
+	}{
+		{
+			name: "none",
+		},
+		{
+			name:          "all",
+			sinks:         []string{"file", "stdout", "redis"},
+			expectedCount: 3,
+		},
+		{
+			name:          "unknown",
+			sinks:         []string{"stdout", "kafka"},
+			expectedError: audit.ErrUnknownSink,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sinks, err := audit.NewSinks(&config.Audit{
+				Sinks: tt.sinks,
+				File:  filepath.Join(t.TempDir(), "audit.log"),
+			}, new(redistest.Client))
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, sinks, tt.expectedCount)
+		})
+	}
+}
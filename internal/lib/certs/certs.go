@@ -0,0 +1,262 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+var (
+	ErrNoCertificates = errors.New("no certificates found in CA bundle")
+	ErrTLSVersion     = errors.New("unsupported TLS version")
+)
+
+// Reloader serves a certificate/key pair and an optional CA bundle from disk,
+// picking up new files when they change, e.g. after a cert-manager rotation.
+// A failed reload is logged and the previous material is kept.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	interval time.Duration
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	modTimes  []time.Time
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+}
+
+// NewReloader loads the initial certificate material. certFile and keyFile
+// may be empty when only a CA bundle is needed, and caFile may be empty when
+// peers are not verified against a private CA. The files are checked for
+// changes at most once per interval, lazily on the next handshake.
+func NewReloader(certFile string, keyFile string, caFile string, interval time.Duration) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		interval: interval,
+	}
+
+	modTimes, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTimes); err != nil {
+		return nil, err
+	}
+	r.checkedAt = time.Now()
+
+	return r, nil
+}
+
+// ServerConfig returns a TLS config for a gRPC server. With a CA bundle
+// clients must present a certificate signed by it, unless optional is set.
+func (r *Reloader) ServerConfig(optional bool) *tls.Config {
+	clientAuth := tls.NoClientCert
+	if r.caFile != "" {
+		clientAuth = tls.RequireAndVerifyClientCert
+		if optional {
+			clientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := r.current()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				ClientAuth:   clientAuth,
+				ClientCAs:    pool,
+				NextProtos:   []string{"h2"},
+			}, nil
+		},
+	}
+}
+
+// ClientConfig returns a TLS config for dialing a gRPC server. The client
+// certificate, if any, is presented from the current files on every
+// handshake. With a CA bundle the server is verified against the current
+// bundle instead of the system roots; serverName overrides the name checked
+// against the server certificate, which otherwise comes from the target.
+func (r *Reloader) ClientConfig(serverName string, minVersion uint16) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: minVersion,
+		ServerName: serverName,
+	}
+
+	if r.certFile != "" {
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			return cert, nil
+		}
+	}
+
+	if r.caFile != "" {
+		// The standard verification only reads RootCAs once, so it is turned
+		// off here and redone against the reloaded bundle.
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			_, pool := r.current()
+			return verifyPeer(cs, pool)
+		}
+	}
+
+	return cfg
+}
+
+func verifyPeer(cs tls.ConnectionState, roots *x509.CertPool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server presented no certificate")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+// ParseTLSVersion maps "1.2" or "1.3" to its tls.Version constant. An empty
+// string means TLS 1.2.
+func ParseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("%w: %q", ErrTLSVersion, version)
+}
+
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checkedAt) >= r.interval {
+		r.checkedAt = time.Now()
+		r.reloadIfChanged()
+	}
+
+	return r.cert, r.pool
+}
+
+func (r *Reloader) reloadIfChanged() {
+	modTimes, err := r.stat()
+	if err != nil {
+		logger.Warn("Failed to check certificate files, keeping current ones: %v", err)
+		return
+	}
+
+	changed := false
+	for i := range modTimes {
+		if !modTimes[i].Equal(r.modTimes[i]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	if err := r.load(modTimes); err != nil {
+		logger.Warn("Failed to reload certificates, keeping current ones: %v", err)
+		return
+	}
+	logger.Info("Reloaded TLS certificates from %q", r.files())
+}
+
+func (r *Reloader) load(modTimes []time.Time) error {
+	var cert *tls.Certificate
+	if r.certFile != "" {
+		c, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return err
+		}
+		cert = &c
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return ErrNoCertificates
+		}
+	}
+
+	r.cert, r.pool, r.modTimes = cert, pool, modTimes
+	return nil
+}
+
+func (r *Reloader) stat() ([]time.Time, error) {
+	files := r.files()
+	modTimes := make([]time.Time, len(files))
+	for i, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return nil, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+func (r *Reloader) files() []string {
+	var files []string
+	for _, f := range []string{r.certFile, r.keyFile, r.caFile} {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// PeerCertificate returns the verified client certificate of the gRPC call in
+// ctx, if the client authenticated with one over mTLS.
+func PeerCertificate(ctx context.Context) (*x509.Certificate, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	return info.State.VerifiedChains[0][0], true
+}
+
+// PeerIdentity names the mTLS client of the call in ctx: its first URI SAN,
+// which carries the SPIFFE ID in service meshes, or else the subject CN.
+func PeerIdentity(ctx context.Context) (string, bool) {
+	cert, ok := PeerCertificate(ctx)
+	if !ok {
+		return "", false
+	}
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String(), true
+	}
+	return cert.Subject.CommonName, cert.Subject.CommonName != ""
+}
@@ -0,0 +1,300 @@
+package certs_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/certs"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/certs/certstest"
+)
+
+// handshake serves one TLS connection with cfg and returns the certificate
+// the server presented to the client.
+func handshake(t *testing.T, cfg *tls.Config, client *tls.Config) (*x509.Certificate, error) {
+	t.Helper()
+
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+	require.NoError(t, err)
+	defer lis.Close()
+
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.(*tls.Conn).Handshake()
+	}()
+
+	conn, err := tls.Dial("tcp", lis.Addr().String(), client)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+func clientConfig(t *testing.T, ca *certstest.CA, pair *certstest.Pair) *tls.Config {
+	t.Helper()
+
+	pem, err := os.ReadFile(ca.File)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(pem)
+
+	cfg := &tls.Config{RootCAs: pool, ServerName: "localhost", NextProtos: []string{"h2"}}
+	if pair != nil {
+		cert, err := tls.LoadX509KeyPair(pair.CertFile, pair.KeyFile)
+		require.NoError(t, err)
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg
+}
+
+func TestNewReloader(t *testing.T) {
+	ca := certstest.NewCA(t, "test-ca")
+	pair := ca.Issue(t, "auth-service", "")
+
+	tests := []struct {
+		name      string
+		certFile  string
+		keyFile   string
+		caFile    string
+		expectErr bool
+	}{
+		{name: "key pair", certFile: pair.CertFile, keyFile: pair.KeyFile},
+		{name: "key pair and CA", certFile: pair.CertFile, keyFile: pair.KeyFile, caFile: ca.File},
+		{name: "CA only", caFile: ca.File},
+		{name: "missing key", certFile: pair.CertFile, keyFile: "/does/not/exist", expectErr: true},
+		{name: "CA bundle without certificates", caFile: pair.KeyFile, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := certs.NewReloader(tt.certFile, tt.keyFile, tt.caFile, 0)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReloader_ServerConfig(t *testing.T) {
+	ca := certstest.NewCA(t, "test-ca")
+	serverPair := ca.Issue(t, "auth-service", "")
+	clientPair := ca.Issue(t, "gateway", "")
+	otherCA := certstest.NewCA(t, "other-ca")
+	untrustedPair := otherCA.Issue(t, "intruder", "")
+
+	tests := []struct {
+		name      string
+		caFile    string
+		optional  bool
+		client    *certstest.Pair
+		expectErr bool
+	}{
+		{name: "server TLS", client: nil},
+		{name: "mTLS with client certificate", caFile: ca.File, client: clientPair},
+		{name: "mTLS without client certificate", caFile: ca.File, client: nil, expectErr: true},
+		{name: "mTLS with untrusted client certificate", caFile: ca.File, client: untrustedPair, expectErr: true},
+		{name: "optional mTLS without client certificate", caFile: ca.File, optional: true, client: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reloader, err := certs.NewReloader(serverPair.CertFile, serverPair.KeyFile, tt.caFile, 0)
+			require.NoError(t, err)
+
+			lis, err := tls.Listen("tcp", "127.0.0.1:0", reloader.ServerConfig(tt.optional))
+			require.NoError(t, err)
+			defer lis.Close()
+
+			serverErr := make(chan error, 1)
+			go func() {
+				conn, err := lis.Accept()
+				if err != nil {
+					serverErr <- err
+					return
+				}
+				defer conn.Close()
+				serverErr <- conn.(*tls.Conn).Handshake()
+			}()
+
+			conn, err := tls.Dial("tcp", lis.Addr().String(), clientConfig(t, ca, tt.client))
+			if err == nil {
+				// TLS 1.3 client certificates are checked after the client
+				// finishes, so read to surface the server's verdict.
+				_, _ = conn.Read(make([]byte, 1))
+				conn.Close()
+			}
+
+			if tt.expectErr {
+				assert.Error(t, <-serverErr)
+			} else {
+				require.NoError(t, err)
+				assert.NoError(t, <-serverErr)
+			}
+		})
+	}
+}
+
+func TestReloader_ReloadsRotatedCertificate(t *testing.T) {
+	ca := certstest.NewCA(t, "test-ca")
+	pair := ca.Issue(t, "auth-service", "")
+	original := pair.Serial
+
+	reloader, err := certs.NewReloader(pair.CertFile, pair.KeyFile, "", 0)
+	require.NoError(t, err)
+	cfg := reloader.ServerConfig(false)
+
+	cert, err := handshake(t, cfg, clientConfig(t, ca, nil))
+	require.NoError(t, err)
+	assert.Equal(t, original, cert.SerialNumber)
+
+	certstest.Replace(t, pair, ca.Issue(t, "auth-service", ""))
+
+	cert, err = handshake(t, cfg, clientConfig(t, ca, nil))
+	require.NoError(t, err)
+	assert.NotEqual(t, original, cert.SerialNumber)
+	assert.Equal(t, pair.Serial, cert.SerialNumber)
+}
+
+func TestReloader_KeepsCertificateOnBrokenRotation(t *testing.T) {
+	ca := certstest.NewCA(t, "test-ca")
+	pair := ca.Issue(t, "auth-service", "")
+	original := pair.Serial
+
+	reloader, err := certs.NewReloader(pair.CertFile, pair.KeyFile, "", 0)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(pair.KeyFile, []byte("garbage"), 0o600))
+
+	cert, err := handshake(t, reloader.ServerConfig(false), clientConfig(t, ca, nil))
+	require.NoError(t, err)
+	assert.Equal(t, original, cert.SerialNumber)
+}
+
+func TestPeerIdentity(t *testing.T) {
+	spiffe := &x509.Certificate{}
+	spiffe.Subject.CommonName = "gateway"
+	uri, err := url.Parse("spiffe://cluster.local/ns/default/sa/gateway")
+	require.NoError(t, err)
+	spiffe.URIs = append(spiffe.URIs, uri)
+
+	commonName := &x509.Certificate{}
+	commonName.Subject.CommonName = "gateway"
+
+	withPeer := func(state tls.ConnectionState) context.Context {
+		return peer.NewContext(context.Background(), &peer.Peer{
+			Addr:     &net.TCPAddr{},
+			AuthInfo: credentials.TLSInfo{State: state},
+		})
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "SPIFFE URI",
+			ctx:      withPeer(tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{spiffe}}}),
+			expected: "spiffe://cluster.local/ns/default/sa/gateway",
+			ok:       true,
+		},
+		{
+			name:     "common name",
+			ctx:      withPeer(tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{commonName}}}),
+			expected: "gateway",
+			ok:       true,
+		},
+		{
+			name: "TLS without client certificate",
+			ctx:  withPeer(tls.ConnectionState{}),
+		},
+		{
+			name: "no peer",
+			ctx:  context.Background(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, ok := certs.PeerIdentity(tt.ctx)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, identity)
+		})
+	}
+}
+
+func TestReloader_ClientConfigReloadsCABundle(t *testing.T) {
+	oldCA := certstest.NewCA(t, "old-ca")
+	newCA := certstest.NewCA(t, "new-ca")
+	serverPair := newCA.Issue(t, "user-service", "")
+
+	server, err := certs.NewReloader(serverPair.CertFile, serverPair.KeyFile, "", 0)
+	require.NoError(t, err)
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	pem, err := os.ReadFile(oldCA.File)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(bundle, pem, 0o600))
+
+	client, err := certs.NewReloader("", "", bundle, 0)
+	require.NoError(t, err)
+	cfg := client.ClientConfig("localhost", tls.VersionTLS12)
+
+	_, err = handshake(t, server.ServerConfig(false), cfg)
+	assert.Error(t, err)
+
+	pem, err = os.ReadFile(newCA.File)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(bundle, pem, 0o600))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(bundle, future, future))
+
+	cert, err := handshake(t, server.ServerConfig(false), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, serverPair.Serial, cert.SerialNumber)
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	tests := []struct {
+		version   string
+		expected  uint16
+		expectErr bool
+	}{
+		{version: "", expected: tls.VersionTLS12},
+		{version: "1.2", expected: tls.VersionTLS12},
+		{version: "1.3", expected: tls.VersionTLS13},
+		{version: "1.1", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			version, err := certs.ParseTLSVersion(tt.version)
+			if tt.expectErr {
+				assert.ErrorIs(t, err, certs.ErrTLSVersion)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, version)
+		})
+	}
+}
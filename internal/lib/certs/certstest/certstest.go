@@ -0,0 +1,149 @@
+// Package certstest issues throwaway certificates for TLS tests.
+package certstest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type CA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	// File is the PEM encoded CA certificate.
+	File string
+}
+
+// Pair is a PEM encoded certificate and private key on disk.
+type Pair struct {
+	CertFile string
+	KeyFile  string
+	Serial   *big.Int
+}
+
+func NewCA(t *testing.T, name string) *CA {
+	t.Helper()
+
+	key := newKey(t)
+	tmpl := &x509.Certificate{
+		SerialNumber:          newSerial(t),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "ca.pem")
+	writePEM(t, file, "CERTIFICATE", der)
+
+	return &CA{cert: cert, key: key, File: file}
+}
+
+// Issue signs a certificate usable for both server and client auth, valid for
+// localhost and 127.0.0.1 plus any extra DNS names. A spiffe:// style uri is
+// added as a URI SAN when given.
+func (ca *CA) Issue(t *testing.T, commonName string, uri string, dnsNames ...string) *Pair {
+	t.Helper()
+
+	key := newKey(t)
+	tmpl := &x509.Certificate{
+		SerialNumber: newSerial(t),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     append([]string{"localhost"}, dnsNames...),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if uri != "" {
+		u, err := url.Parse(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl.URIs = []*url.URL{u}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	pair := &Pair{
+		CertFile: filepath.Join(dir, "tls.crt"),
+		KeyFile:  filepath.Join(dir, "tls.key"),
+		Serial:   tmpl.SerialNumber,
+	}
+	writePEM(t, pair.CertFile, "CERTIFICATE", der)
+	writePEM(t, pair.KeyFile, "EC PRIVATE KEY", keyDER)
+
+	return pair
+}
+
+// Replace overwrites dst's files with src's, the way a rotation would, and
+// bumps their modification time so reloaders notice the change.
+func Replace(t *testing.T, dst *Pair, src *Pair) {
+	t.Helper()
+
+	for from, to := range map[string]string{src.CertFile: dst.CertFile, src.KeyFile: dst.KeyFile} {
+		b, err := os.ReadFile(from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(to, b, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		future := time.Now().Add(time.Minute)
+		if err := os.Chtimes(to, future, future); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dst.Serial = src.Serial
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func newSerial(t *testing.T) *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return serial
+}
+
+func writePEM(t *testing.T, file string, blockType string, der []byte) {
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,356 @@
+package device
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis"
+)
+
+// RFC 8628 section 6.1 recommends a base-20 alphabet without vowels so user
+// codes are easy to type and can't spell words.
+const (
+	userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength  = 8
+
+	// Seconds added to the polling interval on every slow_down response.
+	slowDownIncrement = 5
+)
+
+var (
+	ErrAuthorizationPending = errors.New(constant.OAuthErrorAuthorizationPending)
+	ErrSlowDown             = errors.New(constant.OAuthErrorSlowDown)
+	ErrAccessDenied         = errors.New(constant.OAuthErrorAccessDenied)
+	ErrExpiredToken         = errors.New(constant.OAuthErrorExpiredToken)
+	ErrInvalidUserCode      = errors.New("invalid or already used user code")
+	ErrClientMismatch       = errors.New("device code was issued to another client")
+
+	// errDecided tells Poll the grant was approved or denied and is ready to
+	// be consumed.
+	errDecided = errors.New("device grant decided")
+)
+
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusDenied   Status = "denied"
+)
+
+type Authorization struct {
+	DeviceCode   string `json:"device_code"`
+	UserCode     string `json:"user_code"`
+	ClientID     string `json:"client_id"`
+	Scope        string `json:"scope,omitempty"`
+	Status       Status `json:"status"`
+	UserID       uint   `json:"user_id,omitempty"`
+	Username     string `json:"username,omitempty"`
+	Interval     int64  `json:"interval"`
+	ExpiresAt    int64  `json:"expires_at"`
+	LastPolledAt int64  `json:"last_polled_at,omitempty"`
+
+	VerificationURI         string `json:"-"`
+	VerificationURIComplete string `json:"-"`
+}
+
+type DeviceManager interface {
+	Authorize(ctx context.Context, clientID string, scope string) (*Authorization, error)
+	Lookup(ctx context.Context, userCode string) (*Authorization, error)
+	Approve(ctx context.Context, userCode string, userID uint, username string) error
+	Deny(ctx context.Context, userCode string) error
+	Poll(ctx context.Context, deviceCode string, clientID string) (*Authorization, error)
+}
+
+type deviceManager struct {
+	verificationURI string
+	expiry          time.Duration
+	interval        time.Duration
+	redis           redis.RedisService
+}
+
+func NewDeviceManager(cfg *config.Device, redis redis.RedisService) DeviceManager {
+	return &deviceManager{
+		verificationURI: cfg.VerificationURI,
+		expiry:          cfg.Expiry,
+		interval:        cfg.Interval,
+		redis:           redis,
+	}
+}
+
+func (d *deviceManager) Authorize(ctx context.Context, clientID string, scope string) (*Authorization, error) {
+	deviceCode, err := generateDeviceCode()
+	if err != nil {
+		return nil, err
+	}
+	userCode, err := generateUserCode()
+	if err != nil {
+		return nil, err
+	}
+
+	auth := &Authorization{
+		DeviceCode: deviceCode,
+		UserCode:   userCode,
+		ClientID:   clientID,
+		Scope:      scope,
+		Status:     StatusPending,
+		Interval:   int64(d.interval.Seconds()),
+		ExpiresAt:  time.Now().Add(d.expiry).Unix(),
+	}
+
+	if err := d.save(ctx, auth); err != nil {
+		return nil, err
+	}
+	if err := d.redis.Set(ctx, userCodeKey(userCode), deviceCode, d.expiry); err != nil {
+		return nil, err
+	}
+
+	d.withVerificationURI(auth)
+	return auth, nil
+}
+
+// Lookup returns the pending grant behind a user code, so the user can be
+// shown what the device asks for before approving it.
+func (d *deviceManager) Lookup(ctx context.Context, userCode string) (*Authorization, error) {
+	deviceCode, err := d.deviceCodeFor(ctx, userCode)
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := d.find(ctx, deviceCode)
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrInvalidUserCode
+	}
+	if err != nil {
+		return nil, err
+	}
+	if auth.Status != StatusPending {
+		return nil, ErrInvalidUserCode
+	}
+
+	return auth, nil
+}
+
+func (d *deviceManager) Approve(ctx context.Context, userCode string, userID uint, username string) error {
+	return d.decide(ctx, userCode, func(auth *Authorization) {
+		auth.Status = StatusApproved
+		auth.UserID = userID
+		auth.Username = username
+	})
+}
+
+func (d *deviceManager) Deny(ctx context.Context, userCode string) error {
+	return d.decide(ctx, userCode, func(auth *Authorization) {
+		auth.Status = StatusDenied
+	})
+}
+
+// Poll reports the state of a grant to the device. Pending grants are updated
+// in a transaction, so a poll never writes back over an approval that landed
+// in between. A decided grant is consumed with GETDEL, which only one of
+// several concurrent polls wins, so a device code is redeemed at most once.
+func (d *deviceManager) Poll(ctx context.Context, deviceCode string, clientID string) (*Authorization, error) {
+	var pollErr error
+	err := d.update(ctx, deviceCode, func(auth *Authorization) error {
+		if auth.ClientID != clientID {
+			return ErrClientMismatch
+		}
+
+		now := time.Now().Unix()
+		pollErr = ErrAuthorizationPending
+		if auth.LastPolledAt != 0 && now-auth.LastPolledAt < auth.Interval {
+			auth.Interval += slowDownIncrement
+			pollErr = ErrSlowDown
+		} else if auth.Status != StatusPending {
+			return errDecided
+		}
+		auth.LastPolledAt = now
+		return nil
+	})
+
+	switch {
+	case err == nil:
+		return nil, pollErr
+	case errors.Is(err, errDecided):
+		return d.consume(ctx, deviceCode)
+	case errors.Is(err, redis.Nil):
+		return nil, ErrExpiredToken
+	default:
+		return nil, err
+	}
+}
+
+// decide records the user's answer on a pending grant.
+func (d *deviceManager) decide(ctx context.Context, userCode string, decision func(auth *Authorization)) error {
+	deviceCode, err := d.deviceCodeFor(ctx, userCode)
+	if err != nil {
+		return err
+	}
+
+	err = d.update(ctx, deviceCode, func(auth *Authorization) error {
+		if auth.Status != StatusPending {
+			return ErrInvalidUserCode
+		}
+		decision(auth)
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return ErrInvalidUserCode
+	}
+	return err
+}
+
+// consume removes a decided grant and returns it when approved.
+func (d *deviceManager) consume(ctx context.Context, deviceCode string) (*Authorization, error) {
+	val, err := d.redis.GetDel(ctx, deviceCodeKey(deviceCode))
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrExpiredToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	auth := &Authorization{}
+	if err := json.Unmarshal([]byte(val), auth); err != nil {
+		return nil, err
+	}
+	_ = d.redis.Del(ctx, userCodeKey(auth.UserCode))
+
+	if auth.Status != StatusApproved {
+		return nil, ErrAccessDenied
+	}
+	return auth, nil
+}
+
+func (d *deviceManager) deviceCodeFor(ctx context.Context, userCode string) (string, error) {
+	deviceCode, err := d.redis.Get(ctx, userCodeKey(NormalizeUserCode(userCode)))
+	if errors.Is(err, redis.Nil) {
+		return "", ErrInvalidUserCode
+	}
+	return deviceCode, err
+}
+
+func (d *deviceManager) find(ctx context.Context, deviceCode string) (*Authorization, error) {
+	val, err := d.redis.Get(ctx, deviceCodeKey(deviceCode))
+	if err != nil {
+		return nil, err
+	}
+
+	auth := &Authorization{}
+	if err := json.Unmarshal([]byte(val), auth); err != nil {
+		return nil, err
+	}
+
+	return auth, nil
+}
+
+// update applies fn to the stored grant atomically. An error from fn leaves
+// the grant as it was.
+func (d *deviceManager) update(ctx context.Context, deviceCode string, fn func(auth *Authorization) error) error {
+	return d.redis.Update(ctx, deviceCodeKey(deviceCode), func(val string) (string, time.Duration, error) {
+		auth := &Authorization{}
+		if err := json.Unmarshal([]byte(val), auth); err != nil {
+			return "", 0, err
+		}
+		if err := fn(auth); err != nil {
+			return "", 0, err
+		}
+		return encode(auth)
+	})
+}
+
+func (d *deviceManager) save(ctx context.Context, auth *Authorization) error {
+	val, ttl, err := encode(auth)
+	if err != nil {
+		return err
+	}
+
+	return d.redis.Set(ctx, deviceCodeKey(auth.DeviceCode), val, ttl)
+}
+
+// encode returns the stored form of auth and how long it has left.
+func encode(auth *Authorization) (string, time.Duration, error) {
+	ttl := auth.ExpiresAt - time.Now().Unix()
+	if ttl <= 0 {
+		return "", 0, ErrExpiredToken
+	}
+
+	val, err := json.Marshal(auth)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return string(val), time.Duration(ttl) * time.Second, nil
+}
+
+func (d *deviceManager) withVerificationURI(auth *Authorization) {
+	auth.VerificationURI = d.verificationURI
+
+	u, err := url.Parse(d.verificationURI)
+	if err != nil {
+		return
+	}
+	q := u.Query()
+	q.Set("user_code", FormatUserCode(auth.UserCode))
+	u.RawQuery = q.Encode()
+	auth.VerificationURIComplete = u.String()
+}
+
+// NormalizeUserCode strips the separators and casing users tend to add when
+// typing a code so "bcdf-ghjk" and "BCDFGHJK" resolve to the same grant.
+func NormalizeUserCode(userCode string) string {
+	userCode = strings.ToUpper(userCode)
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(userCodeCharset, r) {
+			return r
+		}
+		return -1
+	}, userCode)
+}
+
+// FormatUserCode renders a normalized user code as XXXX-XXXX for display.
+func FormatUserCode(userCode string) string {
+	if len(userCode) != userCodeLength {
+		return userCode
+	}
+	return userCode[:userCodeLength/2] + "-" + userCode[userCodeLength/2:]
+}
+
+func generateDeviceCode() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func generateUserCode() (string, error) {
+	charsetLen := big.NewInt(int64(len(userCodeCharset)))
+	code := make([]byte, userCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, charsetLen)
+		if err != nil {
+			return "", err
+		}
+		code[i] = userCodeCharset[n.Int64()]
+	}
+	return string(code), nil
+}
+
+func deviceCodeKey(deviceCode string) string {
+	return fmt.Sprintf("%s:%s", constant.RedisDeviceCode, deviceCode)
+}
+
+func userCodeKey(userCode string) string {
+	return fmt.Sprintf("%s:%s", constant.RedisDeviceUserCode, userCode)
+}
@@ -0,0 +1,314 @@
+package device_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/device"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis/redistest"
+)
+
+var cfg = &config.Device{
+	VerificationURI: "https://example.com/device",
+	Expiry:          10 * time.Minute,
+	Interval:        5 * time.Second,
+}
+
+func encode(t *testing.T, a *device.Authorization) string {
+	t.Helper()
+	b, err := json.Marshal(a)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func pending() *device.Authorization {
+	return &device.Authorization{
+		DeviceCode: "device123",
+		UserCode:   "BCDFGHJK",
+		ClientID:   "tv-app",
+		Status:     device.StatusPending,
+		Interval:   5,
+		ExpiresAt:  time.Now().Add(10 * time.Minute).Unix(),
+	}
+}
+
+func TestDeviceManager_Authorize(t *testing.T) {
+	mockRedis := new(redistest.Client)
+	manager := device.NewDeviceManager(cfg, mockRedis)
+
+	mockRedis.On("Set", mock.Anything, mock.MatchedBy(func(k string) bool {
+		return strings.HasPrefix(k, constant.RedisDeviceCode+":")
+	}), mock.Anything, mock.Anything).Return(nil).Once()
+	mockRedis.On("Set", mock.Anything, mock.MatchedBy(func(k string) bool {
+		return strings.HasPrefix(k, constant.RedisDeviceUserCode+":")
+	}), mock.Anything, cfg.Expiry).Return(nil).Once()
+
+	auth, err := manager.Authorize(context.Background(), "tv-app", "profile")
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, auth.DeviceCode)
+	assert.Len(t, auth.UserCode, 8)
+	assert.Equal(t, auth.UserCode, device.NormalizeUserCode(auth.UserCode))
+	assert.Equal(t, device.StatusPending, auth.Status)
+	assert.Equal(t, int64(5), auth.Interval)
+	assert.Equal(t, cfg.VerificationURI, auth.VerificationURI)
+	assert.Contains(t, auth.VerificationURIComplete, "user_code="+device.FormatUserCode(auth.UserCode))
+	mockRedis.AssertExpectations(t)
+}
+
+func TestDeviceManager_Poll(t *testing.T) {
+	deviceKey := constant.RedisDeviceCode + ":device123"
+	userKey := constant.RedisDeviceUserCode + ":BCDFGHJK"
+
+	tests := []struct {
+		name       string
+		stored     func() *device.Authorization
+		getErr     error
+		clientID   string
+		setupMocks func(r *redistest.Client)
+		expectErr  error
+	}{
+		{
+			name:     "authorization pending",
+			stored:   pending,
+			clientID: "tv-app",
+			setupMocks: func(r *redistest.Client) {
+				r.On("Set", mock.Anything, deviceKey, mock.Anything, mock.Anything).Return(nil)
+			},
+			expectErr: device.ErrAuthorizationPending,
+		},
+		{
+			name: "polling too fast",
+			stored: func() *device.Authorization {
+				a := pending()
+				a.LastPolledAt = time.Now().Unix()
+				return a
+			},
+			clientID: "tv-app",
+			setupMocks: func(r *redistest.Client) {
+				r.On("Set", mock.Anything, deviceKey, mock.MatchedBy(func(v string) bool {
+					a := &device.Authorization{}
+					_ = json.Unmarshal([]byte(v), a)
+					return a.Interval == 10
+				}), mock.Anything).Return(nil)
+			},
+			expectErr: device.ErrSlowDown,
+		},
+		{
+			name: "approved",
+			stored: func() *device.Authorization {
+				a := pending()
+				a.Status = device.StatusApproved
+				a.UserID = 1
+				a.Username = "name"
+				return a
+			},
+			clientID: "tv-app",
+			setupMocks: func(r *redistest.Client) {
+				a := pending()
+				a.Status = device.StatusApproved
+				a.UserID = 1
+				a.Username = "name"
+				r.On("GetDel", mock.Anything, deviceKey).Return(encode(t, a), nil)
+				r.On("Del", mock.Anything, userKey).Return(nil)
+			},
+		},
+		{
+			name: "approved grant already redeemed by a concurrent poll",
+			stored: func() *device.Authorization {
+				a := pending()
+				a.Status = device.StatusApproved
+				return a
+			},
+			clientID: "tv-app",
+			setupMocks: func(r *redistest.Client) {
+				r.On("GetDel", mock.Anything, deviceKey).Return("", redis.Nil)
+			},
+			expectErr: device.ErrExpiredToken,
+		},
+		{
+			name: "denied",
+			stored: func() *device.Authorization {
+				a := pending()
+				a.Status = device.StatusDenied
+				return a
+			},
+			clientID: "tv-app",
+			setupMocks: func(r *redistest.Client) {
+				a := pending()
+				a.Status = device.StatusDenied
+				r.On("GetDel", mock.Anything, deviceKey).Return(encode(t, a), nil)
+				r.On("Del", mock.Anything, userKey).Return(nil)
+			},
+			expectErr: device.ErrAccessDenied,
+		},
+		{
+			name:      "expired or unknown device code",
+			getErr:    redis.Nil,
+			clientID:  "tv-app",
+			expectErr: device.ErrExpiredToken,
+		},
+		{
+			name:      "other client",
+			stored:    pending,
+			clientID:  "someone-else",
+			expectErr: device.ErrClientMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRedis := new(redistest.Client)
+			manager := device.NewDeviceManager(cfg, mockRedis)
+
+			if tt.getErr != nil {
+				mockRedis.On("Get", mock.Anything, deviceKey).Return("", tt.getErr)
+			} else {
+				mockRedis.On("Get", mock.Anything, deviceKey).Return(encode(t, tt.stored()), nil)
+			}
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockRedis)
+			}
+
+			auth, err := manager.Poll(context.Background(), "device123", tt.clientID)
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+				assert.Nil(t, auth)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, uint(1), auth.UserID)
+				assert.Equal(t, "name", auth.Username)
+			}
+			mockRedis.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeviceManager_Approve(t *testing.T) {
+	deviceKey := constant.RedisDeviceCode + ":device123"
+	userKey := constant.RedisDeviceUserCode + ":BCDFGHJK"
+
+	tests := []struct {
+		name       string
+		userCode   string
+		setupMocks func(r *redistest.Client)
+		expectErr  error
+	}{
+		{
+			name:     "approves pending grant with loosely typed code",
+			userCode: "bcdf-ghjk",
+			setupMocks: func(r *redistest.Client) {
+				r.On("Get", mock.Anything, userKey).Return("device123", nil)
+				r.On("Get", mock.Anything, deviceKey).Return(encode(t, pending()), nil)
+				r.On("Set", mock.Anything, deviceKey, mock.MatchedBy(func(v string) bool {
+					a := &device.Authorization{}
+					_ = json.Unmarshal([]byte(v), a)
+					return a.Status == device.StatusApproved && a.UserID == 1 && a.Username == "name"
+				}), mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:     "unknown user code",
+			userCode: "BCDF-GHJK",
+			setupMocks: func(r *redistest.Client) {
+				r.On("Get", mock.Anything, userKey).Return("", redis.Nil)
+			},
+			expectErr: device.ErrInvalidUserCode,
+		},
+		{
+			name:     "already approved",
+			userCode: "BCDF-GHJK",
+			setupMocks: func(r *redistest.Client) {
+				a := pending()
+				a.Status = device.StatusApproved
+				r.On("Get", mock.Anything, userKey).Return("device123", nil)
+				r.On("Get", mock.Anything, deviceKey).Return(encode(t, a), nil)
+			},
+			expectErr: device.ErrInvalidUserCode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRedis := new(redistest.Client)
+			manager := device.NewDeviceManager(cfg, mockRedis)
+			tt.setupMocks(mockRedis)
+
+			err := manager.Approve(context.Background(), tt.userCode, 1, "name")
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockRedis.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeviceManager_Lookup(t *testing.T) {
+	deviceKey := constant.RedisDeviceCode + ":device123"
+	userKey := constant.RedisDeviceUserCode + ":BCDFGHJK"
+
+	stored := pending()
+	stored.Scope = "videos:read"
+
+	mockRedis := new(redistest.Client)
+	manager := device.NewDeviceManager(cfg, mockRedis)
+	mockRedis.On("Get", mock.Anything, userKey).Return("device123", nil)
+	mockRedis.On("Get", mock.Anything, deviceKey).Return(encode(t, stored), nil)
+
+	auth, err := manager.Lookup(context.Background(), "bcdf-ghjk")
+	require.NoError(t, err)
+	assert.Equal(t, "videos:read", auth.Scope)
+	mockRedis.AssertExpectations(t)
+}
+
+func TestDeviceManager_Deny(t *testing.T) {
+	deviceKey := constant.RedisDeviceCode + ":device123"
+	userKey := constant.RedisDeviceUserCode + ":BCDFGHJK"
+
+	mockRedis := new(redistest.Client)
+	manager := device.NewDeviceManager(cfg, mockRedis)
+
+	mockRedis.On("Get", mock.Anything, userKey).Return("device123", nil)
+	mockRedis.On("Get", mock.Anything, deviceKey).Return(encode(t, pending()), nil)
+	mockRedis.On("Set", mock.Anything, deviceKey, mock.MatchedBy(func(v string) bool {
+		a := &device.Authorization{}
+		_ = json.Unmarshal([]byte(v), a)
+		return a.Status == device.StatusDenied
+	}), mock.Anything).Return(nil)
+
+	assert.NoError(t, manager.Deny(context.Background(), "BCDF-GHJK"))
+	mockRedis.AssertExpectations(t)
+}
+
+func TestUserCodeFormatting(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		normalized string
+		formatted  string
+	}{
+		{"already normalized", "BCDFGHJK", "BCDFGHJK", "BCDF-GHJK"},
+		{"lowercase with dash", "bcdf-ghjk", "BCDFGHJK", "BCDF-GHJK"},
+		{"spaces", " bcdf ghjk ", "BCDFGHJK", "BCDF-GHJK"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized := device.NormalizeUserCode(tt.input)
+			assert.Equal(t, tt.normalized, normalized)
+			assert.Equal(t, tt.formatted, device.FormatUserCode(normalized))
+		})
+	}
+}
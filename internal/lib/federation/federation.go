@@ -0,0 +1,229 @@
+package federation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	ErrInvalidState    = errors.New("invalid or expired login state")
+	ErrMissingIDToken  = errors.New("token response has no id_token")
+	ErrNonceMismatch   = errors.New("id token nonce does not match login state")
+)
+
+// Identity is the subset of upstream ID token claims we act on.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	// TrustEmail is set for providers configured to vouch for their emails,
+	// whose identities may be linked to an existing account with the same
+	// address.
+	TrustEmail bool
+}
+
+type FederationManager interface {
+	AuthCodeURL(ctx context.Context, provider string) (url string, state string, err error)
+	Exchange(ctx context.Context, state string, code string) (*Identity, error)
+}
+
+type loginState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+type idTokenClaims struct {
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+type federationManager struct {
+	stateExpiry time.Duration
+	providers   map[string]*provider
+	redis       redis.RedisService
+}
+
+func NewFederationManager(cfg *config.OIDC, redis redis.RedisService) FederationManager {
+	providers := make(map[string]*provider, len(cfg.Providers))
+	for name, p := range cfg.Providers {
+		providers[name] = &provider{cfg: p}
+	}
+
+	return &federationManager{
+		stateExpiry: cfg.StateExpiry,
+		providers:   providers,
+		redis:       redis,
+	}
+}
+
+func (f *federationManager) AuthCodeURL(ctx context.Context, name string) (string, string, error) {
+	p, err := f.provider(ctx, name)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	ls := &loginState{
+		Provider:     name,
+		Nonce:        nonce,
+		CodeVerifier: oauth2.GenerateVerifier(),
+	}
+
+	val, err := json.Marshal(ls)
+	if err != nil {
+		return "", "", err
+	}
+	if err := f.redis.Set(ctx, stateKey(state), string(val), f.stateExpiry); err != nil {
+		return "", "", err
+	}
+
+	url := p.oauth2.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(ls.CodeVerifier))
+	return url, state, nil
+}
+
+func (f *federationManager) Exchange(ctx context.Context, state string, code string) (*Identity, error) {
+	ls, err := f.consumeState(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := f.provider(ctx, ls.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(ls.CodeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("code exchange with %q failed: %w", ls.Provider, err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, ErrMissingIDToken
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("id token verification failed: %w", err)
+	}
+	if idToken.Nonce != ls.Nonce {
+		return nil, ErrNonceMismatch
+	}
+
+	claims := &idTokenClaims{}
+	if err := idToken.Claims(claims); err != nil {
+		return nil, err
+	}
+
+	return &Identity{
+		Provider:      ls.Provider,
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+		TrustEmail:    p.cfg.TrustEmail,
+	}, nil
+}
+
+// consumeState loads and deletes the login state in one GETDEL, so of two
+// callbacks racing with the same state only one gets it and none can be
+// replayed.
+func (f *federationManager) consumeState(ctx context.Context, state string) (*loginState, error) {
+	val, err := f.redis.GetDel(ctx, stateKey(state))
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrInvalidState
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ls := &loginState{}
+	if err := json.Unmarshal([]byte(val), ls); err != nil {
+		return nil, err
+	}
+
+	return ls, nil
+}
+
+func (f *federationManager) provider(ctx context.Context, name string) (*provider, error) {
+	p, ok := f.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// provider runs discovery lazily so an unreachable identity provider only
+// breaks its own logins instead of service startup.
+type provider struct {
+	cfg *config.OIDCProvider
+
+	mu       sync.Mutex
+	oauth2   *oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+}
+
+func (p *provider) init(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.verifier != nil {
+		return nil
+	}
+
+	op, err := gooidc.NewProvider(ctx, p.cfg.IssuerURL)
+	if err != nil {
+		return fmt.Errorf("oidc discovery for %q failed: %w", p.cfg.IssuerURL, err)
+	}
+
+	p.oauth2 = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Endpoint:     op.Endpoint(),
+		Scopes:       p.cfg.Scopes,
+	}
+	p.verifier = op.Verifier(&gooidc.Config{ClientID: p.cfg.ClientID})
+
+	return nil
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func stateKey(state string) string {
+	return fmt.Sprintf("%s:%s", constant.RedisOIDCState, state)
+}
@@ -0,0 +1,176 @@
+package federation_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/federation"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis/redistest"
+)
+
+const clientID = "auth-service"
+
+func newManager(t *testing.T, issuerURL string, r *redistest.Client) federation.FederationManager {
+	t.Helper()
+	return federation.NewFederationManager(&config.OIDC{
+		StateExpiry: 10 * time.Minute,
+		Providers: map[string]*config.OIDCProvider{
+			"fake": {
+				IssuerURL:    issuerURL,
+				ClientID:     clientID,
+				ClientSecret: "secret",
+				RedirectURL:  "https://app.example.com/callback",
+				Scopes:       []string{"openid", "email", "profile"},
+			},
+		},
+	}, r)
+}
+
+// startLogin runs AuthCodeURL and returns the redirect query along with the
+// login state that was persisted to Redis.
+func startLogin(t *testing.T, manager federation.FederationManager, r *redistest.Client) (url.Values, string) {
+	t.Helper()
+
+	var stored string
+	r.On("Set", mock.Anything, mock.MatchedBy(func(k string) bool {
+		return strings.HasPrefix(k, constant.RedisOIDCState+":")
+	}), mock.Anything, 10*time.Minute).Run(func(args mock.Arguments) {
+		stored = args.String(2)
+	}).Return(nil).Once()
+
+	authURL, state, err := manager.AuthCodeURL(context.Background(), "fake")
+	require.NoError(t, err)
+
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	q := u.Query()
+	require.Equal(t, state, q.Get("state"))
+
+	return q, stored
+}
+
+func TestFederationManager_AuthCodeURL(t *testing.T) {
+	provider := newFakeProvider(t, clientID)
+	mockRedis := new(redistest.Client)
+	manager := newManager(t, provider.URL, mockRedis)
+
+	q, stored := startLogin(t, manager, mockRedis)
+
+	assert.Equal(t, clientID, q.Get("client_id"))
+	assert.Equal(t, "https://app.example.com/callback", q.Get("redirect_uri"))
+	assert.Equal(t, "openid email profile", q.Get("scope"))
+	assert.Equal(t, "S256", q.Get("code_challenge_method"))
+	assert.NotEmpty(t, q.Get("code_challenge"))
+	assert.NotEmpty(t, q.Get("nonce"))
+	assert.Contains(t, stored, q.Get("nonce"))
+	assert.Contains(t, stored, `"provider":"fake"`)
+	mockRedis.AssertExpectations(t)
+}
+
+func TestFederationManager_AuthCodeURL_UnknownProvider(t *testing.T) {
+	mockRedis := new(redistest.Client)
+	manager := newManager(t, "http://127.0.0.1:0", mockRedis)
+
+	_, _, err := manager.AuthCodeURL(context.Background(), "missing")
+	assert.ErrorIs(t, err, federation.ErrUnknownProvider)
+	mockRedis.AssertNotCalled(t, "Set")
+}
+
+func TestFederationManager_Exchange(t *testing.T) {
+	grant := fakeGrant{subject: "upstream-42", email: "name@gmail.com", verified: true, name: "name"}
+
+	tests := []struct {
+		name      string
+		tamper    func(p *fakeProvider)
+		code      string
+		expectErr error
+		expectAny bool
+	}{
+		{
+			name: "success",
+			code: "code1",
+		},
+		{
+			name:      "id token for another audience",
+			tamper:    func(p *fakeProvider) { p.audience = "someone-else" },
+			code:      "code1",
+			expectAny: true,
+		},
+		{
+			name:      "id token from another issuer",
+			tamper:    func(p *fakeProvider) { p.issuer = "https://evil.example.com" },
+			code:      "code1",
+			expectAny: true,
+		},
+		{
+			name:      "replayed nonce",
+			tamper:    func(p *fakeProvider) { p.nonce = "stale-nonce" },
+			code:      "code1",
+			expectErr: federation.ErrNonceMismatch,
+		},
+		{
+			name:      "unknown authorization code",
+			code:      "bogus",
+			expectAny: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newFakeProvider(t, clientID)
+			mockRedis := new(redistest.Client)
+			manager := newManager(t, provider.URL, mockRedis)
+
+			q, stored := startLogin(t, manager, mockRedis)
+			provider.authorize("code1", q.Get("code_challenge"), q.Get("nonce"), grant)
+			if tt.tamper != nil {
+				tt.tamper(provider)
+			}
+
+			key := constant.RedisOIDCState + ":" + q.Get("state")
+			mockRedis.On("GetDel", mock.Anything, key).Return(stored, nil).Once()
+
+			identity, err := manager.Exchange(context.Background(), q.Get("state"), tt.code)
+			switch {
+			case tt.expectErr != nil:
+				assert.ErrorIs(t, err, tt.expectErr)
+				assert.Nil(t, identity)
+			case tt.expectAny:
+				assert.Error(t, err)
+				assert.Nil(t, identity)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, &federation.Identity{
+					Provider:      "fake",
+					Subject:       "upstream-42",
+					Email:         "name@gmail.com",
+					EmailVerified: true,
+					Name:          "name",
+				}, identity)
+			}
+			mockRedis.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFederationManager_Exchange_InvalidState(t *testing.T) {
+	mockRedis := new(redistest.Client)
+	manager := newManager(t, "http://127.0.0.1:0", mockRedis)
+
+	mockRedis.On("GetDel", mock.Anything, constant.RedisOIDCState+":unknown").Return("", redis.Nil)
+
+	identity, err := manager.Exchange(context.Background(), "unknown", "code1")
+	assert.ErrorIs(t, err, federation.ErrInvalidState)
+	assert.Nil(t, identity)
+	mockRedis.AssertExpectations(t)
+}
@@ -0,0 +1,152 @@
+package federation_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+)
+
+// fakeProvider is a minimal in-process OIDC identity provider: discovery,
+// JWKS and a token endpoint that honours PKCE and echoes the login nonce.
+type fakeProvider struct {
+	*httptest.Server
+
+	t        *testing.T
+	key      *rsa.PrivateKey
+	clientID string
+
+	mu     sync.Mutex
+	grants map[string]fakeGrant
+
+	// Overrides applied to the next issued ID token.
+	issuer   string
+	audience string
+	nonce    string
+}
+
+type fakeGrant struct {
+	challenge string
+	nonce     string
+	subject   string
+	email     string
+	verified  bool
+	name      string
+}
+
+func newFakeProvider(t *testing.T, clientID string) *fakeProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate signing key: %v", err)
+	}
+
+	p := &fakeProvider{t: t, key: key, clientID: clientID, grants: map[string]fakeGrant{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /jwks", p.jwks)
+	mux.HandleFunc("POST /token", p.token)
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+
+	return p
+}
+
+// authorize stands in for the user signing in at the provider after being
+// redirected with the given PKCE challenge and nonce.
+func (p *fakeProvider) authorize(code string, challenge string, nonce string, g fakeGrant) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	g.challenge = challenge
+	g.nonce = nonce
+	p.grants[code] = g
+}
+
+func (p *fakeProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                                p.URL,
+		"authorization_endpoint":                p.URL + "/authorize",
+		"token_endpoint":                        p.URL + "/token",
+		"jwks_uri":                              p.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (p *fakeProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := p.key.PublicKey
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test-key",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *fakeProvider) token(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_ = r.ParseForm()
+	g, ok := p.grants[r.PostForm.Get("code")]
+	if !ok {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+	delete(p.grants, r.PostForm.Get("code"))
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	claims := jwtlib.MapClaims{
+		"iss":            valueOr(p.issuer, p.URL),
+		"aud":            valueOr(p.audience, p.clientID),
+		"sub":            g.subject,
+		"email":          g.email,
+		"email_verified": g.verified,
+		"name":           g.name,
+		"nonce":          valueOr(p.nonce, g.nonce),
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Minute).Unix(),
+	}
+	tok := jwtlib.NewWithClaims(jwtlib.SigningMethodRS256, claims)
+	tok.Header["kid"] = "test-key"
+	idToken, err := tok.SignedString(p.key)
+	if err != nil {
+		p.t.Errorf("failed to sign id token: %v", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "upstream-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     idToken,
+	})
+}
+
+func valueOr(v string, fallback string) string {
+	if v != "" {
+		return v
+	}
+	return fallback
+}
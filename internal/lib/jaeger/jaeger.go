@@ -2,36 +2,159 @@ package jaeger
 
 import (
 	"context"
-	"log"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
 
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	"go.opentelemetry.io/contrib/propagators/b3"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
-func Init(ctx context.Context, url string) func(context.Context) error {
-	exporter, err := otlptracehttp.New(ctx,
-		otlptracehttp.WithEndpoint(url),
-		otlptracehttp.WithInsecure(),
-	)
+// Exporters
+const (
+	ExporterOTLP = "otlp"
+	ExporterNone = "none"
+)
+
+// Propagators
+const (
+	PropagatorTraceContext = "tracecontext"
+	PropagatorBaggage      = "baggage"
+	PropagatorB3           = "b3"
+	PropagatorB3Multi      = "b3multi"
+)
+
+var (
+	ErrUnknownExporter   = errors.New("unknown trace exporter")
+	ErrUnknownPropagator = errors.New("unknown trace propagator")
+	ErrSampleRatio       = errors.New("trace sample ratio must be between 0 and 1")
+	ErrCAFile            = errors.New("no certificates found in trace exporter CA file")
+)
+
+// Init sets the global tracer provider and propagators from cfg and returns
+// a function that flushes pending spans. A mistake in cfg is returned as an
+// error, but an exporter that can't be set up only turns tracing off with a
+// warning: the service runs fine without it.
+func Init(ctx context.Context, cfg *config.Jaeger) (func(context.Context) error, error) {
+	propagator, err := newPropagator(cfg.Propagators)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return nil, fmt.Errorf("%w: %v", ErrSampleRatio, cfg.SampleRatio)
+	}
+
+	if cfg.Exporter != ExporterOTLP && cfg.Exporter != ExporterNone {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExporter, cfg.Exporter)
+	}
 
+	// Incoming trace context is passed on even when tracing is off.
+	otel.SetTextMapPropagator(propagator)
+
+	if cfg.Exporter == ExporterNone {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		logger.Info("Tracing disabled")
+		return noopShutdown, nil
+	}
+
+	exporter, err := newExporter(ctx, cfg)
 	if err != nil {
-		log.Fatalf("failed to create OTLP exporter: %v", err)
+		logger.Warn("Tracing disabled, failed to create OTLP exporter: %v", err)
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		return noopShutdown, nil
+	}
+
+	sampler := trace.TraceIDRatioBased(cfg.SampleRatio)
+	if cfg.ParentBased {
+		sampler = trace.ParentBased(sampler)
 	}
 
 	tp := trace.NewTracerProvider(
 		trace.WithBatcher(exporter),
-		trace.WithResource(resource.NewWithAttributes(
-			semconv.SchemaURL,
-			semconv.ServiceName(constant.ServiceName),
-		)),
+		trace.WithSampler(sampler),
+		trace.WithResource(newResource(cfg)),
 	)
 	otel.SetTracerProvider(tp)
-	otel.SetTextMapPropagator(propagation.TraceContext{})
 
-	return tp.Shutdown
+	return tp.Shutdown, nil
+}
+
+func noopShutdown(context.Context) error {
+	return nil
+}
+
+func newExporter(ctx context.Context, cfg *config.Jaeger) (*otlptrace.Exporter, error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.URL)}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(cfg.Headers))
+	}
+
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	} else {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		if cfg.CAFile != "" {
+			pem, err := os.ReadFile(cfg.CAFile)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, ErrCAFile
+			}
+		}
+		opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+	}
+
+	return otlptracehttp.New(ctx, opts...)
+}
+
+func newPropagator(names []string) (propagation.TextMapPropagator, error) {
+	var propagators []propagation.TextMapPropagator
+	for _, name := range names {
+		switch name {
+		case PropagatorTraceContext:
+			propagators = append(propagators, propagation.TraceContext{})
+		case PropagatorBaggage:
+			propagators = append(propagators, propagation.Baggage{})
+		case PropagatorB3:
+			propagators = append(propagators, b3.New())
+		case PropagatorB3Multi:
+			propagators = append(propagators, b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader)))
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrUnknownPropagator, name)
+		}
+	}
+	return propagation.NewCompositeTextMapPropagator(propagators...), nil
+}
+
+func newResource(cfg *config.Jaeger) *resource.Resource {
+	attrs := []attribute.KeyValue{semconv.ServiceName(constant.ServiceName)}
+	if cfg.ServiceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersion(cfg.ServiceVersion))
+	}
+	if cfg.Environment != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironment(cfg.Environment))
+	}
+	if cfg.PodName != "" {
+		attrs = append(attrs, semconv.K8SPodName(cfg.PodName))
+	}
+	for k, v := range cfg.ResourceAttributes {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...)
 }
@@ -0,0 +1,270 @@
+package jaeger_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/jaeger"
+)
+
+// collector is a fake OTLP HTTP trace endpoint.
+type collector struct {
+	*httptest.Server
+	mu         sync.Mutex
+	headers    http.Header
+	spans      []string
+	attributes map[string]string
+}
+
+func newCollector(t *testing.T) *collector {
+	c := &collector{attributes: map[string]string{}}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		req := &collectorpb.ExportTraceServiceRequest{}
+		if err := proto.Unmarshal(body, req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.headers = r.Header.Clone()
+		for _, rs := range req.GetResourceSpans() {
+			for _, kv := range rs.GetResource().GetAttributes() {
+				c.attributes[kv.GetKey()] = kv.GetValue().GetStringValue()
+			}
+			for _, ss := range rs.GetScopeSpans() {
+				for _, span := range ss.GetSpans() {
+					c.spans = append(c.spans, span.GetName())
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	t.Cleanup(c.Close)
+	return c
+}
+
+func (c *collector) endpoint() string {
+	return strings.TrimPrefix(c.URL, "http://")
+}
+
+func resetGlobals(t *testing.T) {
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	})
+}
+
+func tracingConfig(url string) *config.Jaeger {
+	return &config.Jaeger{
+		URL:         url,
+		Exporter:    jaeger.ExporterOTLP,
+		Insecure:    true,
+		SampleRatio: 1,
+		ParentBased: true,
+		Propagators: []string{jaeger.PropagatorTraceContext},
+	}
+}
+
+func TestInit_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(cfg *config.Jaeger)
+		expectedError error
+	}{
+		{
+			name:          "unknown exporter",
+			modify:        func(cfg *config.Jaeger) { cfg.Exporter = "zipkin" },
+			expectedError: jaeger.ErrUnknownExporter,
+		},
+		{
+			name:          "unknown propagator",
+			modify:        func(cfg *config.Jaeger) { cfg.Propagators = []string{"tracecontext", "xray"} },
+			expectedError: jaeger.ErrUnknownPropagator,
+		},
+		{
+			name:          "sample ratio above 1",
+			modify:        func(cfg *config.Jaeger) { cfg.SampleRatio = 1.5 },
+			expectedError: jaeger.ErrSampleRatio,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetGlobals(t)
+			cfg := tracingConfig("localhost:4318")
+			tt.modify(cfg)
+
+			_, err := jaeger.Init(context.Background(), cfg)
+
+			assert.ErrorIs(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestInit_Propagators(t *testing.T) {
+	tests := []struct {
+		name        string
+		propagators []string
+		expected    []string
+	}{
+		{
+			name:        "trace context and baggage",
+			propagators: []string{jaeger.PropagatorTraceContext, jaeger.PropagatorBaggage},
+			expected:    []string{"traceparent", "baggage"},
+		},
+		{
+			name:        "b3 single header",
+			propagators: []string{jaeger.PropagatorB3},
+			expected:    []string{"b3"},
+		},
+		{
+			name:        "b3 multiple headers",
+			propagators: []string{jaeger.PropagatorB3Multi},
+			expected:    []string{"x-b3-traceid", "x-b3-spanid", "x-b3-sampled"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetGlobals(t)
+			cfg := tracingConfig("localhost:4318")
+			cfg.Exporter = jaeger.ExporterNone
+			cfg.Propagators = tt.propagators
+
+			_, err := jaeger.Init(context.Background(), cfg)
+			require.NoError(t, err)
+
+			member, _ := baggage.NewMember("tenant", "acme")
+			bag, _ := baggage.New(member)
+			ctx := baggage.ContextWithBaggage(context.Background(), bag)
+			ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+				TraceID:    trace.TraceID{1},
+				SpanID:     trace.SpanID{1},
+				TraceFlags: trace.FlagsSampled,
+			}))
+
+			carrier := propagation.MapCarrier{}
+			otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+			assert.ElementsMatch(t, tt.expected, carrier.Keys())
+		})
+	}
+}
+
+func TestInit_ExporterNone(t *testing.T) {
+	resetGlobals(t)
+	cfg := tracingConfig("localhost:4318")
+	cfg.Exporter = jaeger.ExporterNone
+
+	shutdown, err := jaeger.Init(context.Background(), cfg)
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "op")
+	span.End()
+
+	assert.False(t, span.IsRecording())
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestInit_ExportsSpans(t *testing.T) {
+	resetGlobals(t)
+	c := newCollector(t)
+	cfg := tracingConfig(c.endpoint())
+	cfg.Headers = map[string]string{"x-api-key": "secret"}
+	cfg.ServiceVersion = "1.4.2"
+	cfg.Environment = "staging"
+	cfg.PodName = "authentication-7d9f"
+	cfg.ResourceAttributes = map[string]string{"team": "identity"}
+
+	shutdown, err := jaeger.Init(context.Background(), cfg)
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "op")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	assert.Equal(t, []string{"op"}, c.spans)
+	assert.Equal(t, "secret", c.headers.Get("x-api-key"))
+	assert.Equal(t, "Authentication Service", c.attributes["service.name"])
+	assert.Equal(t, "1.4.2", c.attributes["service.version"])
+	assert.Equal(t, "staging", c.attributes["deployment.environment"])
+	assert.Equal(t, "authentication-7d9f", c.attributes["k8s.pod.name"])
+	assert.Equal(t, "identity", c.attributes["team"])
+}
+
+func TestInit_Sampling(t *testing.T) {
+	sampledParent := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	}))
+
+	tests := []struct {
+		name            string
+		sampleRatio     float64
+		parentBased     bool
+		ctx             context.Context
+		expectedSampled bool
+	}{
+		{"ratio 1 samples new traces", 1, true, context.Background(), true},
+		{"ratio 0 drops new traces", 0, true, context.Background(), false},
+		{"parent based follows a sampled caller", 0, true, sampledParent, true},
+		{"without parent based the ratio wins", 0, false, sampledParent, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetGlobals(t)
+			cfg := tracingConfig(newCollector(t).endpoint())
+			cfg.SampleRatio = tt.sampleRatio
+			cfg.ParentBased = tt.parentBased
+
+			shutdown, err := jaeger.Init(context.Background(), cfg)
+			require.NoError(t, err)
+			defer shutdown(context.Background())
+
+			_, span := otel.Tracer("test").Start(tt.ctx, "op")
+			defer span.End()
+
+			assert.Equal(t, tt.expectedSampled, span.SpanContext().IsSampled())
+		})
+	}
+}
+
+func TestInit_ExporterFailureDisablesTracing(t *testing.T) {
+	resetGlobals(t)
+	cfg := tracingConfig("localhost:4318")
+	cfg.Insecure = false
+	cfg.CAFile = filepath.Join(t.TempDir(), "missing-ca.pem")
+
+	shutdown, err := jaeger.Init(context.Background(), cfg)
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "op")
+	span.End()
+
+	assert.False(t, span.IsRecording())
+	assert.NoError(t, shutdown(context.Background()))
+}
@@ -2,39 +2,100 @@ package jwt
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/rbac"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis"
+	"go.opentelemetry.io/otel/metric"
 )
 
 type JWTManager interface {
 	NewToken(id uint, username string) (string, error)
+	NewScopedToken(id uint, username string, scope string) (string, error)
+	NewProfileToken(id uint, username string, profile *Profile) (string, error)
+	NewExchangedToken(exchange *TokenExchange) (string, int64, error)
 	ParseToken(token string) (jwt.MapClaims, error)
 	AddToBlacklist(ctx context.Context, jti string, expiry int64) error
 	IsBlacklisted(ctx context.Context, jti string) bool
 }
 
+// TokenExchange describes an RFC 8693 exchange: a token for Subject, optionally
+// used by Actor, restricted to Audience and Scope.
+type TokenExchange struct {
+	Subject  jwt.MapClaims
+	Actor    jwt.MapClaims
+	Audience string
+	Scope    string
+}
+
+// Profile is the user profile signed into tokens in degraded mode, so the
+// token alone can stand in for the user service while it is down. Claims are
+// signed, not encrypted: anyone holding the token can read it.
+type Profile struct {
+	Name      string  `json:"name"`
+	Email     string  `json:"email"`
+	Image     *string `json:"image,omitempty"`
+	CreatedAt *string `json:"created_at,omitempty"`
+	UpdatedAt *string `json:"updated_at,omitempty"`
+}
+
+const profileClaim = "profile"
+
+var (
+	ErrAudienceNotAllowed = errors.New("audience is not allowed for token exchange")
+	ErrWrongAudience      = errors.New("token was issued to another audience")
+)
+
 type jwtManager struct {
-	secret []byte
-	expiry time.Duration
-	redis  redis.RedisService
+	secret            []byte
+	expiry            time.Duration
+	exchangeExpiry    time.Duration
+	exchangeAudiences []string
+	redis             redis.RedisService
+	policy            rbac.Policy
 }
 
-func NewJWTManager(cfg *config.JWT, redis redis.RedisService) JWTManager {
+// NewJWTManager creates a JWT manager. policy may be nil, in which case tokens
+// are issued without role and scope claims.
+func NewJWTManager(cfg *config.JWT, redis redis.RedisService, policy rbac.Policy) JWTManager {
 	return &jwtManager{
-		secret: []byte(cfg.Secret),
-		expiry: cfg.Expiry,
-		redis:  redis,
+		secret:            []byte(cfg.Secret),
+		expiry:            cfg.Expiry,
+		exchangeExpiry:    cfg.ExchangeExpiry,
+		exchangeAudiences: cfg.ExchangeAudiences,
+		redis:             redis,
+		policy:            policy,
 	}
 }
 
 func (j *jwtManager) NewToken(id uint, username string) (string, error) {
+	return j.newToken(id, username, nil, nil)
+}
+
+// NewScopedToken issues an access token restricted to scope, for grants that
+// asked for less than the user's roles allow. The caller narrows scope to what
+// the user holds first.
+func (j *jwtManager) NewScopedToken(id uint, username string, scope string) (string, error) {
+	return j.newToken(id, username, &scope, nil)
+}
+
+// NewProfileToken issues an access token that also carries the user's
+// profile, see ProfileFromClaims.
+func (j *jwtManager) NewProfileToken(id uint, username string, profile *Profile) (string, error) {
+	return j.newToken(id, username, nil, profile)
+}
+
+func (j *jwtManager) newToken(id uint, username string, scope *string, profile *Profile) (string, error) {
 	token := jwt.New(jwt.SigningMethodHS256)
 	expiry := time.Now().Add(j.expiry).Unix()
 
@@ -44,7 +105,63 @@ func (j *jwtManager) NewToken(id uint, username string) (string, error) {
 	claims["exp"] = expiry
 	claims["jti"] = uuid.New().String()
 
-	return token.SignedString(j.secret)
+	// The scope claim is always set once RBAC is enabled, even when empty, as
+	// a token without one is treated as unrestricted by token exchange.
+	if j.policy != nil {
+		roles := j.policy.Roles(id)
+		claims["roles"] = roles
+		claims["scope"] = strings.Join(j.policy.Permissions(roles), " ")
+	}
+	if scope != nil {
+		claims["scope"] = *scope
+	}
+	if profile != nil {
+		claims[profileClaim] = profile
+	}
+
+	signed, err := token.SignedString(j.secret)
+	if err != nil {
+		return "", err
+	}
+	metrics.TokensIssued.Add(context.Background(), 1, metric.WithAttributes(metrics.TypeKey.String(constant.MetricTokenAccess)))
+	return signed, nil
+}
+
+// NewExchangedToken mints a short-lived token for the exchange subject. It
+// never outlives the subject token and returns its expiry as a unix timestamp.
+func (j *jwtManager) NewExchangedToken(exchange *TokenExchange) (string, int64, error) {
+	if len(j.exchangeAudiences) > 0 && !slices.Contains(j.exchangeAudiences, exchange.Audience) {
+		return "", 0, ErrAudienceNotAllowed
+	}
+
+	token := jwt.New(jwt.SigningMethodHS256)
+	expiry := time.Now().Add(j.exchangeExpiry).Unix()
+	if exp, err := exchange.Subject.GetExpirationTime(); err == nil && exp != nil && exp.Unix() < expiry {
+		expiry = exp.Unix()
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	claims["id"] = exchange.Subject["id"]
+	claims["username"] = exchange.Subject["username"]
+	claims["exp"] = expiry
+	claims["jti"] = uuid.New().String()
+	claims["aud"] = exchange.Audience
+	if _, restricted := exchange.Subject["scope"]; restricted || exchange.Scope != "" {
+		claims["scope"] = exchange.Scope
+	}
+	if act := actorClaim(exchange); act != nil {
+		claims["act"] = act
+	}
+	if profile, ok := exchange.Subject[profileClaim]; ok {
+		claims[profileClaim] = profile
+	}
+
+	signed, err := token.SignedString(j.secret)
+	if err != nil {
+		return "", 0, err
+	}
+	metrics.TokensIssued.Add(context.Background(), 1, metric.WithAttributes(metrics.TypeKey.String(constant.MetricTokenExchange)))
+	return signed, expiry, nil
 }
 
 func (j *jwtManager) ParseToken(token string) (jwt.MapClaims, error) {
@@ -72,11 +189,80 @@ func (j *jwtManager) AddToBlacklist(ctx context.Context, jti string, expiry int6
 	if exp <= 0 {
 		return nil // already expired
 	}
-	return j.redis.Set(ctx, key, "", time.Duration(exp)*time.Second)
+	if err := j.redis.Set(ctx, key, "", time.Duration(exp)*time.Second); err != nil {
+		return err
+	}
+	metrics.TokensRevoked.Add(ctx, 1, metric.WithAttributes(metrics.TypeKey.String(constant.MetricTokenAccess)))
+	return nil
 }
 
 func (j *jwtManager) IsBlacklisted(ctx context.Context, jti string) bool {
 	key := fmt.Sprintf("%s:%s", constant.RedisTokenBlacklist, jti)
 	_, err := j.redis.Get(ctx, key)
+
+	result := constant.MetricBlacklistHit
+	switch {
+	case errors.Is(err, redis.Nil):
+		result = constant.MetricBlacklistMiss
+	case err != nil:
+		result = constant.MetricBlacklistError
+	}
+	metrics.BlacklistLookups.Add(ctx, 1, metric.WithAttributes(metrics.ResultKey.String(result)))
+
 	return err == nil
 }
+
+// ProfileFromClaims returns the profile signed into a token by
+// NewProfileToken, or nil when the token carries none.
+func ProfileFromClaims(claims jwt.MapClaims) *Profile {
+	raw, ok := claims[profileClaim]
+	if !ok {
+		return nil
+	}
+
+	// The claim comes back from parsing as a generic map.
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	profile := &Profile{}
+	if err := json.Unmarshal(b, profile); err != nil || profile.Email == "" {
+		return nil
+	}
+	return profile
+}
+
+// VerificationFailureReason classifies a ParseToken error for metrics:
+// expired, bad_signature, malformed, wrong_audience or, for anything else,
+// invalid.
+func VerificationFailureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrWrongAudience), errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return constant.MetricVerifyWrongAudience
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return constant.MetricVerifyExpired
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return constant.MetricVerifyBadSignature
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return constant.MetricVerifyMalformed
+	}
+	return constant.MetricVerifyInvalid
+}
+
+// actorClaim builds the RFC 8693 "act" chain: the current actor at the top
+// with any actors already recorded on the subject token nested beneath it.
+func actorClaim(exchange *TokenExchange) any {
+	prior := exchange.Subject["act"]
+	if exchange.Actor == nil {
+		return prior
+	}
+
+	act := map[string]any{
+		"sub":      fmt.Sprint(exchange.Actor["id"]),
+		"username": exchange.Actor["username"],
+	}
+	if prior != nil {
+		act["act"] = prior
+	}
+	return act
+}
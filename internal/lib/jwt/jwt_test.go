@@ -3,6 +3,8 @@ package jwt_test
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -13,12 +15,17 @@ import (
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/jwt"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics/metricstest"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/rbac"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis/redistest"
 )
 
 func TestJWTManager_NewAndParseToken(t *testing.T) {
-	mockRedis := new(MockRedisClient)
+	mockRedis := new(redistest.Client)
 	cfg := &config.JWT{Secret: "test-secret", Expiry: time.Hour}
-	manager := jwt.NewJWTManager(cfg, mockRedis)
+	manager := jwt.NewJWTManager(cfg, mockRedis, nil)
 
 	token, err := manager.NewToken(123, "alice")
 	assert.NoError(t, err)
@@ -33,9 +40,9 @@ func TestJWTManager_NewAndParseToken(t *testing.T) {
 }
 
 func TestJWTManager_ParseTokenErrors(t *testing.T) {
-	mockRedis := new(MockRedisClient)
+	mockRedis := new(redistest.Client)
 	cfg := &config.JWT{Secret: "test-secret", Expiry: time.Hour}
-	manager := jwt.NewJWTManager(cfg, mockRedis)
+	manager := jwt.NewJWTManager(cfg, mockRedis, nil)
 
 	tests := []struct {
 		name      string
@@ -73,9 +80,9 @@ func TestJWTManager_ParseTokenErrors(t *testing.T) {
 }
 
 func TestJWTManager_AddToBlacklist(t *testing.T) {
-	mockRedis := new(MockRedisClient)
+	mockRedis := new(redistest.Client)
 	cfg := &config.JWT{Secret: "test-secret", Expiry: time.Hour}
-	manager := jwt.NewJWTManager(cfg, mockRedis)
+	manager := jwt.NewJWTManager(cfg, mockRedis, nil)
 
 	jti := "test-jti"
 	expiry := time.Now().Add(10 * time.Second).Unix()
@@ -89,9 +96,9 @@ func TestJWTManager_AddToBlacklist(t *testing.T) {
 }
 
 func TestJWTManager_AddToBlacklist_ExpiredToken(t *testing.T) {
-	mockRedis := new(MockRedisClient)
+	mockRedis := new(redistest.Client)
 	cfg := &config.JWT{Secret: "test-secret", Expiry: time.Hour}
-	manager := jwt.NewJWTManager(cfg, mockRedis)
+	manager := jwt.NewJWTManager(cfg, mockRedis, nil)
 
 	// Expired token, should not call redis.Set
 	jti := "expired-jti"
@@ -104,36 +111,277 @@ func TestJWTManager_AddToBlacklist_ExpiredToken(t *testing.T) {
 
 func TestJWTManager_IsBlacklisted(t *testing.T) {
 	tests := []struct {
-		name      string
-		redisResp error
-		expected  bool
+		name           string
+		redisResp      error
+		expected       bool
+		expectedLookup string
 	}{
 		{
-			name:      "token is blacklisted",
-			redisResp: nil,
-			expected:  true,
+			name:           "token is blacklisted",
+			redisResp:      nil,
+			expected:       true,
+			expectedLookup: constant.MetricBlacklistHit,
+		},
+		{
+			name:           "token not blacklisted",
+			redisResp:      redis.Nil,
+			expected:       false,
+			expectedLookup: constant.MetricBlacklistMiss,
 		},
 		{
-			name:      "token not blacklisted",
-			redisResp: errors.New("not found"),
-			expected:  false,
+			name:           "redis error",
+			redisResp:      errors.New("connection refused"),
+			expected:       false,
+			expectedLookup: constant.MetricBlacklistError,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockRedis := new(MockRedisClient)
+			mockRedis := new(redistest.Client)
 			cfg := &config.JWT{Secret: "test-secret", Expiry: time.Hour}
-			manager := jwt.NewJWTManager(cfg, mockRedis)
+			manager := jwt.NewJWTManager(cfg, mockRedis, nil)
 
 			jti := "some-jti"
 			key := constant.RedisTokenBlacklist + ":" + jti
 
 			mockRedis.On("Get", mock.Anything, key).Return("", tt.redisResp)
+			reader := metricstest.New(t)
 
 			result := manager.IsBlacklisted(context.Background(), jti)
 			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, 1.0, reader.Value(t, "auth_blacklist_lookups_total", metrics.ResultKey.String(tt.expectedLookup)))
 			mockRedis.AssertExpectations(t)
 		})
 	}
 }
+
+func TestVerificationFailureReason(t *testing.T) {
+	secret := []byte("test-secret")
+	manager := jwt.NewJWTManager(&config.JWT{Secret: string(secret), Expiry: time.Hour}, new(redistest.Client), nil)
+	sign := func(method jwtlib.SigningMethod, key any, claims jwtlib.MapClaims) string {
+		ss, err := jwtlib.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ss
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		expected string
+	}{
+		{
+			name:     "expired",
+			token:    sign(jwtlib.SigningMethodHS256, secret, jwtlib.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}),
+			expected: constant.MetricVerifyExpired,
+		},
+		{
+			name:     "signed with another secret",
+			token:    sign(jwtlib.SigningMethodHS256, []byte("other-secret"), jwtlib.MapClaims{}),
+			expected: constant.MetricVerifyBadSignature,
+		},
+		{
+			name:     "unsigned",
+			token:    sign(jwtlib.SigningMethodNone, jwtlib.UnsafeAllowNoneSignatureType, jwtlib.MapClaims{}),
+			expected: constant.MetricVerifyBadSignature,
+		},
+		{
+			name:     "malformed",
+			token:    "not-a-jwt",
+			expected: constant.MetricVerifyMalformed,
+		},
+		{
+			name:     "not valid yet",
+			token:    sign(jwtlib.SigningMethodHS256, secret, jwtlib.MapClaims{"nbf": time.Now().Add(time.Hour).Unix()}),
+			expected: constant.MetricVerifyInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := manager.ParseToken(tt.token)
+
+			assert.Error(t, err)
+			assert.Equal(t, tt.expected, jwt.VerificationFailureReason(err))
+		})
+	}
+}
+
+func TestJWTManager_NewExchangedToken(t *testing.T) {
+	mockRedis := new(redistest.Client)
+	cfg := &config.JWT{
+		Secret:            "test-secret",
+		Expiry:            time.Hour,
+		ExchangeExpiry:    5 * time.Minute,
+		ExchangeAudiences: []string{"video-service"},
+	}
+	manager := jwt.NewJWTManager(cfg, mockRedis, nil)
+
+	subjectExp := float64(time.Now().Add(time.Hour).Unix())
+	soonExp := float64(time.Now().Add(time.Minute).Unix())
+
+	tests := []struct {
+		name        string
+		exchange    *jwt.TokenExchange
+		expectErr   error
+		expectedAct any
+		maxExpiry   time.Duration
+	}{
+		{
+			name: "without actor",
+			exchange: &jwt.TokenExchange{
+				Subject:  jwtlib.MapClaims{"id": float64(1), "username": "alice", "exp": subjectExp},
+				Audience: "video-service",
+				Scope:    "videos:read",
+			},
+			expectedAct: nil,
+			maxExpiry:   5 * time.Minute,
+		},
+		{
+			name: "actor on top of existing chain",
+			exchange: &jwt.TokenExchange{
+				Subject: jwtlib.MapClaims{
+					"id": float64(1), "username": "alice", "exp": subjectExp,
+					"act": map[string]any{"sub": "2", "username": "gateway"},
+				},
+				Actor:    jwtlib.MapClaims{"id": float64(3), "username": "upload-service"},
+				Audience: "video-service",
+			},
+			expectedAct: map[string]any{
+				"sub":      "3",
+				"username": "upload-service",
+				"act":      map[string]any{"sub": "2", "username": "gateway"},
+			},
+			maxExpiry: 5 * time.Minute,
+		},
+		{
+			name: "never outlives subject token",
+			exchange: &jwt.TokenExchange{
+				Subject:  jwtlib.MapClaims{"id": float64(1), "username": "alice", "exp": soonExp},
+				Audience: "video-service",
+			},
+			maxExpiry: time.Minute,
+		},
+		{
+			name: "audience not allowed",
+			exchange: &jwt.TokenExchange{
+				Subject:  jwtlib.MapClaims{"id": float64(1), "username": "alice", "exp": subjectExp},
+				Audience: "billing-service",
+			},
+			expectErr: jwt.ErrAudienceNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, expiry, err := manager.NewExchangedToken(tt.exchange)
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			claims, err := manager.ParseToken(token)
+			assert.NoError(t, err)
+			assert.Equal(t, float64(1), claims["id"])
+			assert.Equal(t, "alice", claims["username"])
+			assert.Equal(t, tt.exchange.Audience, claims["aud"])
+			assert.Equal(t, float64(expiry), claims["exp"])
+			assert.LessOrEqual(t, expiry, time.Now().Add(tt.maxExpiry).Unix())
+			assert.Equal(t, tt.expectedAct, claims["act"])
+			if tt.exchange.Scope != "" {
+				assert.Equal(t, tt.exchange.Scope, claims["scope"])
+			} else {
+				assert.NotContains(t, claims, "scope")
+			}
+		})
+	}
+}
+
+func TestJWTManager_NewToken_WithPolicy(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "policy.json")
+	err := os.WriteFile(policyFile, []byte(`{
+		"default_roles": ["viewer"],
+		"roles": {"viewer": ["videos:read"], "uploader": ["videos:read", "videos:upload"]},
+		"users": {"1": ["uploader"], "2": []}
+	}`), 0o600)
+	assert.NoError(t, err)
+
+	policy, err := rbac.NewPolicy(&config.RBAC{PolicyFile: policyFile})
+	assert.NoError(t, err)
+
+	manager := jwt.NewJWTManager(&config.JWT{Secret: "test-secret", Expiry: time.Hour}, new(redistest.Client), policy)
+
+	tests := []struct {
+		name          string
+		id            uint
+		expectedRoles []any
+		expectedScope string
+	}{
+		{name: "mapped user", id: 1, expectedRoles: []any{"uploader"}, expectedScope: "videos:read videos:upload"},
+		{name: "default roles", id: 3, expectedRoles: []any{"viewer"}, expectedScope: "videos:read"},
+		{name: "user without roles", id: 2, expectedRoles: []any{}, expectedScope: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := manager.NewToken(tt.id, "alice")
+			assert.NoError(t, err)
+
+			claims, err := manager.ParseToken(token)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedRoles, claims["roles"])
+			assert.Equal(t, tt.expectedScope, claims["scope"])
+		})
+	}
+}
+
+func TestJWTManager_NewScopedToken(t *testing.T) {
+	policyFile := filepath.Join(t.TempDir(), "policy.json")
+	err := os.WriteFile(policyFile, []byte(`{
+		"default_roles": ["uploader"],
+		"roles": {"uploader": ["videos:read", "videos:upload"]}
+	}`), 0o600)
+	assert.NoError(t, err)
+
+	policy, err := rbac.NewPolicy(&config.RBAC{PolicyFile: policyFile})
+	assert.NoError(t, err)
+
+	manager := jwt.NewJWTManager(&config.JWT{Secret: "test-secret", Expiry: time.Hour}, new(redistest.Client), policy)
+
+	token, err := manager.NewScopedToken(1, "alice", "videos:read")
+	assert.NoError(t, err)
+
+	claims, err := manager.ParseToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"uploader"}, claims["roles"])
+	assert.Equal(t, "videos:read", claims["scope"])
+}
+
+func TestJWTManager_NewProfileToken(t *testing.T) {
+	cfg := &config.JWT{Secret: "test-secret", Expiry: time.Hour, ExchangeExpiry: time.Minute}
+	manager := jwt.NewJWTManager(cfg, new(redistest.Client), nil)
+	createdAt := "2024-01-01T00:00:00Z"
+	profile := &jwt.Profile{Name: "Alice", Email: "alice@example.com", CreatedAt: &createdAt}
+
+	token, err := manager.NewProfileToken(1, "alice@example.com", profile)
+	assert.NoError(t, err)
+	claims, err := manager.ParseToken(token)
+	assert.NoError(t, err)
+	assert.Equal(t, profile, jwt.ProfileFromClaims(claims))
+
+	// Exchanged tokens keep the subject's profile.
+	exchanged, _, err := manager.NewExchangedToken(&jwt.TokenExchange{Subject: claims, Audience: "video-service"})
+	assert.NoError(t, err)
+	exchangedClaims, err := manager.ParseToken(exchanged)
+	assert.NoError(t, err)
+	assert.Equal(t, profile, jwt.ProfileFromClaims(exchangedClaims))
+
+	plain, err := manager.NewToken(1, "alice@example.com")
+	assert.NoError(t, err)
+	plainClaims, err := manager.ParseToken(plain)
+	assert.NoError(t, err)
+	assert.Nil(t, jwt.ProfileFromClaims(plainClaims))
+}
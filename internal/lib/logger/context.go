@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Structured field names attached to request-scoped log lines
+const (
+	FieldRequestID    = "request_id"
+	FieldGRPCMethod   = "grpc_method"
+	FieldHTTPMethod   = "http_method"
+	FieldHTTPPath     = "http_path"
+	FieldPeer         = "peer"
+	FieldPeerIdentity = "peer_identity"
+	FieldUserID       = "user_id"
+	FieldTraceID      = "trace_id"
+	FieldSpanID       = "span_id"
+)
+
+// requestLogger is shared by everything handling one request, so fields added
+// deep in a handler, like the user ID, also show up on the access log line.
+type requestLogger struct {
+	mu     sync.RWMutex
+	logger zerolog.Logger
+}
+
+type requestLoggerKey struct{}
+
+// NewContext starts request-scoped logging for ctx with the given fields.
+func NewContext(ctx context.Context, fields map[string]any) context.Context {
+	return context.WithValue(ctx, requestLoggerKey{}, &requestLogger{
+		logger: log.Logger.With().Fields(fields).Logger(),
+	})
+}
+
+// AddField attaches a field to every later log line of the request in ctx.
+// It does nothing outside of a request.
+func AddField(ctx context.Context, key string, value any) {
+	r, ok := ctx.Value(requestLoggerKey{}).(*requestLogger)
+	if !ok {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logger = r.logger.With().Interface(key, value).Logger()
+}
+
+// Ctx returns the logger for the request in ctx, or the global logger, with
+// the trace and span IDs of the current OpenTelemetry span.
+func Ctx(ctx context.Context) *zerolog.Logger {
+	l := log.Logger
+	if r, ok := ctx.Value(requestLoggerKey{}).(*requestLogger); ok {
+		r.mu.RLock()
+		l = r.logger
+		r.mu.RUnlock()
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		l = l.With().
+			Str(FieldTraceID, sc.TraceID().String()).
+			Str(FieldSpanID, sc.SpanID().String()).
+			Logger()
+	}
+
+	return &l
+}
+
+func InfoCtx(ctx context.Context, format string, v ...interface{}) {
+	write(Ctx(ctx).Info(), format, v)
+}
+
+func WarnCtx(ctx context.Context, format string, v ...interface{}) {
+	write(Ctx(ctx).Warn(), format, v)
+}
+
+func DebugCtx(ctx context.Context, format string, v ...interface{}) {
+	write(Ctx(ctx).Debug(), format, v)
+}
+
+func ErrorCtx(ctx context.Context, format string, v ...interface{}) {
+	write(Ctx(ctx).Error(), format, v)
+}
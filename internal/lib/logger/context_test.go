@@ -0,0 +1,96 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+)
+
+// captureLogs points the global logger at a buffer for the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { log.Logger = previous })
+	return &buf
+}
+
+func lastLine(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(lines[len(lines)-1], &line))
+	return line
+}
+
+func TestCtx(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+
+	t.Run("request fields", func(t *testing.T) {
+		buf := captureLogs(t)
+		ctx := logger.NewContext(context.Background(), map[string]any{
+			logger.FieldRequestID:  "req-1",
+			logger.FieldGRPCMethod: "/authentication.AuthenticationService/Login",
+		})
+
+		logger.InfoCtx(ctx, "hello %s", "world")
+
+		line := lastLine(t, buf)
+		assert.Equal(t, "hello world", line["message"])
+		assert.Equal(t, "info", line["level"])
+		assert.Equal(t, "req-1", line[logger.FieldRequestID])
+		assert.Equal(t, "/authentication.AuthenticationService/Login", line[logger.FieldGRPCMethod])
+		assert.NotContains(t, line, logger.FieldTraceID)
+	})
+
+	t.Run("fields added later are shared", func(t *testing.T) {
+		buf := captureLogs(t)
+		ctx := logger.NewContext(context.Background(), map[string]any{logger.FieldRequestID: "req-1"})
+		child, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		logger.AddField(child, logger.FieldUserID, 42)
+		logger.ErrorCtx(ctx, "failed")
+
+		line := lastLine(t, buf)
+		assert.Equal(t, "error", line["level"])
+		assert.Equal(t, float64(42), line[logger.FieldUserID])
+	})
+
+	t.Run("trace and span IDs", func(t *testing.T) {
+		buf := captureLogs(t)
+		ctx := trace.ContextWithSpanContext(context.Background(), spanCtx)
+
+		logger.WarnCtx(ctx, "slow")
+
+		line := lastLine(t, buf)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", line[logger.FieldTraceID])
+		assert.Equal(t, "00f067aa0ba902b7", line[logger.FieldSpanID])
+	})
+
+	t.Run("outside of a request", func(t *testing.T) {
+		buf := captureLogs(t)
+		ctx := context.Background()
+
+		logger.AddField(ctx, logger.FieldUserID, 42)
+		logger.InfoCtx(ctx, "startup")
+
+		line := lastLine(t, buf)
+		assert.Equal(t, "startup", line["message"])
+		assert.NotContains(t, line, logger.FieldUserID)
+	})
+}
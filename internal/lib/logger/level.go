@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/rs/zerolog"
+)
+
+type levelBody struct {
+	Level string `json:"level"`
+}
+
+// LevelHandler reports the global log level on GET and changes it on PUT with
+// a {"level": "debug"} body, until the next restart.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var body levelBody
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeLevelError(w, http.StatusBadRequest, "invalid request body")
+				return
+			}
+			level, err := ParseLevel(body.Level)
+			if err != nil {
+				writeLevelError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if previous := zerolog.GlobalLevel(); previous != level {
+				zerolog.SetGlobalLevel(level)
+				Warn("Log level changed from %q to %q", previous, level)
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeLevelError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(&levelBody{Level: zerolog.GlobalLevel().String()})
+	})
+}
+
+func writeLevelError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
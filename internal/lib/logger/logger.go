@@ -1,39 +1,136 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+
+	consoleTimeFormat = "02/01/2006, 3:04:05 PM"
+)
+
+var ErrUnknownFormat = errors.New("unknown log format")
+
+// Options configures the global logger.
+type Options struct {
+	// Level is a zerolog level name such as "debug", "info" or "warn".
+	Level string
+	// Format is FormatConsole for humans or FormatJSON for log pipelines.
+	Format string
+	// Output is "stderr" or "stdout", or "none" to only log to File.
+	Output string
+	// File, when set, also writes logs to this file, rotated by size.
+	File           string
+	FileMaxSizeMB  int
+	FileMaxBackups int
+	FileMaxAgeDays int
+	// SampleInfo keeps one in every SampleInfo info and debug lines. Warnings
+	// and errors are never sampled. 0 or 1 logs everything.
+	SampleInfo uint32
+}
+
+// Init sets up console logging to stderr, used until Configure runs with the
+// loaded config.
 func Init() {
 	log.Logger = log.Output(zerolog.ConsoleWriter{
 		Out:        os.Stderr,
-		TimeFormat: "02/01/2006, 3:04:05 PM",
+		TimeFormat: consoleTimeFormat,
 	})
 }
 
+// Configure replaces the global logger according to opts. On error the
+// current logger is left untouched.
+func Configure(opts *Options) error {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return err
+	}
+	if opts.Format != FormatConsole && opts.Format != FormatJSON {
+		return fmt.Errorf("%w: %q", ErrUnknownFormat, opts.Format)
+	}
+
+	var writers []io.Writer
+	switch opts.Output {
+	case "", "stderr":
+		writers = append(writers, formatWriter(os.Stderr, opts.Format, false))
+	case "stdout":
+		writers = append(writers, formatWriter(os.Stdout, opts.Format, false))
+	case "none":
+	default:
+		return fmt.Errorf("unknown log output %q", opts.Output)
+	}
+	if opts.File != "" {
+		file := &lumberjack.Logger{
+			Filename:   opts.File,
+			MaxSize:    opts.FileMaxSizeMB,
+			MaxBackups: opts.FileMaxBackups,
+			MaxAge:     opts.FileMaxAgeDays,
+		}
+		writers = append(writers, formatWriter(file, opts.Format, true))
+	}
+
+	l := zerolog.New(zerolog.MultiLevelWriter(writers...)).With().Timestamp().Logger()
+	if opts.SampleInfo > 1 {
+		sampler := &zerolog.BasicSampler{N: opts.SampleInfo}
+		l = l.Sample(&zerolog.LevelSampler{DebugSampler: sampler, InfoSampler: sampler})
+	}
+
+	zerolog.SetGlobalLevel(level)
+	log.Logger = l
+	return nil
+}
+
+func formatWriter(w io.Writer, format string, noColor bool) io.Writer {
+	if format == FormatJSON {
+		return w
+	}
+	return zerolog.ConsoleWriter{Out: w, TimeFormat: consoleTimeFormat, NoColor: noColor}
+}
+
+// ParseLevel is zerolog.ParseLevel limited to levels that make sense as a
+// minimum, with an empty string meaning info.
+func ParseLevel(level string) (zerolog.Level, error) {
+	if level == "" {
+		return zerolog.InfoLevel, nil
+	}
+
+	l, err := zerolog.ParseLevel(strings.ToLower(level))
+	if err != nil || l == zerolog.NoLevel {
+		return zerolog.NoLevel, fmt.Errorf("unknown log level %q", level)
+	}
+	return l, nil
+}
+
 func Info(format string, v ...interface{}) {
-	log.Info().Msgf(format, v...)
+	write(log.Info(), format, v)
 }
 
 func Warn(format string, v ...interface{}) {
-	log.Warn().Msgf(format, v...)
+	write(log.Warn(), format, v)
 }
 
 func Debug(format string, v ...interface{}) {
-	log.Debug().Msgf(format, v...)
+	write(log.Debug(), format, v)
 }
 
 func Error(format string, v ...interface{}) {
-	log.Error().Msgf(format, v...)
+	write(log.Error(), format, v)
 }
 
 func Fatal(format string, v ...interface{}) {
-	log.Fatal().Msgf(format, v...)
+	write(log.Fatal(), format, v)
 }
 
 func Panic(format string, v ...interface{}) {
-	log.Panic().Msgf(format, v...)
+	write(log.Panic(), format, v)
 }
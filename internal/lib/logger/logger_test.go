@@ -0,0 +1,159 @@
+package logger_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+)
+
+// restoreLogger undoes Configure's changes to the global logger and level.
+func restoreLogger(t *testing.T) {
+	previous, level := log.Logger, zerolog.GlobalLevel()
+	t.Cleanup(func() {
+		log.Logger = previous
+		zerolog.SetGlobalLevel(level)
+	})
+}
+
+func readLines(t *testing.T, file string) []map[string]any {
+	t.Helper()
+
+	b, err := os.ReadFile(file)
+	require.NoError(t, err)
+
+	var lines []map[string]any
+	for _, raw := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		if raw == "" {
+			continue
+		}
+		var line map[string]any
+		require.NoError(t, json.Unmarshal([]byte(raw), &line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestConfigure(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      logger.Options
+		log       func()
+		expected  []string
+		expectErr bool
+	}{
+		{
+			name: "JSON to file at warn level",
+			opts: logger.Options{Level: "warn", Format: logger.FormatJSON, Output: "none"},
+			log: func() {
+				logger.Info("dropped")
+				logger.Warn("kept %d", 1)
+				logger.Error("kept %d", 2)
+			},
+			expected: []string{"kept 1", "kept 2"},
+		},
+		{
+			name: "level is case insensitive",
+			opts: logger.Options{Level: "DEBUG", Format: logger.FormatJSON, Output: "none"},
+			log: func() {
+				logger.Debug("kept")
+			},
+			expected: []string{"kept"},
+		},
+		{
+			name: "sampled info logs",
+			opts: logger.Options{Level: "info", Format: logger.FormatJSON, Output: "none", SampleInfo: 3},
+			log: func() {
+				for range 6 {
+					logger.Info("sampled")
+				}
+				logger.Error("never sampled")
+			},
+			expected: []string{"sampled", "sampled", "never sampled"},
+		},
+		{
+			name:      "unknown level",
+			opts:      logger.Options{Level: "verbose", Format: logger.FormatJSON},
+			expectErr: true,
+		},
+		{
+			name:      "unknown format",
+			opts:      logger.Options{Level: "info", Format: "logfmt"},
+			expectErr: true,
+		},
+		{
+			name:      "unknown output",
+			opts:      logger.Options{Level: "info", Format: logger.FormatJSON, Output: "syslog"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restoreLogger(t)
+			file := filepath.Join(t.TempDir(), "service.log")
+			tt.opts.File = file
+			tt.opts.FileMaxSizeMB = 1
+
+			err := logger.Configure(&tt.opts)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			tt.log()
+
+			var messages []string
+			for _, line := range readLines(t, file) {
+				assert.Contains(t, line, "time")
+				messages = append(messages, line["message"].(string))
+			}
+			assert.Equal(t, tt.expected, messages)
+		})
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	restoreLogger(t)
+	captureLogs(t)
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	handler := logger.LevelHandler()
+
+	tests := []struct {
+		name          string
+		method        string
+		body          string
+		expectedCode  int
+		expectedLevel zerolog.Level
+	}{
+		{name: "get", method: http.MethodGet, expectedCode: http.StatusOK, expectedLevel: zerolog.InfoLevel},
+		{name: "set debug", method: http.MethodPut, body: `{"level":"debug"}`, expectedCode: http.StatusOK, expectedLevel: zerolog.DebugLevel},
+		{name: "unknown level", method: http.MethodPut, body: `{"level":"loud"}`, expectedCode: http.StatusBadRequest, expectedLevel: zerolog.DebugLevel},
+		{name: "invalid body", method: http.MethodPut, body: `level=warn`, expectedCode: http.StatusBadRequest, expectedLevel: zerolog.DebugLevel},
+		{name: "wrong method", method: http.MethodPost, body: `{"level":"warn"}`, expectedCode: http.StatusMethodNotAllowed, expectedLevel: zerolog.DebugLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, "/log/level", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Equal(t, tt.expectedLevel, zerolog.GlobalLevel())
+			if tt.expectedCode == http.StatusOK {
+				assert.JSONEq(t, `{"level":"`+tt.expectedLevel.String()+`"}`, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const redacted = "[REDACTED]"
+
+// Redaction rules, in one place. Proto string fields with these names are
+// masked in every message logged, at any depth, along with these metadata
+// keys.
+var (
+	redactedProtoFields = map[protoreflect.Name]bool{
+		// Credentials
+		"password":      true,
+		"token":         true,
+		"access_token":  true,
+		"subject_token": true,
+		"actor_token":   true,
+		"key":           true,
+		"code":          true,
+		"device_code":   true,
+		// Personal data
+		"email": true,
+		"name":  true,
+	}
+
+	redactedMetadata = map[string]bool{
+		"authorization": true,
+		"cookie":        true,
+		"set-cookie":    true,
+	}
+
+	// Credentials that can end up in free text such as error messages.
+	secretPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)(bearer\s+)[^\s"',]+`),
+		regexp.MustCompile(`eyJ[\w-]*\.[\w-]*\.[\w-]*`),
+		regexp.MustCompile(`ak_[0-9a-f]+_[\w-]+`),
+	}
+)
+
+// Redact returns a copy of v that is safe to log: protobuf messages with
+// their sensitive fields masked and metadata with credential values masked.
+// Other values are returned as is. The logging functions call it on every
+// argument, so only direct zerolog use needs it explicitly.
+func Redact(v any) any {
+	switch v := v.(type) {
+	case proto.Message:
+		return redactedMessage{m: redactMessage(v)}
+	case metadata.MD:
+		return redactMetadata(v)
+	}
+	return v
+}
+
+// Scrub masks bearer tokens, JWTs and API keys found in s.
+func Scrub(s string) string {
+	for _, p := range secretPatterns {
+		s = p.ReplaceAllStringFunc(s, func(match string) string {
+			if sub := p.FindStringSubmatch(match); len(sub) > 1 {
+				return sub[1] + redacted
+			}
+			return redacted
+		})
+	}
+	return s
+}
+
+// write logs a line with every argument redacted, skipping the redaction work
+// for disabled levels.
+func write(e *zerolog.Event, format string, v []any) {
+	if !e.Enabled() {
+		return
+	}
+	e.Msg(message(format, v))
+}
+
+func message(format string, v []any) string {
+	args := make([]any, len(v))
+	for i := range v {
+		args[i] = Redact(v[i])
+	}
+	return Scrub(fmt.Sprintf(format, args...))
+}
+
+type redactedMessage struct {
+	m proto.Message
+}
+
+func (r redactedMessage) String() string {
+	if r.m == nil {
+		return "<nil>"
+	}
+	return prototext.MarshalOptions{}.Format(r.m)
+}
+
+func redactMessage(m proto.Message) proto.Message {
+	if m == nil || !m.ProtoReflect().IsValid() {
+		return m
+	}
+
+	clone := proto.Clone(m)
+	redactFields(clone.ProtoReflect())
+	return clone
+}
+
+func redactFields(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Kind() == protoreflect.MessageKind {
+				v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+					redactFields(mv.Message())
+					return true
+				})
+			}
+		case fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind:
+			if fd.IsList() {
+				for i := 0; i < v.List().Len(); i++ {
+					redactFields(v.List().Get(i).Message())
+				}
+			} else {
+				redactFields(v.Message())
+			}
+		case fd.Kind() == protoreflect.StringKind && redactedProtoFields[fd.Name()]:
+			if fd.IsList() {
+				for i := 0; i < v.List().Len(); i++ {
+					v.List().Set(i, protoreflect.ValueOfString(redacted))
+				}
+			} else {
+				m.Set(fd, protoreflect.ValueOfString(redacted))
+			}
+		case fd.Kind() == protoreflect.BytesKind && redactedProtoFields[fd.Name()]:
+			m.Set(fd, protoreflect.ValueOfBytes([]byte(redacted)))
+		}
+		return true
+	})
+}
+
+func redactMetadata(md metadata.MD) metadata.MD {
+	out := make(metadata.MD, len(md))
+	for k, values := range md {
+		if redactedMetadata[k] {
+			masked := make([]string, len(values))
+			for i := range masked {
+				masked[i] = redacted
+			}
+			out[k] = masked
+			continue
+		}
+		out[k] = append([]string(nil), values...)
+	}
+	return out
+}
@@ -0,0 +1,127 @@
+package logger_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	userpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/user"
+)
+
+const (
+	secretPassword = "hunter2-secret"
+	secretJWT      = "eyJhbGciOiJIUzI1NiJ9.eyJpZCI6MX0.c2lnbmF0dXJl"
+	secretAPIKey   = "ak_0123abcd_s3cr3tPart"
+)
+
+func TestRedact_NeverLogsSecrets(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		args     []any
+		secrets  []string
+		expected []string
+	}{
+		{
+			name:     "store request",
+			format:   "gRPC userClient.Store request: %v",
+			args:     []any{&userpb.StoreRequest{Name: "Jane Doe", Email: "jane@example.com", Password: secretPassword}},
+			secrets:  []string{secretPassword, "jane@example.com", "Jane Doe"},
+			expected: []string{"[REDACTED]"},
+		},
+		{
+			name:   "nested user in response",
+			format: "gRPC userClient.FindByCredential response: %v",
+			args: []any{&userpb.FindByCredentialResponse{
+				Message: "OK",
+				Data:    &userpb.FindByCredentialResponseData{User: &userpb.User{Id: 7, Name: "Jane Doe", Email: "jane@example.com"}},
+			}},
+			secrets:  []string{"jane@example.com", "Jane Doe"},
+			expected: []string{"id:7", "OK"},
+		},
+		{
+			name:   "token in response",
+			format: "%+v",
+			args: []any{&authpb.LoginResponse{
+				Data: &authpb.LoginResponseData{Token: secretJWT},
+			}},
+			secrets: []string{secretJWT},
+		},
+		{
+			name:   "repeated messages",
+			format: "%s",
+			args: []any{&authpb.CreateAPIKeyResponse{
+				Data: &authpb.CreateAPIKeyResponseData{Key: secretAPIKey, ApiKey: &authpb.APIKey{Id: "0123abcd", Scopes: []string{"videos:read"}}},
+			}},
+			secrets:  []string{secretAPIKey},
+			expected: []string{"videos:read", "0123abcd"},
+		},
+		{
+			name:     "metadata",
+			format:   "metadata: %v",
+			args:     []any{metadata.Pairs("authorization", "Bearer "+secretJWT, "x-request-id", "req-1")},
+			secrets:  []string{secretJWT},
+			expected: []string{"req-1"},
+		},
+		{
+			name:     "bearer token in error",
+			format:   "request failed: %v",
+			args:     []any{errors.New(`rpc error: invalid header "Bearer opaque-token-123"`)},
+			secrets:  []string{"opaque-token-123"},
+			expected: []string{"Bearer [REDACTED]"},
+		},
+		{
+			name:    "JWT in error",
+			format:  "request failed: %v",
+			args:    []any{fmt.Errorf("cannot parse %s", secretJWT)},
+			secrets: []string{secretJWT},
+		},
+		{
+			name:    "API key in error",
+			format:  "request failed: %v",
+			args:    []any{fmt.Errorf("unknown key %s", secretAPIKey)},
+			secrets: []string{secretAPIKey, "s3cr3tPart"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureLogs(t)
+
+			logger.Info(tt.format, tt.args...)
+			logger.ErrorCtx(context.Background(), tt.format, tt.args...)
+
+			out := buf.String()
+			for _, secret := range tt.secrets {
+				assert.NotContains(t, out, secret)
+			}
+			for _, want := range tt.expected {
+				assert.Contains(t, out, want)
+			}
+		})
+	}
+}
+
+func TestRedact_LeavesOriginalUntouched(t *testing.T) {
+	req := &userpb.StoreRequest{Name: "Jane Doe", Email: "jane@example.com", Password: secretPassword}
+	md := metadata.Pairs("authorization", "Bearer token")
+
+	_ = fmt.Sprint(logger.Redact(req), logger.Redact(md))
+
+	assert.Equal(t, secretPassword, req.Password)
+	assert.Equal(t, "jane@example.com", req.Email)
+	assert.Equal(t, []string{"Bearer token"}, md.Get("authorization"))
+}
+
+func TestRedact_NilMessage(t *testing.T) {
+	var req *userpb.StoreRequest
+	assert.NotPanics(t, func() {
+		_ = fmt.Sprint(logger.Redact(req))
+	})
+}
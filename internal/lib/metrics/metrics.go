@@ -0,0 +1,186 @@
+package metrics
+
+import (
+	"context"
+
+	prometheuslib "github.com/prometheus/client_golang/prometheus"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+const meterName = "github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+
+// Attribute keys, exported as Prometheus labels of the same name
+const (
+	MethodKey     = attribute.Key("method")
+	StatusKey     = attribute.Key("status")
+	DirectionKey  = attribute.Key("direction")
+	OutcomeKey    = attribute.Key("outcome")
+	ReasonKey     = attribute.Key("reason")
+	TypeKey       = attribute.Key("type")
+	ResultKey     = attribute.Key("result")
+	CommandKey    = attribute.Key("command")
+	DependencyKey = attribute.Key("dependency")
+	SourceKey     = attribute.Key("source")
+	RouteKey      = attribute.Key("route")
+)
+
+// Instruments are named the Prometheus way, suffixes included, so both
+// exporters report the same names. They are no-ops until Init is called.
+var (
+	GRPCRequests              metric.Int64Counter
+	GRPCRequestDuration       metric.Float64Histogram
+	GRPCStreamMessages        metric.Int64Counter
+	GRPCStreamDuration        metric.Float64Histogram
+	HTTPRequests              metric.Int64Counter
+	HTTPRequestDuration       metric.Float64Histogram
+	LoginAttempts             metric.Int64Counter
+	TokensIssued              metric.Int64Counter
+	TokensRevoked             metric.Int64Counter
+	BlacklistLookups          metric.Int64Counter
+	TokenVerificationFailures metric.Int64Counter
+	UserServiceDuration       metric.Float64Histogram
+	RedisCommandDuration      metric.Float64Histogram
+	AuditEventsDropped        metric.Int64Counter
+	ServiceHealth             metric.Int64Gauge
+	DependencyHealth          metric.Int64Gauge
+	ProfileFallbacks          metric.Int64Counter
+)
+
+// Prometheus client defaults, kept so existing dashboards still line up.
+var defaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+func init() {
+	if err := createInstruments(noop.NewMeterProvider().Meter(meterName)); err != nil {
+		panic(err)
+	}
+}
+
+// Init sets up a meter provider that serves metrics to Prometheus through
+// registerer and, when cfg.OTLPURL is set, pushes the same metrics over OTLP
+// HTTP every cfg.OTLPInterval. The returned function flushes and stops both.
+func Init(ctx context.Context, cfg *config.Metrics, registerer prometheuslib.Registerer) (func(context.Context) error, error) {
+	promExporter, err := otelprom.New(
+		otelprom.WithRegisterer(registerer),
+		otelprom.WithoutScopeInfo(),
+		otelprom.WithoutTargetInfo(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []sdkmetric.Option{
+		sdkmetric.WithReader(promExporter),
+		sdkmetric.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(constant.ServiceName),
+		)),
+	}
+
+	if cfg.OTLPURL != "" {
+		otlpExporter, err := otlpmetrichttp.New(ctx,
+			otlpmetrichttp.WithEndpoint(cfg.OTLPURL),
+			otlpmetrichttp.WithInsecure(),
+		)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdkmetric.WithReader(
+			sdkmetric.NewPeriodicReader(otlpExporter, sdkmetric.WithInterval(cfg.OTLPInterval)),
+		))
+	}
+
+	provider := sdkmetric.NewMeterProvider(opts...)
+	if err := SetMeterProvider(provider); err != nil {
+		return nil, err
+	}
+	return provider.Shutdown, nil
+}
+
+// SetMeterProvider recreates every instrument from provider. Init calls it,
+// tests use it to collect metrics in memory.
+func SetMeterProvider(provider metric.MeterProvider) error {
+	return createInstruments(provider.Meter(meterName))
+}
+
+func createInstruments(meter metric.Meter) error {
+	var err error
+	counter := func(name, description string) metric.Int64Counter {
+		if err != nil {
+			return nil
+		}
+		var c metric.Int64Counter
+		c, err = meter.Int64Counter(name, metric.WithDescription(description))
+		return c
+	}
+	histogram := func(name, description string, buckets []float64) metric.Float64Histogram {
+		if err != nil {
+			return nil
+		}
+		var h metric.Float64Histogram
+		h, err = meter.Float64Histogram(name,
+			metric.WithDescription(description),
+			metric.WithUnit("s"),
+			metric.WithExplicitBucketBoundaries(buckets...),
+		)
+		return h
+	}
+
+	grpcRequests := counter("grpc_requests_total", "Total number of gRPC requests")
+	grpcRequestDuration := histogram("grpc_request_duration_seconds", "Histogram of response latency (seconds) of gRPC requests", defaultBuckets)
+	grpcStreamMessages := counter("grpc_stream_messages_total", "Total number of messages sent and received on gRPC streams")
+	grpcStreamDuration := histogram("grpc_stream_duration_seconds", "Histogram of gRPC stream lifetimes (seconds)",
+		[]float64{.1, .5, 1, 5, 15, 30, 60, 300, 900, 3600})
+	httpRequests := counter("http_requests_total", "Total number of HTTP requests by route and status code")
+	httpRequestDuration := histogram("http_request_duration_seconds", "Histogram of response latency (seconds) of HTTP requests", defaultBuckets)
+	loginAttempts := counter("auth_login_attempts_total", "Total number of password login attempts by outcome and gRPC status")
+	tokensIssued := counter("auth_tokens_issued_total", "Total number of credentials issued: access, exchange or api_key")
+	tokensRevoked := counter("auth_tokens_revoked_total", "Total number of credentials revoked: access (any JWT) or api_key")
+	blacklistLookups := counter("auth_blacklist_lookups_total", "Total number of token blacklist lookups by result: hit, miss or error")
+	tokenVerificationFailures := counter("auth_token_verification_failures_total",
+		"Total number of rejected JWTs by reason: expired, bad_signature, revoked, malformed or invalid")
+	userServiceDuration := histogram("user_service_request_duration_seconds", "Histogram of user-service gRPC call latency (seconds)", defaultBuckets)
+	redisCommandDuration := histogram("redis_command_duration_seconds", "Histogram of Redis command latency (seconds) by outcome: ok, nil or error",
+		[]float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1})
+	auditEventsDropped := counter("audit_events_dropped_total", "Total number of audit events dropped because the audit buffer was full")
+	profileFallbacks := counter("auth_profile_fallbacks_total",
+		"Total number of VerifyToken responses served with a stale profile while the user service was down, by source: cache or token")
+	if err != nil {
+		return err
+	}
+	serviceHealth, err := meter.Int64Gauge("service_health_status", metric.WithDescription("Health status of the service: 1=Healthy, 0=Unhealthy"))
+	if err != nil {
+		return err
+	}
+	dependencyHealth, err := meter.Int64Gauge("dependency_health_status", metric.WithDescription("Health status of each dependency: 1=Healthy, 0=Unhealthy"))
+	if err != nil {
+		return err
+	}
+
+	GRPCRequests = grpcRequests
+	GRPCRequestDuration = grpcRequestDuration
+	GRPCStreamMessages = grpcStreamMessages
+	GRPCStreamDuration = grpcStreamDuration
+	HTTPRequests = httpRequests
+	HTTPRequestDuration = httpRequestDuration
+	LoginAttempts = loginAttempts
+	TokensIssued = tokensIssued
+	TokensRevoked = tokensRevoked
+	BlacklistLookups = blacklistLookups
+	TokenVerificationFailures = tokenVerificationFailures
+	UserServiceDuration = userServiceDuration
+	RedisCommandDuration = redisCommandDuration
+	AuditEventsDropped = auditEventsDropped
+	ServiceHealth = serviceHealth
+	DependencyHealth = dependencyHealth
+	ProfileFallbacks = profileFallbacks
+	return nil
+}
@@ -0,0 +1,145 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+)
+
+var expectedMetrics = []string{
+	"audit_events_dropped_total",
+	"auth_blacklist_lookups_total",
+	"auth_login_attempts_total",
+	"auth_token_verification_failures_total",
+	"auth_tokens_issued_total",
+	"auth_tokens_revoked_total",
+	"grpc_request_duration_seconds",
+	"grpc_requests_total",
+	"grpc_stream_duration_seconds",
+	"grpc_stream_messages_total",
+	"redis_command_duration_seconds",
+	"service_health_status",
+	"user_service_request_duration_seconds",
+}
+
+// recordAll touches every instrument once.
+func recordAll(ctx context.Context) {
+	method := metric.WithAttributes(metrics.MethodKey.String("/test.Method"))
+	metrics.GRPCRequests.Add(ctx, 1, metric.WithAttributes(metrics.MethodKey.String("/test.Method"), metrics.StatusKey.String("OK")))
+	metrics.GRPCRequestDuration.Record(ctx, 0.2, method)
+	metrics.GRPCStreamMessages.Add(ctx, 1, metric.WithAttributes(metrics.MethodKey.String("/test.Method"), metrics.DirectionKey.String("sent")))
+	metrics.GRPCStreamDuration.Record(ctx, 1, method)
+	metrics.LoginAttempts.Add(ctx, 1, metric.WithAttributes(metrics.OutcomeKey.String("success"), metrics.ReasonKey.String("OK")))
+	metrics.TokensIssued.Add(ctx, 1, metric.WithAttributes(metrics.TypeKey.String("access")))
+	metrics.TokensRevoked.Add(ctx, 1, metric.WithAttributes(metrics.TypeKey.String("access")))
+	metrics.BlacklistLookups.Add(ctx, 1, metric.WithAttributes(metrics.ResultKey.String("miss")))
+	metrics.TokenVerificationFailures.Add(ctx, 1, metric.WithAttributes(metrics.ReasonKey.String("expired")))
+	metrics.UserServiceDuration.Record(ctx, 0.01, metric.WithAttributes(metrics.MethodKey.String("/user.UserService/FindById"), metrics.StatusKey.String("OK")))
+	metrics.RedisCommandDuration.Record(ctx, 0.001, metric.WithAttributes(metrics.CommandKey.String("get"), metrics.OutcomeKey.String("ok")))
+	metrics.AuditEventsDropped.Add(ctx, 1)
+	metrics.ServiceHealth.Record(ctx, 1)
+}
+
+func resetMeterProvider(t *testing.T) {
+	t.Cleanup(func() { _ = metrics.SetMeterProvider(noop.NewMeterProvider()) })
+}
+
+func TestInit_Prometheus(t *testing.T) {
+	resetMeterProvider(t)
+	reg := prometheus.NewRegistry()
+
+	shutdown, err := metrics.Init(context.Background(), &config.Metrics{}, reg)
+	require.NoError(t, err)
+	defer shutdown(context.Background())
+
+	recordAll(context.Background())
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	var names []string
+	for _, f := range families {
+		names = append(names, f.GetName())
+
+		// No scope labels next to the ones recorded
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				assert.False(t, strings.HasPrefix(l.GetName(), "otel_"), "%s has label %s", f.GetName(), l.GetName())
+			}
+		}
+
+		if f.GetName() == "grpc_request_duration_seconds" {
+			buckets := f.GetMetric()[0].GetHistogram().GetBucket()
+			require.Len(t, buckets, 11)
+			assert.Equal(t, 0.005, buckets[0].GetUpperBound())
+			assert.Equal(t, 10.0, buckets[10].GetUpperBound())
+		}
+	}
+	assert.Equal(t, expectedMetrics, names)
+}
+
+func TestInit_OTLP(t *testing.T) {
+	resetMeterProvider(t)
+
+	var mu sync.Mutex
+	var exported []string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		req := &collectorpb.ExportMetricsServiceRequest{}
+		if err := proto.Unmarshal(body, req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, rm := range req.GetResourceMetrics() {
+			for _, sm := range rm.GetScopeMetrics() {
+				for _, m := range sm.GetMetrics() {
+					exported = append(exported, m.GetName())
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	defer collector.Close()
+
+	shutdown, err := metrics.Init(context.Background(), &config.Metrics{
+		OTLPURL:      strings.TrimPrefix(collector.URL, "http://"),
+		OTLPInterval: time.Hour,
+	}, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	recordAll(context.Background())
+
+	// Shutting down flushes the periodic reader.
+	require.NoError(t, shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	sort.Strings(exported)
+	assert.Equal(t, expectedMetrics, exported)
+}
+
+func TestSetMeterProvider_Noop(t *testing.T) {
+	require.NoError(t, metrics.SetMeterProvider(noop.NewMeterProvider()))
+
+	assert.NotPanics(t, func() { recordAll(context.Background()) })
+}
@@ -0,0 +1,73 @@
+// Package metricstest collects the service metrics in memory for tests.
+package metricstest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+type Reader struct {
+	reader *sdkmetric.ManualReader
+}
+
+// New points every instrument at a fresh in-memory provider until the test
+// ends, so each test starts from zero.
+func New(t *testing.T) *Reader {
+	t.Helper()
+
+	reader := sdkmetric.NewManualReader()
+	if err := metrics.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))); err != nil {
+		t.Fatalf("failed to set meter provider: %v", err)
+	}
+	t.Cleanup(func() { _ = metrics.SetMeterProvider(noop.NewMeterProvider()) })
+
+	return &Reader{reader: reader}
+}
+
+// Value returns the data point of the named metric with exactly attrs: the
+// total of a counter, the number of observations of a histogram or the last
+// value of a gauge. It is 0 when nothing was recorded.
+func (r *Reader) Value(t *testing.T, name string, attrs ...attribute.KeyValue) float64 {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := r.reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("failed to collect metrics: %v", err)
+	}
+
+	set := attribute.NewSet(attrs...)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					if dp.Attributes.Equals(&set) {
+						return float64(dp.Value)
+					}
+				}
+			case metricdata.Gauge[int64]:
+				for _, dp := range data.DataPoints {
+					if dp.Attributes.Equals(&set) {
+						return float64(dp.Value)
+					}
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					if dp.Attributes.Equals(&set) {
+						return float64(dp.Count)
+					}
+				}
+			}
+		}
+	}
+	return 0
+}
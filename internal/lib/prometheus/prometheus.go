@@ -1,57 +1,46 @@
 package prometheus
 
 import (
+	"net"
 	"net/http"
 
 	prometheuslib "github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
 )
 
-var (
-	GRPCRequestCounter = prometheuslib.NewCounterVec(
-		prometheuslib.CounterOpts{
-			Name: "grpc_requests_total",
-			Help: "Total number of gRPC requests",
-		},
-		[]string{"method", "status"},
-	)
-
-	GRPCRequestLatency = prometheuslib.NewHistogramVec(
-		prometheuslib.HistogramOpts{
-			Name:    "grpc_request_duration_seconds",
-			Help:    "Histogram of response latency (seconds) of gRPC requests",
-			Buckets: prometheuslib.DefBuckets,
-		},
-		[]string{"method"},
-	)
-
-	ServiceHealth = prometheuslib.NewGauge(prometheuslib.GaugeOpts{
-		Name: "service_health_status",
-		Help: "Health status of the service: 1=Healthy, 0=Unhealthy",
-	})
-)
-
-func RegisterMetrics(registry *prometheuslib.Registry) {
-	registry.MustRegister(
-		GRPCRequestCounter,
-		GRPCRequestLatency,
-		ServiceHealth,
-	)
-}
-
-func NewServer(url string, registry *prometheuslib.Registry) *http.Server {
-	RegisterMetrics(registry)
-
+// NewServer serves the metrics in registry, which metrics.Init registers
+// its Prometheus exporter with, and the health probes by path.
+func NewServer(url string, registry *prometheuslib.Registry, probes map[string]http.Handler) *http.Server {
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.HandlerFor(
 		registry,
 		promhttp.HandlerOpts{},
 	))
+	// The metrics port is usually reachable by the scraper, so changing the
+	// log level is further limited to callers on the same host.
+	mux.Handle("/log/level", loopbackOnly(logger.LevelHandler()))
+	for path, handler := range probes {
+		mux.Handle(path, handler)
+	}
 
 	return &http.Server{Addr: url, Handler: mux}
 }
 
+// loopbackOnly rejects requests that don't come from a loopback address, such
+// as kubectl port-forward or a shell in the container.
+func loopbackOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if ip := net.ParseIP(host); err != nil || ip == nil || !ip.IsLoopback() {
+			http.Error(w, constant.MessageForbidden, http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func Serve(server *http.Server, listen func() error) error {
 	logger.Info("Starting Prometheus metrics server %s", server.Addr)
 
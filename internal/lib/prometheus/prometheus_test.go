@@ -1,6 +1,7 @@
 package prometheus_test
 
 import (
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -10,55 +11,32 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
 	myprom "github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
 )
 
-func TestRegisterMetrics(t *testing.T) {
+func TestNewServerAndMetricsEndpoint(t *testing.T) {
 	reg := prometheus.NewRegistry()
-
-	myprom.RegisterMetrics(reg)
-
-	// Touch the vectors so they are instantiated
-	myprom.GRPCRequestCounter.WithLabelValues("methodX", "success").Inc()
-	myprom.GRPCRequestLatency.WithLabelValues("methodX").Observe(0.123)
-
-	metrics, err := reg.Gather()
+	_, err := metrics.Init(context.Background(), &config.Metrics{}, reg)
 	require.NoError(t, err)
-
-	tests := []struct {
-		name string
-		want string
-	}{
-		{"grpc_requests_total", "grpc_requests_total"},
-		{"grpc_request_duration_seconds", "grpc_request_duration_seconds"},
-		{"service_health_status", "service_health_status"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			found := false
-			for _, m := range metrics {
-				if m.GetName() == tt.want {
-					found = true
-					break
-				}
-			}
-			assert.True(t, found, "metric %s should be registered", tt.want)
-		})
+	t.Cleanup(func() { _ = metrics.SetMeterProvider(noop.NewMeterProvider()) })
+	probes := map[string]http.Handler{
+		"/readyz": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusServiceUnavailable) }),
 	}
-}
-
-func TestNewServerAndMetricsEndpoint(t *testing.T) {
-	reg := prometheus.NewRegistry()
-	server := myprom.NewServer(":0", reg) // :0 = random free port
+	server := myprom.NewServer(":0", reg, probes) // :0 = random free port
 
 	// Start test server with httptest instead of real listen
 	ts := httptest.NewServer(server.Handler)
 	defer ts.Close()
 
 	// Increment some metrics
-	myprom.GRPCRequestCounter.WithLabelValues("Login", "OK").Inc()
-	myprom.ServiceHealth.Set(1)
+	metrics.GRPCRequests.Add(context.Background(), 1, metric.WithAttributes(
+		metrics.MethodKey.String("Login"), metrics.StatusKey.String("OK"),
+	))
+	metrics.ServiceHealth.Record(context.Background(), 1)
 
 	resp, err := http.Get(ts.URL + "/metrics")
 	require.NoError(t, err)
@@ -69,8 +47,39 @@ func TestNewServerAndMetricsEndpoint(t *testing.T) {
 	body, _ := ioutil.ReadAll(resp.Body)
 	bodyStr := string(body)
 
-	assert.Contains(t, bodyStr, "grpc_requests_total")
-	assert.Contains(t, bodyStr, "service_health_status")
+	assert.Contains(t, bodyStr, `grpc_requests_total{method="Login",status="OK"} 1`)
+	assert.Contains(t, bodyStr, "service_health_status 1")
+
+	probeResp, err := http.Get(ts.URL + "/readyz")
+	require.NoError(t, err)
+	probeResp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, probeResp.StatusCode)
+}
+
+func TestLogLevelEndpoint(t *testing.T) {
+	server := myprom.NewServer(":0", prometheus.NewRegistry(), nil)
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		expectedCode int
+	}{
+		{name: "loopback", remoteAddr: "127.0.0.1:5000", expectedCode: http.StatusOK},
+		{name: "ipv6 loopback", remoteAddr: "[::1]:5000", expectedCode: http.StatusOK},
+		{name: "remote caller", remoteAddr: "10.0.0.7:5000", expectedCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/log/level", nil)
+			req.RemoteAddr = tt.remoteAddr
+			rec := httptest.NewRecorder()
+
+			server.Handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+		})
+	}
 }
 
 func TestServeFunction(t *testing.T) {
@@ -0,0 +1,92 @@
+package rbac
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+)
+
+// Policy maps users to roles and roles to the permissions embedded in their
+// tokens as the "scope" claim.
+type Policy interface {
+	Roles(userID uint) []string
+	Permissions(roles []string) []string
+}
+
+// policyFile is the on-disk role mapping, e.g.
+//
+//	{
+//	  "default_roles": ["viewer"],
+//	  "roles": {"viewer": ["videos:read"], "admin": ["*"]},
+//	  "users": {"1": ["admin"]}
+//	}
+type policyFile struct {
+	DefaultRoles []string            `json:"default_roles"`
+	Roles        map[string][]string `json:"roles"`
+	Users        map[string][]string `json:"users"`
+}
+
+type policy struct {
+	file *policyFile
+}
+
+// NewPolicy loads the role mapping file. Without a configured file RBAC is
+// disabled and a nil Policy is returned, so tokens carry no role claims.
+func NewPolicy(cfg *config.RBAC) (Policy, error) {
+	if cfg.PolicyFile == "" {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(cfg.PolicyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &policyFile{}
+	if err := json.Unmarshal(b, file); err != nil {
+		return nil, err
+	}
+
+	return &policy{file: file}, nil
+}
+
+func (p *policy) Roles(userID uint) []string {
+	if roles, ok := p.file.Users[strconv.FormatUint(uint64(userID), 10)]; ok {
+		return roles
+	}
+	return p.file.DefaultRoles
+}
+
+func (p *policy) Permissions(roles []string) []string {
+	permissions := []string{}
+	for _, role := range roles {
+		for _, permission := range p.file.Roles[role] {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
+
+// Allowed reports whether any of the granted scopes covers permission on
+// resource. Scopes are glob patterns, so "videos:*" grants "videos:read", and
+// a scope may be pinned to a single resource as "<permission>:<resource>".
+func Allowed(scopes []string, permission string, resource string) bool {
+	for _, scope := range scopes {
+		if ok, _ := path.Match(scope, permission); ok {
+			return true
+		}
+		if resource == "" {
+			continue
+		}
+		if ok, _ := path.Match(scope, permission+":"+resource); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package rbac_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/config"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/rbac"
+)
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	policyFile := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(policyFile, []byte(content), 0o600))
+	return policyFile
+}
+
+func TestNewPolicy(t *testing.T) {
+	t.Run("disabled without a policy file", func(t *testing.T) {
+		policy, err := rbac.NewPolicy(&config.RBAC{})
+		assert.NoError(t, err)
+		assert.Nil(t, policy)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := rbac.NewPolicy(&config.RBAC{PolicyFile: filepath.Join(t.TempDir(), "missing.json")})
+		assert.Error(t, err)
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		_, err := rbac.NewPolicy(&config.RBAC{PolicyFile: writePolicy(t, "{")})
+		assert.Error(t, err)
+	})
+}
+
+func TestPolicy_RolesAndPermissions(t *testing.T) {
+	policy, err := rbac.NewPolicy(&config.RBAC{PolicyFile: writePolicy(t, `{
+		"default_roles": ["viewer"],
+		"roles": {
+			"viewer": ["videos:read"],
+			"uploader": ["videos:read", "videos:upload"],
+			"moderator": ["videos:delete"]
+		},
+		"users": {"1": ["uploader", "moderator"]}
+	}`)})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"uploader", "moderator"}, policy.Roles(1))
+	assert.Equal(t, []string{"viewer"}, policy.Roles(2))
+	assert.Equal(t, []string{"videos:read", "videos:upload", "videos:delete"}, policy.Permissions(policy.Roles(1)))
+	assert.Equal(t, []string{}, policy.Permissions([]string{"unknown"}))
+}
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		name       string
+		scopes     []string
+		permission string
+		resource   string
+		expected   bool
+	}{
+		{name: "exact permission", scopes: []string{"videos:read"}, permission: "videos:read", expected: true},
+		{name: "permission applies to any resource", scopes: []string{"videos:read"}, permission: "videos:read", resource: "42", expected: true},
+		{name: "wildcard", scopes: []string{"videos:*"}, permission: "videos:delete", expected: true},
+		{name: "superuser", scopes: []string{"*"}, permission: "billing:refund", expected: true},
+		{name: "pinned to resource", scopes: []string{"videos:update:42"}, permission: "videos:update", resource: "42", expected: true},
+		{name: "pinned to other resource", scopes: []string{"videos:update:42"}, permission: "videos:update", resource: "43", expected: false},
+		{name: "pinned scope without resource", scopes: []string{"videos:update:42"}, permission: "videos:update", expected: false},
+		{name: "missing permission", scopes: []string{"videos:read"}, permission: "videos:delete", expected: false},
+		{name: "no scopes", scopes: nil, permission: "videos:read", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rbac.Allowed(tt.scopes, tt.permission, tt.resource))
+		})
+	}
+}
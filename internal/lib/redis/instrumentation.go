@@ -0,0 +1,108 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	redislib "github.com/redis/go-redis/v9"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis"
+
+// OutcomeKey is the span attribute holding a command's outcome: ok, nil (key
+// not found) or error.
+const OutcomeKey = attribute.Key("redis.outcome")
+
+// InstrumentationHook traces every Redis command and records its latency by
+// command name and outcome. Spans are children of the span in the command's
+// context, normally the gRPC server span of the request being served.
+// Commands in a pipeline share one span and are timed together.
+type InstrumentationHook struct {
+	tracer trace.Tracer
+}
+
+// NewInstrumentationHook uses the global tracer provider, so it can be
+// created before tracing is set up.
+func NewInstrumentationHook() *InstrumentationHook {
+	return &InstrumentationHook{tracer: otel.Tracer(tracerName)}
+}
+
+func (h *InstrumentationHook) DialHook(next redislib.DialHook) redislib.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *InstrumentationHook) ProcessHook(next redislib.ProcessHook) redislib.ProcessHook {
+	return func(ctx context.Context, cmd redislib.Cmder) error {
+		ctx, span := h.tracer.Start(ctx, "redis "+cmd.Name(),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemRedis, semconv.DBOperation(cmd.Name())),
+		)
+		defer span.End()
+
+		start := time.Now()
+		err := next(ctx, cmd)
+
+		outcome := commandOutcome(err)
+		metrics.RedisCommandDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(
+			metrics.CommandKey.String(cmd.Name()),
+			metrics.OutcomeKey.String(outcome),
+		))
+		endSpan(span, outcome, err)
+		return err
+	}
+}
+
+func (h *InstrumentationHook) ProcessPipelineHook(next redislib.ProcessPipelineHook) redislib.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redislib.Cmder) error {
+		ctx, span := h.tracer.Start(ctx, "redis pipeline",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemRedis, attribute.Int("redis.pipeline.length", len(cmds))),
+		)
+		defer span.End()
+
+		start := time.Now()
+		err := next(ctx, cmds)
+
+		elapsed := time.Since(start).Seconds()
+		for _, cmd := range cmds {
+			metrics.RedisCommandDuration.Record(ctx, elapsed, metric.WithAttributes(
+				metrics.CommandKey.String(cmd.Name()),
+				metrics.OutcomeKey.String(commandOutcome(cmd.Err())),
+			))
+		}
+		endSpan(span, commandOutcome(err), err)
+		return err
+	}
+}
+
+func commandOutcome(err error) string {
+	switch {
+	case err == nil:
+		return constant.MetricRedisOK
+	case errors.Is(err, Nil):
+		return constant.MetricRedisNil
+	}
+	return constant.MetricRedisError
+}
+
+// endSpan marks the span failed for real errors only: a missing key is an
+// expected answer, e.g. for every token that isn't blacklisted.
+func endSpan(span trace.Span, outcome string, err error) {
+	span.SetAttributes(OutcomeKey.String(outcome))
+	if outcome == constant.MetricRedisError {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,106 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	redislib "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/metrics/metricstest"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis"
+)
+
+func setupTracing(t *testing.T) (*tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	return recorder, tp
+}
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestInstrumentationHook_ProcessHook(t *testing.T) {
+	tests := []struct {
+		name            string
+		nextErr         error
+		expectedOutcome string
+		expectedStatus  codes.Code
+	}{
+		{"success", nil, constant.MetricRedisOK, codes.Unset},
+		{"key not found", redis.Nil, constant.MetricRedisNil, codes.Unset},
+		{"error", errors.New("connection refused"), constant.MetricRedisError, codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder, tp := setupTracing(t)
+			reader := metricstest.New(t)
+
+			ctx, parent := tp.Tracer("test").Start(context.Background(), "/authentication.AuthenticationService/VerifyToken")
+			next := func(ctx context.Context, cmd redislib.Cmder) error { return tt.nextErr }
+			cmd := redislib.NewStringCmd(ctx, "get", "key")
+
+			err := redis.NewInstrumentationHook().ProcessHook(next)(ctx, cmd)
+			parent.End()
+
+			assert.Equal(t, tt.nextErr, err)
+			assert.Equal(t, 1.0, reader.Value(t, "redis_command_duration_seconds",
+				metrics.CommandKey.String("get"), metrics.OutcomeKey.String(tt.expectedOutcome)))
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 2)
+			span := spans[0]
+			assert.Equal(t, "redis get", span.Name())
+			assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+			assert.Equal(t, "redis", spanAttribute(span, "db.system"))
+			assert.Equal(t, "get", spanAttribute(span, "db.operation"))
+			assert.Equal(t, tt.expectedOutcome, spanAttribute(span, redis.OutcomeKey))
+			assert.Equal(t, tt.expectedStatus, span.Status().Code)
+		})
+	}
+}
+
+func TestInstrumentationHook_ProcessPipelineHook(t *testing.T) {
+	recorder, _ := setupTracing(t)
+	reader := metricstest.New(t)
+
+	get := redislib.NewStringCmd(context.Background(), "get", "key")
+	get.SetErr(redis.Nil)
+	set := redislib.NewStatusCmd(context.Background(), "set", "key", "val")
+	next := func(ctx context.Context, cmds []redislib.Cmder) error { return nil }
+
+	err := redis.NewInstrumentationHook().ProcessPipelineHook(next)(context.Background(), []redislib.Cmder{get, set})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, reader.Value(t, "redis_command_duration_seconds",
+		metrics.CommandKey.String("get"), metrics.OutcomeKey.String(constant.MetricRedisNil)))
+	assert.Equal(t, 1.0, reader.Value(t, "redis_command_duration_seconds",
+		metrics.CommandKey.String("set"), metrics.OutcomeKey.String(constant.MetricRedisOK)))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "redis pipeline", spans[0].Name())
+	assert.Equal(t, "2", spanAttribute(spans[0], "redis.pipeline.length"))
+}
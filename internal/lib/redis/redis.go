@@ -11,10 +11,24 @@ import (
 	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/logger"
 )
 
+const Nil = redislib.Nil
+
+// Update gives up after this many attempts when the key keeps changing under
+// it.
+const maxUpdateAttempts = 10
+
+var ErrConflict = errors.New("redis key kept changing during update")
+
 type RedisService interface {
 	Get(ctx context.Context, key string) (string, error)
+	GetDel(ctx context.Context, key string) (string, error)
+	Update(ctx context.Context, key string, update func(val string) (string, time.Duration, error)) error
 	Set(ctx context.Context, key string, val string, expiry time.Duration) error
 	Del(ctx context.Context, key string) error
+	SAdd(ctx context.Context, key string, members ...string) error
+	SRem(ctx context.Context, key string, members ...string) error
+	SMembers(ctx context.Context, key string) ([]string, error)
+	XAdd(ctx context.Context, stream string, maxLen int64, values map[string]any) error
 	Health(ctx context.Context) error
 	Close() error
 }
@@ -31,6 +45,7 @@ func NewClient(cfg *config.Redis) (*RedisClient, error) {
 		Username: cfg.Username,
 		Password: cfg.Password,
 	})
+	client.AddHook(NewInstrumentationHook())
 
 	r := &RedisClient{Client: client}
 
@@ -48,6 +63,44 @@ func (r *RedisClient) Get(ctx context.Context, key string) (string, error) {
 	return r.Client.Get(ctx, key).Result()
 }
 
+// GetDel returns the value of key and deletes it in one command, so of several
+// concurrent callers only one gets the value.
+func (r *RedisClient) GetDel(ctx context.Context, key string) (string, error) {
+	return r.Client.GetDel(ctx, key).Result()
+}
+
+// Update replaces the value of key with what update returns for the current
+// one, under WATCH/MULTI. When key is written by someone else in between,
+// nothing is stored and update runs again on the new value. Update returns Nil
+// for a missing key and an error from update as is, storing nothing.
+func (r *RedisClient) Update(ctx context.Context, key string, update func(val string) (string, time.Duration, error)) error {
+	txf := func(tx *redislib.Tx) error {
+		val, err := tx.Get(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+
+		newVal, expiry, err := update(val)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redislib.Pipeliner) error {
+			pipe.Set(ctx, key, newVal, expiry)
+			return nil
+		})
+		return err
+	}
+
+	for i := 0; i < maxUpdateAttempts; i++ {
+		err := r.Client.Watch(ctx, txf, key)
+		if !errors.Is(err, redislib.TxFailedErr) {
+			return err
+		}
+	}
+	return ErrConflict
+}
+
 func (r *RedisClient) Set(ctx context.Context, key string, val string, expiry time.Duration) error {
 	return r.Client.Set(ctx, key, val, expiry).Err()
 }
@@ -56,6 +109,29 @@ func (r *RedisClient) Del(ctx context.Context, key string) error {
 	return r.Client.Del(ctx, key).Err()
 }
 
+func (r *RedisClient) SAdd(ctx context.Context, key string, members ...string) error {
+	return r.Client.SAdd(ctx, key, toAny(members)...).Err()
+}
+
+func (r *RedisClient) SRem(ctx context.Context, key string, members ...string) error {
+	return r.Client.SRem(ctx, key, toAny(members)...).Err()
+}
+
+func (r *RedisClient) SMembers(ctx context.Context, key string) ([]string, error) {
+	return r.Client.SMembers(ctx, key).Result()
+}
+
+// XAdd appends an entry to a stream, trimming it to roughly maxLen entries
+// when maxLen is positive.
+func (r *RedisClient) XAdd(ctx context.Context, stream string, maxLen int64, values map[string]any) error {
+	return r.Client.XAdd(ctx, &redislib.XAddArgs{
+		Stream: stream,
+		MaxLen: maxLen,
+		Approx: maxLen > 0,
+		Values: values,
+	}).Err()
+}
+
 func (r *RedisClient) Health(ctx context.Context) error {
 	for i := 0; i < 5; i++ {
 		if pong := r.Client.Ping(ctx); pong.Val() == "PONG" {
@@ -74,3 +150,11 @@ func (r *RedisClient) Close() error {
 
 	return nil
 }
+
+func toAny(members []string) []interface{} {
+	vals := make([]interface{}, len(members))
+	for i, m := range members {
+		vals[i] = m
+	}
+	return vals
+}
@@ -85,6 +85,52 @@ func TestRedisClient(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			name: "update key",
+			action: func() error {
+				err := client.Update(ctx, "foo", func(val string) (string, time.Duration, error) {
+					return val + "baz", time.Second * 5, nil
+				})
+				if err != nil {
+					return err
+				}
+				val, err := client.Get(ctx, "foo")
+				if err != nil {
+					return err
+				}
+				if val != "barbaz" {
+					t.Errorf("expected value 'barbaz', got %s", val)
+				}
+				return nil
+			},
+			expectErr: false,
+		},
+		{
+			name: "update missing key returns error",
+			action: func() error {
+				return client.Update(ctx, "missing", func(val string) (string, time.Duration, error) {
+					return val, 0, nil
+				})
+			},
+			expectErr: true,
+		},
+		{
+			name: "get and delete key",
+			action: func() error {
+				if err := client.Set(ctx, "once", "bar", time.Second*5); err != nil {
+					return err
+				}
+				if _, err := client.GetDel(ctx, "once"); err != nil {
+					return err
+				}
+				_, err := client.GetDel(ctx, "once")
+				if err != redis.Nil {
+					t.Errorf("expected redis.Nil on second GetDel, got %v", err)
+				}
+				return nil
+			},
+			expectErr: false,
+		},
 		{
 			name: "delete key",
 			action: func() error {
@@ -100,6 +146,41 @@ func TestRedisClient(t *testing.T) {
 			},
 			expectErr: true, // redis returns redis.Nil for missing keys
 		},
+		{
+			name: "add set members",
+			action: func() error {
+				return client.SAdd(ctx, "set", "a", "b")
+			},
+			expectErr: false,
+		},
+		{
+			name: "remove set member",
+			action: func() error {
+				return client.SRem(ctx, "set", "a")
+			},
+			expectErr: false,
+		},
+		{
+			name: "append to stream",
+			action: func() error {
+				return client.XAdd(ctx, "stream", 100, map[string]any{"event": "login"})
+			},
+			expectErr: false,
+		},
+		{
+			name: "list set members",
+			action: func() error {
+				members, err := client.SMembers(ctx, "set")
+				if err != nil {
+					return err
+				}
+				if len(members) != 1 || members[0] != "b" {
+					t.Errorf("expected members [b], got %v", members)
+				}
+				return nil
+			},
+			expectErr: false,
+		},
 		{
 			name: "health check",
 			action: func() error {
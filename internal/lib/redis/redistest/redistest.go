@@ -0,0 +1,96 @@
+// Package redistest provides a testify mock of redis.RedisService.
+package redistest
+
+import (
+	"context"
+	"time"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/lib/redis"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ redis.RedisService = (*Client)(nil)
+
+type Client struct {
+	mock.Mock
+}
+
+func (m *Client) Get(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+
+	if err := args.Error(1); err != nil {
+		return "", err
+	}
+
+	return args.Get(0).(string), nil
+}
+
+func (m *Client) GetDel(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+
+	if err := args.Error(1); err != nil {
+		return "", err
+	}
+
+	return args.Get(0).(string), nil
+}
+
+// Update runs the update against the mocked Get and Set, so tests set up
+// expectations for those rather than for Update itself.
+func (m *Client) Update(ctx context.Context, key string, update func(val string) (string, time.Duration, error)) error {
+	val, err := m.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	newVal, expiry, err := update(val)
+	if err != nil {
+		return err
+	}
+
+	return m.Set(ctx, key, newVal, expiry)
+}
+
+func (m *Client) Set(ctx context.Context, key string, val string, expiry time.Duration) error {
+	args := m.Called(ctx, key, val, expiry)
+	return args.Error(0)
+}
+
+func (m *Client) Del(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *Client) SAdd(ctx context.Context, key string, members ...string) error {
+	args := m.Called(ctx, key, members)
+	return args.Error(0)
+}
+
+func (m *Client) SRem(ctx context.Context, key string, members ...string) error {
+	args := m.Called(ctx, key, members)
+	return args.Error(0)
+}
+
+func (m *Client) SMembers(ctx context.Context, key string) ([]string, error) {
+	args := m.Called(ctx, key)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).([]string), nil
+}
+
+func (m *Client) XAdd(ctx context.Context, stream string, maxLen int64, values map[string]any) error {
+	args := m.Called(ctx, stream, maxLen, values)
+	return args.Error(0)
+}
+
+func (m *Client) Health(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func (m *Client) Close() error {
+	return nil
+}
@@ -25,6 +25,11 @@ type UserServiceClient interface {
 	FindById(ctx context.Context, in *FindByIdRequest, opts ...grpc.CallOption) (*FindByIdResponse, error)
 	FindByCredential(ctx context.Context, in *FindByCredentialRequest, opts ...grpc.CallOption) (*FindByCredentialResponse, error)
 	Store(ctx context.Context, in *StoreRequest, opts ...grpc.CallOption) (*StoreResponse, error)
+	FindByEmail(ctx context.Context, in *FindByEmailRequest, opts ...grpc.CallOption) (*FindByEmailResponse, error)
+	// Federated identities are linked to users by provider and subject.
+	// FindByFederatedIdentity answers NotFound for an identity never linked.
+	FindByFederatedIdentity(ctx context.Context, in *FindByFederatedIdentityRequest, opts ...grpc.CallOption) (*FindByFederatedIdentityResponse, error)
+	LinkFederatedIdentity(ctx context.Context, in *LinkFederatedIdentityRequest, opts ...grpc.CallOption) (*LinkFederatedIdentityResponse, error)
 }
 
 type userServiceClient struct {
@@ -62,6 +67,33 @@ func (c *userServiceClient) Store(ctx context.Context, in *StoreRequest, opts ..
 	return out, nil
 }
 
+func (c *userServiceClient) FindByEmail(ctx context.Context, in *FindByEmailRequest, opts ...grpc.CallOption) (*FindByEmailResponse, error) {
+	out := new(FindByEmailResponse)
+	err := c.cc.Invoke(ctx, "/user.UserService/FindByEmail", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) FindByFederatedIdentity(ctx context.Context, in *FindByFederatedIdentityRequest, opts ...grpc.CallOption) (*FindByFederatedIdentityResponse, error) {
+	out := new(FindByFederatedIdentityResponse)
+	err := c.cc.Invoke(ctx, "/user.UserService/FindByFederatedIdentity", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) LinkFederatedIdentity(ctx context.Context, in *LinkFederatedIdentityRequest, opts ...grpc.CallOption) (*LinkFederatedIdentityResponse, error) {
+	out := new(LinkFederatedIdentityResponse)
+	err := c.cc.Invoke(ctx, "/user.UserService/LinkFederatedIdentity", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UserServiceServer is the server API for UserService service.
 // All implementations must embed UnimplementedUserServiceServer
 // for forward compatibility
@@ -69,6 +101,11 @@ type UserServiceServer interface {
 	FindById(context.Context, *FindByIdRequest) (*FindByIdResponse, error)
 	FindByCredential(context.Context, *FindByCredentialRequest) (*FindByCredentialResponse, error)
 	Store(context.Context, *StoreRequest) (*StoreResponse, error)
+	FindByEmail(context.Context, *FindByEmailRequest) (*FindByEmailResponse, error)
+	// Federated identities are linked to users by provider and subject.
+	// FindByFederatedIdentity answers NotFound for an identity never linked.
+	FindByFederatedIdentity(context.Context, *FindByFederatedIdentityRequest) (*FindByFederatedIdentityResponse, error)
+	LinkFederatedIdentity(context.Context, *LinkFederatedIdentityRequest) (*LinkFederatedIdentityResponse, error)
 	mustEmbedUnimplementedUserServiceServer()
 }
 
@@ -85,6 +122,15 @@ func (UnimplementedUserServiceServer) FindByCredential(context.Context, *FindByC
 func (UnimplementedUserServiceServer) Store(context.Context, *StoreRequest) (*StoreResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Store not implemented")
 }
+func (UnimplementedUserServiceServer) FindByEmail(context.Context, *FindByEmailRequest) (*FindByEmailResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindByEmail not implemented")
+}
+func (UnimplementedUserServiceServer) FindByFederatedIdentity(context.Context, *FindByFederatedIdentityRequest) (*FindByFederatedIdentityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindByFederatedIdentity not implemented")
+}
+func (UnimplementedUserServiceServer) LinkFederatedIdentity(context.Context, *LinkFederatedIdentityRequest) (*LinkFederatedIdentityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LinkFederatedIdentity not implemented")
+}
 func (UnimplementedUserServiceServer) mustEmbedUnimplementedUserServiceServer() {}
 
 // UnsafeUserServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -152,6 +198,60 @@ func _UserService_Store_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserService_FindByEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindByEmailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).FindByEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user.UserService/FindByEmail",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).FindByEmail(ctx, req.(*FindByEmailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_FindByFederatedIdentity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindByFederatedIdentityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).FindByFederatedIdentity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user.UserService/FindByFederatedIdentity",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).FindByFederatedIdentity(ctx, req.(*FindByFederatedIdentityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_LinkFederatedIdentity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LinkFederatedIdentityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).LinkFederatedIdentity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/user.UserService/LinkFederatedIdentity",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).LinkFederatedIdentity(ctx, req.(*LinkFederatedIdentityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // UserService_ServiceDesc is the grpc.ServiceDesc for UserService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -171,6 +271,18 @@ var UserService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Store",
 			Handler:    _UserService_Store_Handler,
 		},
+		{
+			MethodName: "FindByEmail",
+			Handler:    _UserService_FindByEmail_Handler,
+		},
+		{
+			MethodName: "FindByFederatedIdentity",
+			Handler:    _UserService_FindByFederatedIdentity_Handler,
+		},
+		{
+			MethodName: "LinkFederatedIdentity",
+			Handler:    _UserService_LinkFederatedIdentity_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/user/user.proto",
@@ -0,0 +1,65 @@
+// Package authguard authenticates gRPC calls to other services against this
+// authentication service. Its interceptors read the bearer token from the
+// authorization metadata, verify it and make the caller available to handlers
+// through FromContext.
+//
+//	verifier := authguard.NewRemoteVerifier(authConn)
+//	guard := authguard.NewInterceptor(verifier, "/grpc.health.v1.Health/")
+//	server := grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(guard.Unary()),
+//		grpc.ChainStreamInterceptor(guard.Stream()),
+//	)
+package authguard
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/helper"
+	"google.golang.org/grpc/metadata"
+)
+
+// ErrUnauthenticated is returned by verifiers for missing, invalid, expired
+// or revoked credentials.
+var ErrUnauthenticated = errors.New("authguard: unauthenticated")
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID int
+	// Email and Name are only known when verified remotely.
+	Email string
+	Name  string
+	// Username, Roles, Scopes and Claims are only known when verified locally
+	// from the JWT claims.
+	Username string
+	Roles    []string
+	Scopes   []string
+	Claims   map[string]any
+	// Token is the raw credential, for forwarding to downstream services.
+	Token string
+}
+
+type principalKey struct{}
+
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored by the interceptors.
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// BearerToken reads the bearer token from the incoming authorization metadata.
+func BearerToken(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	header, _ := helper.GetGRPCMetadataValue(md, constant.HeaderAuthorization)
+	token, ok := strings.CutPrefix(header, constant.HeaderBearerPrefix)
+	if !ok || token == "" {
+		return "", false
+	}
+	return token, true
+}
@@ -0,0 +1,90 @@
+package authguard
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Interceptor authenticates every call except the skipped methods.
+type Interceptor struct {
+	verifier Verifier
+	skip     []string
+}
+
+// NewInterceptor creates interceptors that verify calls with verifier.
+// skipMethods are full method names such as "/pkg.Service/Method", or a
+// service prefix ending in "/", e.g. "/grpc.health.v1.Health/", to skip every
+// method of that service.
+func NewInterceptor(verifier Verifier, skipMethods ...string) *Interceptor {
+	return &Interceptor{verifier: verifier, skip: skipMethods}
+}
+
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if i.skipped(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := i.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if i.skipped(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		ctx, err := i.authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (i *Interceptor) authenticate(ctx context.Context) (context.Context, error) {
+	token, ok := BearerToken(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, constant.MessageUnauthorized)
+	}
+
+	principal, err := i.verifier.Verify(ctx, token)
+	if err != nil {
+		if errors.Is(err, ErrUnauthenticated) {
+			return nil, status.Error(codes.Unauthenticated, constant.MessageUnauthorized)
+		}
+		return nil, status.Errorf(codes.Unavailable, "token verification failed: %v", err)
+	}
+
+	return NewContext(ctx, principal), nil
+}
+
+func (i *Interceptor) skipped(method string) bool {
+	for _, skip := range i.skip {
+		if method == skip || (strings.HasSuffix(skip, "/") && strings.HasPrefix(method, skip)) {
+			return true
+		}
+	}
+	return false
+}
+
+// serverStream carries the authenticated context into stream handlers.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,160 @@
+package authguard_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/sagarmaheshwary/microservices-authentication-service/pkg/authguard"
+)
+
+type fakeVerifier struct {
+	principal *authguard.Principal
+	err       error
+	calls     int
+}
+
+func (f *fakeVerifier) Verify(ctx context.Context, token string) (*authguard.Principal, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.principal, nil
+}
+
+func withAuthorization(value string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", value))
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (f *fakeServerStream) Context() context.Context {
+	return f.ctx
+}
+
+func TestInterceptor(t *testing.T) {
+	principal := &authguard.Principal{UserID: 1}
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		method         string
+		verifier       *fakeVerifier
+		expectCode     codes.Code
+		expectUser     bool
+		expectVerified bool
+	}{
+		{
+			name:           "valid token",
+			ctx:            withAuthorization("Bearer token"),
+			method:         "/video.VideoService/List",
+			verifier:       &fakeVerifier{principal: principal},
+			expectCode:     codes.OK,
+			expectUser:     true,
+			expectVerified: true,
+		},
+		{
+			name:       "missing token",
+			ctx:        context.Background(),
+			method:     "/video.VideoService/List",
+			verifier:   &fakeVerifier{principal: principal},
+			expectCode: codes.Unauthenticated,
+		},
+		{
+			name:       "not a bearer token",
+			ctx:        withAuthorization("Basic dXNlcjpwYXNz"),
+			method:     "/video.VideoService/List",
+			verifier:   &fakeVerifier{principal: principal},
+			expectCode: codes.Unauthenticated,
+		},
+		{
+			name:           "invalid token",
+			ctx:            withAuthorization("Bearer token"),
+			method:         "/video.VideoService/List",
+			verifier:       &fakeVerifier{err: authguard.ErrUnauthenticated},
+			expectCode:     codes.Unauthenticated,
+			expectVerified: true,
+		},
+		{
+			name:           "verifier unavailable",
+			ctx:            withAuthorization("Bearer token"),
+			method:         "/video.VideoService/List",
+			verifier:       &fakeVerifier{err: errors.New("connection refused")},
+			expectCode:     codes.Unavailable,
+			expectVerified: true,
+		},
+		{
+			name:       "skipped method",
+			ctx:        context.Background(),
+			method:     "/video.VideoService/Public",
+			verifier:   &fakeVerifier{principal: principal},
+			expectCode: codes.OK,
+		},
+		{
+			name:       "skipped service",
+			ctx:        context.Background(),
+			method:     "/grpc.health.v1.Health/Check",
+			verifier:   &fakeVerifier{principal: principal},
+			expectCode: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/unary", func(t *testing.T) {
+			guard := authguard.NewInterceptor(tt.verifier, "/video.VideoService/Public", "/grpc.health.v1.Health/")
+
+			var gotUser bool
+			handler := func(ctx context.Context, req any) (any, error) {
+				p, ok := authguard.FromContext(ctx)
+				gotUser = ok && p == principal
+				return "response", nil
+			}
+
+			_, err := guard.Unary()(tt.ctx, "req", &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+
+			assert.Equal(t, tt.expectCode, status.Code(err))
+			assert.Equal(t, tt.expectUser, gotUser)
+			assert.Equal(t, tt.expectVerified, tt.verifier.calls == 1)
+		})
+
+		t.Run(tt.name+"/stream", func(t *testing.T) {
+			tt.verifier.calls = 0
+			guard := authguard.NewInterceptor(tt.verifier, "/video.VideoService/Public", "/grpc.health.v1.Health/")
+
+			var gotUser bool
+			handler := func(srv any, ss grpc.ServerStream) error {
+				p, ok := authguard.FromContext(ss.Context())
+				gotUser = ok && p == principal
+				return nil
+			}
+
+			err := guard.Stream()(nil, &fakeServerStream{ctx: tt.ctx}, &grpc.StreamServerInfo{FullMethod: tt.method}, handler)
+
+			assert.Equal(t, tt.expectCode, status.Code(err))
+			assert.Equal(t, tt.expectUser, gotUser)
+			assert.Equal(t, tt.expectVerified, tt.verifier.calls == 1)
+		})
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	token, ok := authguard.BearerToken(withAuthorization("Bearer token"))
+	require.True(t, ok)
+	assert.Equal(t, "token", token)
+
+	_, ok = authguard.BearerToken(withAuthorization("Bearer "))
+	assert.False(t, ok)
+
+	_, ok = authguard.BearerToken(context.Background())
+	assert.False(t, ok)
+}
@@ -0,0 +1,180 @@
+package authguard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	redislib "github.com/redis/go-redis/v9"
+	"github.com/sagarmaheshwary/microservices-authentication-service/internal/constant"
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Verifier resolves a bearer token to its principal. It returns
+// ErrUnauthenticated for bad credentials and any other error when the
+// credential could not be checked.
+type Verifier interface {
+	Verify(ctx context.Context, token string) (*Principal, error)
+}
+
+// VerifierOption configures a verifier.
+type VerifierOption func(*verifierOptions)
+
+type verifierOptions struct {
+	audience string
+}
+
+// WithAudience names the service doing the verifying. Exchanged tokens carry
+// the aud they were issued to and only pass a verifier for that audience.
+// Tokens without aud pass regardless. Without this option any token with an
+// aud is rejected, as the authentication service itself does.
+func WithAudience(audience string) VerifierOption {
+	return func(o *verifierOptions) { o.audience = audience }
+}
+
+func newVerifierOptions(opts []VerifierOption) verifierOptions {
+	var o verifierOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+type remoteVerifier struct {
+	client   authpb.AuthenticationServiceClient
+	audience string
+}
+
+// NewRemoteVerifier verifies tokens, JWTs and API keys alike, by calling
+// VerifyToken on the authentication service behind conn. Revocations take
+// effect immediately, at the cost of a round trip per call.
+func NewRemoteVerifier(conn grpc.ClientConnInterface, opts ...VerifierOption) Verifier {
+	o := newVerifierOptions(opts)
+	return &remoteVerifier{client: authpb.NewAuthenticationServiceClient(conn), audience: o.audience}
+}
+
+func (r *remoteVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, constant.HeaderAuthorization, constant.HeaderBearerPrefix+token)
+	res, err := r.client.VerifyToken(ctx, &authpb.VerifyTokenRequest{Audience: r.audience})
+	if err != nil {
+		if status.Code(err) == codes.Unauthenticated {
+			return nil, ErrUnauthenticated
+		}
+		return nil, err
+	}
+
+	user := res.GetData().GetUser()
+	return &Principal{
+		UserID: int(user.GetId()),
+		Email:  user.GetEmail(),
+		Name:   user.GetName(),
+		Token:  token,
+	}, nil
+}
+
+// Blacklist reports whether a token, by its jti claim, was revoked on logout.
+type Blacklist interface {
+	IsBlacklisted(ctx context.Context, jti string) (bool, error)
+}
+
+type redisBlacklist struct {
+	client redislib.Cmdable
+}
+
+// NewRedisBlacklist reads the token blacklist the authentication service
+// writes to Redis, so client must point at the same Redis.
+func NewRedisBlacklist(client redislib.Cmdable) Blacklist {
+	return &redisBlacklist{client: client}
+}
+
+func (r *redisBlacklist) IsBlacklisted(ctx context.Context, jti string) (bool, error) {
+	n, err := r.client.Exists(ctx, fmt.Sprintf("%s:%s", constant.RedisTokenBlacklist, jti)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+type localVerifier struct {
+	key       []byte
+	blacklist Blacklist
+	audience  string
+}
+
+// NewLocalVerifier verifies JWTs in process with the service's HMAC signing
+// key and checks blacklist for revoked tokens. It cannot verify API keys,
+// which are always rejected. blacklist may be nil to skip revocation checks,
+// in which case logged out tokens stay valid until they expire.
+func NewLocalVerifier(key []byte, blacklist Blacklist, opts ...VerifierOption) Verifier {
+	o := newVerifierOptions(opts)
+	return &localVerifier{key: key, blacklist: blacklist, audience: o.audience}
+}
+
+func (l *localVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	if strings.HasPrefix(token, constant.APIKeyPrefix) {
+		return nil, ErrUnauthenticated
+	}
+
+	decoded, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		return l.key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, ErrUnauthenticated
+	}
+
+	claims, ok := decoded.Claims.(jwt.MapClaims)
+	if !ok || !l.audienceAllowed(claims) {
+		return nil, ErrUnauthenticated
+	}
+	jti, _ := claims["jti"].(string)
+	id, ok := claims["id"].(float64)
+	if jti == "" || !ok {
+		return nil, ErrUnauthenticated
+	}
+
+	if l.blacklist != nil {
+		revoked, err := l.blacklist.IsBlacklisted(ctx, jti)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrUnauthenticated
+		}
+	}
+
+	return principalFromClaims(int(id), claims, token), nil
+}
+
+// audienceAllowed lets tokens without aud through and holds the rest to the
+// configured audience.
+func (l *localVerifier) audienceAllowed(claims jwt.MapClaims) bool {
+	aud, err := claims.GetAudience()
+	if err != nil {
+		return false
+	}
+	if len(aud) == 0 {
+		return true
+	}
+	return l.audience != "" && jwt.NewValidator(jwt.WithAudience(l.audience)).Validate(claims) == nil
+}
+
+func principalFromClaims(id int, claims jwt.MapClaims, token string) *Principal {
+	p := &Principal{UserID: id, Claims: claims, Token: token}
+	p.Username, _ = claims["username"].(string)
+	if roles, ok := claims["roles"].([]any); ok {
+		for _, role := range roles {
+			if r, ok := role.(string); ok {
+				p.Roles = append(p.Roles, r)
+			}
+		}
+	}
+	if scope, ok := claims["scope"].(string); ok {
+		p.Scopes = strings.Fields(scope)
+	}
+	return p
+}
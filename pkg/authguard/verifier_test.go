@@ -0,0 +1,277 @@
+package authguard_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	authpb "github.com/sagarmaheshwary/microservices-authentication-service/internal/proto/authentication"
+	"github.com/sagarmaheshwary/microservices-authentication-service/pkg/authguard"
+)
+
+var testKey = []byte("secret-key")
+
+func signToken(t *testing.T, key []byte, method jwt.SigningMethod, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	require.NoError(t, err)
+	return token
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"id":       float64(1),
+		"username": "name",
+		"exp":      time.Now().Add(time.Hour).Unix(),
+		"jti":      "jti-1",
+		"roles":    []string{"admin"},
+		"scope":    "videos:read videos:write",
+	}
+}
+
+type fakeBlacklist struct {
+	revoked map[string]bool
+	err     error
+}
+
+func (f *fakeBlacklist) IsBlacklisted(ctx context.Context, jti string) (bool, error) {
+	return f.revoked[jti], f.err
+}
+
+func TestLocalVerifier(t *testing.T) {
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+	noExpiry := validClaims()
+	delete(noExpiry, "exp")
+	noJTI := validClaims()
+	delete(noJTI, "jti")
+	exchanged := validClaims()
+	exchanged["aud"] = "video-service"
+	redisErr := errors.New("redis down")
+
+	tests := []struct {
+		name      string
+		token     string
+		blacklist authguard.Blacklist
+		audience  string
+		expected  *authguard.Principal
+		expectErr error
+	}{
+		{
+			name:      "valid token",
+			token:     signToken(t, testKey, jwt.SigningMethodHS256, validClaims()),
+			blacklist: &fakeBlacklist{},
+			expected: &authguard.Principal{
+				UserID:   1,
+				Username: "name",
+				Roles:    []string{"admin"},
+				Scopes:   []string{"videos:read", "videos:write"},
+			},
+		},
+		{
+			name:     "valid token without blacklist",
+			token:    signToken(t, testKey, jwt.SigningMethodHS256, validClaims()),
+			expected: &authguard.Principal{UserID: 1, Username: "name", Roles: []string{"admin"}, Scopes: []string{"videos:read", "videos:write"}},
+		},
+		{
+			name:      "revoked token",
+			token:     signToken(t, testKey, jwt.SigningMethodHS256, validClaims()),
+			blacklist: &fakeBlacklist{revoked: map[string]bool{"jti-1": true}},
+			expectErr: authguard.ErrUnauthenticated,
+		},
+		{
+			name:      "blacklist unavailable",
+			token:     signToken(t, testKey, jwt.SigningMethodHS256, validClaims()),
+			blacklist: &fakeBlacklist{err: redisErr},
+			expectErr: redisErr,
+		},
+		{
+			name:      "wrong key",
+			token:     signToken(t, []byte("other-key"), jwt.SigningMethodHS256, validClaims()),
+			expectErr: authguard.ErrUnauthenticated,
+		},
+		{
+			name:      "wrong algorithm",
+			token:     signToken(t, testKey, jwt.SigningMethodHS512, validClaims()),
+			expectErr: authguard.ErrUnauthenticated,
+		},
+		{
+			name:      "expired token",
+			token:     signToken(t, testKey, jwt.SigningMethodHS256, expired),
+			expectErr: authguard.ErrUnauthenticated,
+		},
+		{
+			name:      "token without expiry",
+			token:     signToken(t, testKey, jwt.SigningMethodHS256, noExpiry),
+			expectErr: authguard.ErrUnauthenticated,
+		},
+		{
+			name:      "token without jti",
+			token:     signToken(t, testKey, jwt.SigningMethodHS256, noJTI),
+			expectErr: authguard.ErrUnauthenticated,
+		},
+		{
+			name:      "API key",
+			token:     "ak_0123_secret",
+			expectErr: authguard.ErrUnauthenticated,
+		},
+		{
+			name:     "token for this audience",
+			token:    signToken(t, testKey, jwt.SigningMethodHS256, exchanged),
+			audience: "video-service",
+			expected: &authguard.Principal{UserID: 1, Username: "name", Roles: []string{"admin"}, Scopes: []string{"videos:read", "videos:write"}},
+		},
+		{
+			name:     "token without audience passes any audience",
+			token:    signToken(t, testKey, jwt.SigningMethodHS256, validClaims()),
+			audience: "video-service",
+			expected: &authguard.Principal{UserID: 1, Username: "name", Roles: []string{"admin"}, Scopes: []string{"videos:read", "videos:write"}},
+		},
+		{
+			name:      "token for another audience",
+			token:     signToken(t, testKey, jwt.SigningMethodHS256, exchanged),
+			audience:  "upload-service",
+			expectErr: authguard.ErrUnauthenticated,
+		},
+		{
+			name:      "audience bound token without configured audience",
+			token:     signToken(t, testKey, jwt.SigningMethodHS256, exchanged),
+			expectErr: authguard.ErrUnauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := authguard.NewLocalVerifier(testKey, tt.blacklist, authguard.WithAudience(tt.audience))
+
+			principal, err := verifier.Verify(context.Background(), tt.token)
+
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+				assert.Nil(t, principal)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected.UserID, principal.UserID)
+			assert.Equal(t, tt.expected.Username, principal.Username)
+			assert.Equal(t, tt.expected.Roles, principal.Roles)
+			assert.Equal(t, tt.expected.Scopes, principal.Scopes)
+			assert.Equal(t, tt.token, principal.Token)
+			assert.Equal(t, "jti-1", principal.Claims["jti"])
+		})
+	}
+}
+
+type fakeAuthServer struct {
+	authpb.UnimplementedAuthenticationServiceServer
+	verify func(ctx context.Context, in *authpb.VerifyTokenRequest) (*authpb.VerifyTokenResponse, error)
+}
+
+func (f *fakeAuthServer) VerifyToken(ctx context.Context, in *authpb.VerifyTokenRequest) (*authpb.VerifyTokenResponse, error) {
+	return f.verify(ctx, in)
+}
+
+func dialAuthServer(t *testing.T, srv authpb.AuthenticationServiceServer) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	authpb.RegisterAuthenticationServiceServer(server, srv)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestRemoteVerifier(t *testing.T) {
+	tests := []struct {
+		name       string
+		verify     func(ctx context.Context, in *authpb.VerifyTokenRequest) (*authpb.VerifyTokenResponse, error)
+		expected   *authguard.Principal
+		expectErr  error
+		expectCode codes.Code
+	}{
+		{
+			name: "valid token",
+			verify: func(ctx context.Context, in *authpb.VerifyTokenRequest) (*authpb.VerifyTokenResponse, error) {
+				md, _ := metadata.FromIncomingContext(ctx)
+				if md.Get("authorization")[0] != "Bearer token" {
+					return nil, status.Error(codes.Unauthenticated, "unauthorized")
+				}
+				return &authpb.VerifyTokenResponse{
+					Data: &authpb.VerifyTokenResponseData{
+						User: &authpb.User{Id: 1, Name: "name", Email: "name@gmail.com"},
+					},
+				}, nil
+			},
+			expected: &authguard.Principal{UserID: 1, Name: "name", Email: "name@gmail.com", Token: "token"},
+		},
+		{
+			name: "audience is sent",
+			verify: func(ctx context.Context, in *authpb.VerifyTokenRequest) (*authpb.VerifyTokenResponse, error) {
+				if in.Audience != "video-service" {
+					return nil, status.Error(codes.Unauthenticated, "unauthorized")
+				}
+				return &authpb.VerifyTokenResponse{
+					Data: &authpb.VerifyTokenResponseData{User: &authpb.User{Id: 1}},
+				}, nil
+			},
+			expected: &authguard.Principal{UserID: 1, Token: "token"},
+		},
+		{
+			name: "invalid token",
+			verify: func(ctx context.Context, in *authpb.VerifyTokenRequest) (*authpb.VerifyTokenResponse, error) {
+				return nil, status.Error(codes.Unauthenticated, "unauthorized")
+			},
+			expectErr: authguard.ErrUnauthenticated,
+		},
+		{
+			name: "service unavailable",
+			verify: func(ctx context.Context, in *authpb.VerifyTokenRequest) (*authpb.VerifyTokenResponse, error) {
+				return nil, status.Error(codes.Unavailable, "unavailable")
+			},
+			expectCode: codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := dialAuthServer(t, &fakeAuthServer{verify: tt.verify})
+			verifier := authguard.NewRemoteVerifier(conn, authguard.WithAudience("video-service"))
+
+			principal, err := verifier.Verify(context.Background(), "token")
+
+			switch {
+			case tt.expectErr != nil:
+				assert.ErrorIs(t, err, tt.expectErr)
+			case tt.expectCode != codes.OK:
+				assert.Equal(t, tt.expectCode, status.Code(err))
+				assert.NotErrorIs(t, err, authguard.ErrUnauthenticated)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, principal)
+			}
+		})
+	}
+}